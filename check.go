@@ -0,0 +1,210 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+
+	"github.com/prometheus-community/windows_exporter/internal/config"
+	"github.com/prometheus-community/windows_exporter/internal/log"
+	"github.com/prometheus-community/windows_exporter/internal/nagios"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+	"github.com/prometheus/client_golang/prometheus"
+	promslogflag "github.com/prometheus/common/promslog/flag"
+)
+
+// checkFlags holds the values of the flags of the check subcommand.
+type checkFlags struct {
+	warnings  *[]string
+	criticals *[]string
+	timeout   *time.Duration
+}
+
+// newCheckApp creates the kingpin application of the check subcommand. See newApp.
+func newCheckApp(collectorConfig *collector.Config) (*kingpin.Application, *exporterFlags, *collector.MetricCollectors, *checkFlags) {
+	app, flags, collectors := newApp(collectorConfig)
+	app.Name = "windows_exporter check"
+	app.Help = "Collects the metrics once and evaluates thresholds like a Nagios plugin. " +
+		"The exit code is 0 for OK, 1 for WARNING, 2 for CRITICAL and 3 for UNKNOWN."
+
+	check := &checkFlags{
+		warnings: app.Flag(
+			"check.warning",
+			"Threshold expression, which results in WARNING, if it is true for any series. Can be repeated.",
+		).Short('w').Strings(),
+		criticals: app.Flag(
+			"check.critical",
+			"Threshold expression, which results in CRITICAL, if it is true for any series. Can be repeated.",
+		).Short('c').Strings(),
+		timeout: app.Flag(
+			"check.timeout",
+			"Timeout of the collection of the metrics.",
+		).Short('t').Default("10s").Duration(),
+	}
+
+	// Without --collectors.enabled, the collectors are derived from the metrics of the thresholds.
+	app.GetFlag("collectors.enabled").Default("")
+	// The monitoring system may capture stderr as part of the plugin output.
+	app.GetFlag(promslogflag.LevelFlagName).Default("warn")
+
+	return app, flags, collectors, check
+}
+
+// runCheck runs the check subcommand. It collects the metrics of the selected collectors once and evaluates the
+// thresholds like a Nagios plugin. The returned exit code is the state of the check.
+func runCheck(args []string) int {
+	app, flags, collectors, check := newCheckApp(nil)
+
+	if _, err := app.Parse(args); err != nil {
+		return printCheckResult(nagios.NewUnknown(fmt.Errorf("failed to parse CLI args: %w", err)))
+	}
+
+	logger, err := log.New(flags.logConfig)
+	if err != nil {
+		return printCheckResult(nagios.NewUnknown(fmt.Errorf("failed to create logger: %w", err)))
+	}
+
+	if *flags.configFile != "" {
+		remote, err := config.NewRemote(logger, *flags.remoteOptions)
+		if err != nil {
+			return printCheckResult(nagios.NewUnknown(fmt.Errorf("failed to configure loading of config file from URL: %w", err)))
+		}
+
+		resolver, err := config.NewResolver(*flags.configFile, logger, remote)
+		if err != nil {
+			return printCheckResult(nagios.NewUnknown(fmt.Errorf("could not load config file: %w", err)))
+		}
+
+		if err = resolver.Validate(app, configSchema()); err != nil {
+			return printCheckResult(nagios.NewUnknown(fmt.Errorf("invalid configuration file: %w", err)))
+		}
+
+		collectorConfig, err := loadCollectorConfig(resolver)
+		if err != nil {
+			return printCheckResult(nagios.NewUnknown(fmt.Errorf("failed to load collector configuration: %w", err)))
+		}
+
+		// See run() for parsing the CLI args by a new application.
+		app, flags, collectors, check = newCheckApp(collectorConfig)
+
+		if err = resolver.Bind(app, args); err != nil {
+			return printCheckResult(nagios.NewUnknown(fmt.Errorf("failed to bind configuration: %w", err)))
+		}
+
+		if _, err = app.Parse(args); err != nil {
+			return printCheckResult(nagios.NewUnknown(fmt.Errorf("failed to parse CLI args from YAML file: %w", err)))
+		}
+
+		if collectors.MetricRelabelRules, err = loadMetricRelabelRules(resolver); err != nil {
+			return printCheckResult(nagios.NewUnknown(fmt.Errorf("failed to load metric relabel configs: %w", err)))
+		}
+	}
+
+	thresholds := make([]*nagios.Threshold, 0, len(*check.warnings)+len(*check.criticals))
+
+	for _, exprs := range []struct {
+		state nagios.State
+		exprs []string
+	}{
+		{nagios.Warning, *check.warnings},
+		{nagios.Critical, *check.criticals},
+	} {
+		for _, expr := range exprs.exprs {
+			threshold, err := nagios.ParseThreshold(exprs.state, expr)
+			if err != nil {
+				return printCheckResult(nagios.NewUnknown(err))
+			}
+
+			thresholds = append(thresholds, threshold)
+		}
+	}
+
+	if len(thresholds) == 0 {
+		return printCheckResult(nagios.NewUnknown(errors.New("no thresholds set, use --check.warning or --check.critical")))
+	}
+
+	enabledCollectorList := utils.ExpandEnabledCollectors(*flags.enabledCollectors)
+	if len(enabledCollectorList) == 0 {
+		if enabledCollectorList, err = collectorsOfThresholds(thresholds); err != nil {
+			return printCheckResult(nagios.NewUnknown(err))
+		}
+	}
+
+	if err = collectors.Enable(enabledCollectorList); err != nil {
+		return printCheckResult(nagios.NewUnknown(err))
+	}
+
+	if err = collectors.Build(logger); err != nil {
+		return printCheckResult(nagios.NewUnknown(fmt.Errorf("couldn't load collectors: %w", err)))
+	}
+
+	defer func() {
+		_ = collectors.Close(logger)
+	}()
+
+	if err = collectors.SetPerfCounterQuery(logger); err != nil {
+		return printCheckResult(nagios.NewUnknown(fmt.Errorf("couldn't set performance counter query: %w", err)))
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewPrometheusCollector(*check.timeout, logger))
+
+	families, err := reg.Gather()
+	if err != nil {
+		// Failed collectors are reported by the windows_exporter_collector_success metric.
+		logger.Warn("error gathering metrics",
+			slog.Any("err", err),
+		)
+	}
+
+	return printCheckResult(nagios.Evaluate(families, thresholds))
+}
+
+// collectorsOfThresholds returns the collectors of the metrics used by the thresholds. The collector of a metric is
+// the longest collector name, which prefixes the metric name after the namespace.
+func collectorsOfThresholds(thresholds []*nagios.Threshold) ([]string, error) {
+	available := collector.Available()
+
+	var names []string
+
+	for _, threshold := range thresholds {
+		for _, metric := range threshold.MetricNames() {
+			if strings.HasPrefix(metric, "windows_exporter_") {
+				continue
+			}
+
+			name := ""
+
+			for _, c := range available {
+				suffix, ok := strings.CutPrefix(metric, "windows_"+c)
+				if ok && (suffix == "" || suffix[0] == '_') && len(c) > len(name) {
+					name = c
+				}
+			}
+
+			if name == "" {
+				return nil, fmt.Errorf("couldn't determine the collector of the metric %s, use --collectors.enabled", metric)
+			}
+
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	return slices.Compact(names), nil
+}
+
+func printCheckResult(result nagios.Result) int {
+	fmt.Print(result.String()) //nolint:forbidigo
+
+	return int(result.State)
+}
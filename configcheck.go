@@ -0,0 +1,85 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/config"
+	"github.com/prometheus-community/windows_exporter/internal/graphite"
+	"github.com/prometheus-community/windows_exporter/internal/influx"
+	"github.com/prometheus-community/windows_exporter/internal/otlp"
+	"github.com/prometheus-community/windows_exporter/internal/pushgateway"
+	"github.com/prometheus-community/windows_exporter/internal/relabel"
+	"github.com/prometheus-community/windows_exporter/internal/remotewrite"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+)
+
+// configSchema returns the keys of the configuration file, which are not flags, and the validation of flag values.
+func configSchema() config.Schema {
+	return config.Schema{
+		Sections: map[string]interface{}{
+			"collector":              &collector.Config{},
+			"const_labels":           &map[string]string{},
+			"metric_relabel_configs": &[]relabel.Config{},
+			"metrics_endpoints":      &[]metricsEndpointConfig{},
+			"remote_write":           &remotewrite.Config{},
+			"otlp":                   &otlp.Config{},
+			"pushgateway":            &pushgateway.Config{},
+			"influxdb":               &influx.Config{},
+			"graphite":               &graphite.Config{},
+		},
+		Values: map[string]func(value string) error{
+			"collectors.enabled":         validateCollectorName,
+			"health.required-collectors": validateCollectorName,
+		},
+	}
+}
+
+// validateCollectorName returns an error, if name is neither an available collector nor the [defaults] placeholder.
+func validateCollectorName(name string) error {
+	if name == types.DefaultCollectorsPlaceholder || slices.Contains(collector.Available(), name) {
+		return nil
+	}
+
+	return fmt.Errorf("unknown collector %s", name)
+}
+
+// printConfigErrors prints the errors of the validation of the configuration file to stderr.
+// Every error is printed on its own line in the form file:line:column: message.
+func printConfigErrors(err error) {
+	errs := []error{err}
+
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		errs = joined.Unwrap()
+	}
+
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err) //nolint:forbidigo
+	}
+}
+
+// printEffectiveConfig prints the effective configuration to stdout, preceded by the merged configuration files.
+// resolver is nil without configuration file.
+func printEffectiveConfig(app *kingpin.Application, resolver *config.Resolver) error {
+	effective, err := config.Effective(app, resolver, configSchema())
+	if err != nil {
+		return err
+	}
+
+	if resolver != nil {
+		for _, file := range resolver.Files() {
+			fmt.Println("# Configuration file: " + file) //nolint:forbidigo
+		}
+	}
+
+	_, err = os.Stdout.Write(effective)
+
+	return err
+}
@@ -0,0 +1,112 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/config"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+)
+
+// metricsEndpointConfig configures an additional metrics endpoint, which serves its own set of collectors.
+type metricsEndpointConfig struct {
+	// Path of the endpoint. The path of --telemetry.path replaces the default metrics endpoint.
+	Path string `yaml:"path"`
+	// Collectors served by the endpoint. [defaults] expands to the collectors enabled by default.
+	// The collectors are enabled in addition to --collectors.enabled, but are not served by the default metrics endpoint
+	// and the push modes.
+	Collectors []string `yaml:"collectors"`
+	// TimeoutMargin overrides --scrape.timeout-margin.
+	TimeoutMargin *float64 `yaml:"timeout_margin"`
+	// MaxRequests overrides --telemetry.max-requests.
+	MaxRequests *int `yaml:"max_requests"`
+	// InfluxPath serves the collectors of the endpoint in the InfluxDB line protocol like --telemetry.influx-path.
+	// Empty to disable.
+	InfluxPath string `yaml:"influx_path"`
+	// JSONPath serves the collectors of the endpoint as JSON like --telemetry.json-path. Empty to disable.
+	JSONPath string `yaml:"json_path"`
+}
+
+// paths returns the paths served by the endpoint.
+func (e metricsEndpointConfig) paths() []string {
+	paths := []string{e.Path}
+
+	for _, path := range []string{e.InfluxPath, e.JSONPath} {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+// loadMetricsEndpoints loads the additional metrics endpoints from the metrics_endpoints key of the configuration file.
+func loadMetricsEndpoints(resolver *config.Resolver) ([]metricsEndpointConfig, error) {
+	var endpoints []metricsEndpointConfig
+
+	if err := resolver.Unmarshal("metrics_endpoints", &endpoints); err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]struct{}, len(endpoints))
+
+	for i, endpoint := range endpoints {
+		for _, path := range endpoint.paths() {
+			if !strings.HasPrefix(path, "/") {
+				return nil, fmt.Errorf("path %q of metrics endpoint %d must start with /", path, i)
+			}
+
+			if _, ok := paths[path]; ok {
+				return nil, fmt.Errorf("duplicate metrics endpoint %s", path)
+			}
+
+			paths[path] = struct{}{}
+		}
+
+		endpoints[i].Collectors = utils.ExpandEnabledCollectors(strings.Join(endpoint.Collectors, ","))
+		if len(endpoints[i].Collectors) == 0 {
+			return nil, fmt.Errorf("metrics endpoint %s has no collectors", endpoint.Path)
+		}
+
+		slices.Sort(endpoints[i].Collectors)
+
+		if endpoint.TimeoutMargin != nil && *endpoint.TimeoutMargin < 0 {
+			return nil, fmt.Errorf("timeout_margin of metrics endpoint %s must not be negative", endpoint.Path)
+		}
+	}
+
+	return endpoints, nil
+}
+
+// metricsEndpointCollectors returns the collectors of all metrics endpoints.
+func metricsEndpointCollectors(endpoints []metricsEndpointConfig) []string {
+	var names []string
+
+	for _, endpoint := range endpoints {
+		names = append(names, endpoint.Collectors...)
+	}
+
+	slices.Sort(names)
+
+	return slices.Compact(names)
+}
+
+// checkMetricsEndpointPaths returns an error, if a metrics endpoint uses the path of another endpoint of windows_exporter.
+// Only the metrics endpoint itself may use the path of the default metrics endpoint metricsPath, which it replaces then.
+func checkMetricsEndpointPaths(endpoints []metricsEndpointConfig, metricsPath string, reserved ...string) error {
+	var errs []error
+
+	for _, endpoint := range endpoints {
+		for _, path := range endpoint.paths() {
+			if slices.Contains(reserved, path) || (path != endpoint.Path && path == metricsPath) {
+				errs = append(errs, fmt.Errorf("metrics endpoint %s conflicts with another endpoint", path))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -13,31 +13,37 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net/http"
 	"net/http/pprof"
 	"os"
 	"os/signal"
 	"os/user"
 	"runtime"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
-	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus-community/windows_exporter/internal/config"
+	"github.com/prometheus-community/windows_exporter/internal/health"
 	"github.com/prometheus-community/windows_exporter/internal/httphandler"
 	"github.com/prometheus-community/windows_exporter/internal/log"
-	"github.com/prometheus-community/windows_exporter/internal/log/flag"
-	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/internal/relabel"
 	"github.com/prometheus-community/windows_exporter/internal/utils"
 	"github.com/prometheus-community/windows_exporter/pkg/collector"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/version"
 	"github.com/prometheus/exporter-toolkit/web"
-	webflag "github.com/prometheus/exporter-toolkit/web/kingpinflag"
 	"golang.org/x/sys/windows"
 )
 
 func main() {
+	// The check subcommand runs the collectors once like a Nagios plugin instead of starting the exporter.
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		os.Exit(runCheck(os.Args[2:]))
+	}
+
 	exitCode := run()
 
 	// If we are running as a service, we need to signal the service control manager that we are done.
@@ -52,60 +58,7 @@ func main() {
 }
 
 func run() int {
-	app := kingpin.New("windows_exporter", "A metrics collector for Windows.")
-
-	var (
-		configFile = app.Flag(
-			"config.file",
-			"YAML configuration file to use. Values set in this file will be overridden by CLI flags.",
-		).String()
-		insecureSkipVerify = app.Flag(
-			"config.file.insecure-skip-verify",
-			"Skip TLS verification in loading YAML configuration.",
-		).Default("false").Bool()
-		webConfig   = webflag.AddFlags(app, ":9182")
-		metricsPath = app.Flag(
-			"telemetry.path",
-			"URL path for surfacing collected metrics.",
-		).Default("/metrics").String()
-		disableExporterMetrics = app.Flag(
-			"web.disable-exporter-metrics",
-			"Exclude metrics about the exporter itself (promhttp_*, process_*, go_*).",
-		).Bool()
-		maxRequests = app.Flag(
-			"telemetry.max-requests",
-			"Maximum number of concurrent requests. 0 to disable.",
-		).Default("5").Int()
-		enabledCollectors = app.Flag(
-			"collectors.enabled",
-			"Comma-separated list of collectors to use. Use '[defaults]' as a placeholder for all the collectors enabled by default.").
-			Default(types.DefaultCollectors).String()
-		printCollectors = app.Flag(
-			"collectors.print",
-			"If true, print available collectors and exit.",
-		).Bool()
-		timeoutMargin = app.Flag(
-			"scrape.timeout-margin",
-			"Seconds to subtract from the timeout allowed by the client. Tune to allow for overhead or high loads.",
-		).Default("0.5").Float64()
-		debugEnabled = app.Flag(
-			"debug.enabled",
-			"If true, windows_exporter will expose debug endpoints under /debug/pprof.",
-		).Default("false").Bool()
-		processPriority = app.Flag(
-			"process.priority",
-			"Priority of the exporter process. Higher priorities may improve exporter responsiveness during periods of system load. Can be one of [\"realtime\", \"high\", \"abovenormal\", \"normal\", \"belownormal\", \"low\"]",
-		).Default("normal").String()
-	)
-
-	logConfig := &log.Config{}
-	flag.AddFlags(app, logConfig)
-
-	app.Version(version.Print("windows_exporter"))
-	app.HelpFlag.Short('h')
-
-	// Initialize collectors before loading and parsing CLI arguments
-	collectors := collector.NewWithFlags(app)
+	app, flags, collectors := newApp(nil)
 
 	// Load values from configuration file(s). Executable flags must first be parsed, in order
 	// to load the specified file(s).
@@ -118,7 +71,7 @@ func run() int {
 		return 1
 	}
 
-	logger, err := log.New(logConfig)
+	logger, err := log.New(flags.logConfig)
 	if err != nil {
 		//nolint:sloglint // we do not have an logger yet
 		slog.Error("failed to create logger",
@@ -128,8 +81,26 @@ func run() int {
 		return 1
 	}
 
-	if *configFile != "" {
-		resolver, err := config.NewResolver(*configFile, logger, *insecureSkipVerify)
+	constLabels := prometheus.Labels{}
+
+	var (
+		pushConfig       pushConfig
+		metricsEndpoints []metricsEndpointConfig
+		resolver         *config.Resolver
+		remote           *config.Remote
+	)
+
+	if *flags.configFile != "" {
+		remote, err = config.NewRemote(logger, *flags.remoteOptions)
+		if err != nil {
+			logger.Error("Failed to configure loading of config file from URL",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+
+		resolver, err = config.NewResolver(*flags.configFile, logger, remote)
 		if err != nil {
 			logger.Error("could not load config file",
 				slog.Any("err", err),
@@ -138,6 +109,31 @@ func run() int {
 			return 1
 		}
 
+		if err = resolver.Validate(app, configSchema()); err != nil {
+			if *flags.configCheck {
+				printConfigErrors(err)
+			} else {
+				logger.Error("Invalid configuration file",
+					slog.Any("err", err),
+				)
+			}
+
+			return 1
+		}
+
+		collectorConfig, err := loadCollectorConfig(resolver)
+		if err != nil {
+			logger.Error("Failed to load collector configuration",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+
+		// The CLI args are parsed by a new application, which uses the values of the configuration file
+		// as defaults. Parsing the same application twice would duplicate the values of repeatable flags.
+		app, flags, collectors = newApp(collectorConfig)
+
 		if err = resolver.Bind(app, os.Args[1:]); err != nil {
 			logger.Error("Failed to bind configuration",
 				slog.Any("err", err),
@@ -146,12 +142,6 @@ func run() int {
 			return 1
 		}
 
-		// NOTE: This is temporary fix for issue #1092, calling kingpin.Parse
-		// twice makes slices flags duplicate its value, this clean up
-		// the first parse before the second call.
-		*webConfig.WebListenAddresses = (*webConfig.WebListenAddresses)[1:]
-
-		// Parse flags once more to include those discovered in configuration file(s).
 		if _, err = app.Parse(os.Args[1:]); err != nil {
 			logger.Error("Failed to parse CLI args from YAML file",
 				slog.Any("err", err),
@@ -160,7 +150,7 @@ func run() int {
 			return 1
 		}
 
-		logger, err = log.New(logConfig)
+		logger, err = log.New(flags.logConfig)
 		if err != nil {
 			//nolint:sloglint // we do not have an logger yet
 			slog.Error("failed to create logger",
@@ -169,17 +159,58 @@ func run() int {
 
 			return 1
 		}
+
+		if collectors.MetricRelabelRules, err = loadMetricRelabelRules(resolver); err != nil {
+			logger.Error("Failed to load metric relabel configs",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+
+		if err = resolver.Unmarshal("const_labels", &constLabels); err != nil {
+			logger.Error("Failed to load const labels",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+
+		if metricsEndpoints, err = loadMetricsEndpoints(resolver); err != nil {
+			logger.Error("Failed to load metrics endpoints",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+
+		if err = pushConfig.load(resolver); err != nil {
+			logger.Error("Failed to load push configuration",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+
+		if err = resolver.SaveCache(); err != nil {
+			logger.Warn("Failed to cache config file",
+				slog.Any("err", err),
+			)
+		}
 	}
 
+	// Labels of the CLI override labels with the same name of the configuration file.
+	maps.Copy(constLabels, *flags.constLabels)
+
 	logger.Debug("Logging has Started")
 
-	if *printCollectors {
+	if *flags.printCollectors {
 		printCollectorsToStdout()
 
 		return 0
 	}
 
-	if err = setPriorityWindows(logger, os.Getpid(), *processPriority); err != nil {
+	if err = setPriorityWindows(logger, os.Getpid(), *flags.processPriority); err != nil {
 		logger.Error("failed to set process priority",
 			slog.Any("err", err),
 		)
@@ -187,15 +218,44 @@ func run() int {
 		return 1
 	}
 
-	enabledCollectorList := utils.ExpandEnabledCollectors(*enabledCollectors)
+	// The collectors of the metrics endpoints are enabled in addition to the collectors of --collectors.enabled.
+	// They are served by their endpoints only.
+	endpointCollectors := metricsEndpointCollectors(metricsEndpoints)
+	defaultCollectorList := utils.ExpandEnabledCollectors(*flags.enabledCollectors)
+
+	enabledCollectorList := utils.ExpandEnabledCollectors(*flags.enabledCollectors + "," + strings.Join(endpointCollectors, ","))
 	if err := collectors.Enable(enabledCollectorList); err != nil {
 		logger.Error(err.Error())
 
 		return 1
 	}
 
+	if err := collectors.CircuitBreaker.Validate(); err != nil {
+		logger.Error(err.Error())
+
+		return 1
+	}
+
+	if *flags.configCheck {
+		if err = printEffectiveConfig(app, resolver); err != nil {
+			logger.Error("Failed to print effective configuration",
+				slog.Any("err", err),
+			)
+
+			return 1
+		}
+
+		return 0
+	}
+
 	// Initialize collectors before loading
-	if err = collectors.Build(logger); err != nil {
+	if *flags.degradedStartup {
+		err = collectors.BuildDegraded(logger, *flags.buildRetryBackoff, *flags.buildRetryMaxBackoff)
+	} else {
+		err = collectors.Build(logger)
+	}
+
+	if err != nil {
 		logger.Error("Couldn't load collectors",
 			slog.Any("err", err),
 		)
@@ -219,16 +279,113 @@ func run() int {
 		logger.Info("Using performance data helper from PHD.dll for performance counter collection. This is in experimental state.")
 	}
 
+	var metricsHandler *httphandler.MetricsHTTPHandler
+
+	reloader := config.NewReloader(logger, func() error {
+		reloadedCollectorList, err := reloadCollectors(logger, *flags.configFile, remote, collectors, endpointCollectors)
+		if err != nil {
+			return err
+		}
+
+		metricsHandler.SetCollectorNames(reloadedCollectorList)
+
+		return nil
+	})
+
+	pushers, err := newPushers(logger, pushConfig)
+	if err != nil {
+		logger.Error("Failed to create pushers",
+			slog.Any("err", err),
+		)
+
+		return 1
+	}
+
+	handlerOptions := httphandler.Options{
+		DisableExporterMetrics: *flags.disableExporterMetrics,
+		TimeoutMargin:          *flags.timeoutMargin,
+		MaxRequests:            *flags.maxRequests,
+		CoalesceWindow:         *flags.coalesceWindow,
+		Collectors:             append([]prometheus.Collector{reloader}, pushers.collectors()...),
+		ConstLabels:            constLabels,
+	}
+
+	// The default metrics endpoint and the push modes serve the collectors of --collectors.enabled.
+	defaultHandlerOptions := handlerOptions
+	defaultHandlerOptions.CollectorNames = defaultCollectorList
+
+	if err = httphandler.ValidateConstLabels(handlerOptions); err != nil {
+		logger.Error("Invalid const labels",
+			slog.Any("err", err),
+		)
+
+		return 1
+	}
+
+	metricsHandler = httphandler.New(logger, collectors, &defaultHandlerOptions)
+
+	if err = checkMetricsEndpointPaths(metricsEndpoints, *flags.metricsPath,
+		"/health", "/-/healthy", "/-/ready", "/version", "/collectors", *flags.influxPath, *flags.jsonPath,
+	); err != nil {
+		logger.Error("Invalid metrics endpoints",
+			slog.Any("err", err),
+		)
+
+		return 1
+	}
+
 	mux := http.NewServeMux()
 	mux.Handle("GET /health", httphandler.NewHealthHandler())
-	mux.Handle("GET /version", httphandler.NewVersionHandler())
-	mux.Handle("GET "+*metricsPath, httphandler.New(logger, collectors, &httphandler.Options{
-		DisableExporterMetrics: *disableExporterMetrics,
-		TimeoutMargin:          *timeoutMargin,
-		MaxRequests:            *maxRequests,
+	mux.Handle("GET /-/healthy", httphandler.NewHealthHandler())
+	mux.Handle("GET /-/ready", httphandler.NewReadinessHandler(collectors, health.Rules{
+		RequiredCollectors: utils.ExpandEnabledCollectors(*flags.requiredCollectors),
+		FailureThreshold:   *flags.failureThreshold,
 	}))
+	mux.Handle("GET /version", httphandler.NewVersionHandler())
+	mux.Handle("GET /collectors", httphandler.NewCollectorsHandler(collectors))
+
+	if !slices.ContainsFunc(metricsEndpoints, func(endpoint metricsEndpointConfig) bool { return endpoint.Path == *flags.metricsPath }) {
+		mux.Handle("GET "+*flags.metricsPath, metricsHandler)
+	}
+
+	for _, endpoint := range metricsEndpoints {
+		options := handlerOptions
+		options.CollectorNames = endpoint.Collectors
+
+		if endpoint.TimeoutMargin != nil {
+			options.TimeoutMargin = *endpoint.TimeoutMargin
+		}
+
+		if endpoint.MaxRequests != nil {
+			options.MaxRequests = *endpoint.MaxRequests
+		}
+
+		endpointHandler := httphandler.New(logger.With(slog.String("endpoint", endpoint.Path)), collectors, &options)
+
+		mux.Handle("GET "+endpoint.Path, endpointHandler)
+
+		if endpoint.InfluxPath != "" {
+			mux.Handle("GET "+endpoint.InfluxPath, endpointHandler.InfluxHandler())
+		}
+
+		if endpoint.JSONPath != "" {
+			mux.Handle("GET "+endpoint.JSONPath, endpointHandler.JSONHandler())
+		}
+	}
 
-	if *debugEnabled {
+	if *flags.influxPath != "" {
+		mux.Handle("GET "+*flags.influxPath, metricsHandler.InfluxHandler())
+	}
+
+	if *flags.jsonPath != "" {
+		mux.Handle("GET "+*flags.jsonPath, metricsHandler.JSONHandler())
+	}
+
+	if *flags.enableLifecycle {
+		mux.Handle("POST /-/reload", reloader)
+	}
+
+	if *flags.debugEnabled {
 		mux.HandleFunc("GET /debug/pprof/", pprof.Index)
 		mux.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
 		mux.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
@@ -256,7 +413,7 @@ func run() int {
 	errCh := make(chan error, 1)
 
 	go func() {
-		if err := web.ListenAndServe(server, webConfig, logger); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := web.ListenAndServe(server, flags.webConfig, logger); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errCh <- err
 		}
 
@@ -266,6 +423,12 @@ func run() int {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer stop()
 
+	pushers.run(ctx, metricsHandler.Gatherer(0))
+
+	if *flags.configWatchInterval > 0 && *flags.configFile != "" {
+		go reloader.Watch(ctx, *flags.configFile, remote, *flags.configWatchInterval)
+	}
+
 	select {
 	case <-ctx.Done():
 		logger.Info("Shutting down windows_exporter via kill signal")
@@ -281,6 +444,8 @@ func run() int {
 		}
 	}
 
+	pushers.stop()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -291,6 +456,21 @@ func run() int {
 	return 0
 }
 
+// loadMetricRelabelRules loads the metric relabel rules from the metric_relabel_configs key of the configuration file.
+func loadMetricRelabelRules(resolver *config.Resolver) (*relabel.Rules, error) {
+	var relabelConfigs []relabel.Config
+
+	if err := resolver.Unmarshal("metric_relabel_configs", &relabelConfigs); err != nil {
+		return nil, err
+	}
+
+	if len(relabelConfigs) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	return relabel.New(relabelConfigs)
+}
+
 func printCollectorsToStdout() {
 	collectorNames := collector.Available()
 	sort.Strings(collectorNames)
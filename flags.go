@@ -0,0 +1,207 @@
+//go:build windows
+
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/config"
+	"github.com/prometheus-community/windows_exporter/internal/health"
+	"github.com/prometheus-community/windows_exporter/internal/log"
+	"github.com/prometheus-community/windows_exporter/internal/log/flag"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+	"github.com/prometheus/common/version"
+	"github.com/prometheus/exporter-toolkit/web"
+	webflag "github.com/prometheus/exporter-toolkit/web/kingpinflag"
+)
+
+// exporterFlags holds the values of the global flags of windows_exporter.
+type exporterFlags struct {
+	configFile             *string
+	remoteOptions          *config.RemoteOptions
+	configWatchInterval    *time.Duration
+	configCheck            *bool
+	webConfig              *web.FlagConfig
+	metricsPath            *string
+	influxPath             *string
+	jsonPath               *string
+	disableExporterMetrics *bool
+	enableLifecycle        *bool
+	maxRequests            *int
+	enabledCollectors      *string
+	printCollectors        *bool
+	degradedStartup        *bool
+	buildRetryBackoff      *time.Duration
+	buildRetryMaxBackoff   *time.Duration
+	timeoutMargin          *float64
+	coalesceWindow         *time.Duration
+	requiredCollectors     *string
+	failureThreshold       *int
+	constLabels            *map[string]string
+	debugEnabled           *bool
+	processPriority        *string
+	logConfig              *log.Config
+}
+
+// newApp creates the kingpin application with all flags of windows_exporter and the collectors.
+// The collectors start from collectorConfig, which is nil without configuration file.
+// It is called on startup, after the configuration file is loaded and on every reload of the configuration.
+func newApp(collectorConfig *collector.Config) (*kingpin.Application, *exporterFlags, *collector.MetricCollectors) {
+	app := kingpin.New("windows_exporter", "A metrics collector for Windows.")
+
+	flags := &exporterFlags{
+		configFile: app.Flag(
+			"config.file",
+			"YAML configuration file, directory or glob pattern to use. Values set in these files will be overridden by CLI flags.",
+		).String(),
+		remoteOptions: &config.RemoteOptions{},
+		configWatchInterval: app.Flag(
+			"config.file.watch-interval",
+			"Interval to check the YAML configuration file for changes. A changed file triggers a reload of the configuration. A file of a URL is requested with If-None-Match and If-Modified-Since. 0 to disable.",
+		).Default("0s").Duration(),
+		configCheck: app.Flag(
+			"config.check",
+			"If true, validate the configuration file, print the effective configuration and exit. The exit code is 1, if the configuration is invalid.",
+		).Bool(),
+		webConfig: webflag.AddFlags(app, ":9182"),
+		metricsPath: app.Flag(
+			"telemetry.path",
+			"URL path for surfacing collected metrics.",
+		).Default("/metrics").String(),
+		influxPath: app.Flag(
+			"telemetry.influx-path",
+			"URL path for surfacing collected metrics in the InfluxDB line protocol. Empty to disable.",
+		).Default("/metrics/influx").String(),
+		jsonPath: app.Flag(
+			"telemetry.json-path",
+			"URL path for surfacing collected metrics as JSON. Empty to disable.",
+		).Default("/metrics.json").String(),
+		disableExporterMetrics: app.Flag(
+			"web.disable-exporter-metrics",
+			"Exclude metrics about the exporter itself (promhttp_*, process_*, go_*).",
+		).Bool(),
+		enableLifecycle: app.Flag(
+			"web.enable-lifecycle",
+			"Enable the reload of the configuration via HTTP request to /-/reload.",
+		).Bool(),
+		maxRequests: app.Flag(
+			"telemetry.max-requests",
+			"Maximum number of concurrent requests. 0 to disable.",
+		).Default("5").Int(),
+		enabledCollectors: app.Flag(
+			"collectors.enabled",
+			"Comma-separated list of collectors to use. Use '[defaults]' as a placeholder for all the collectors enabled by default.").
+			Default(types.DefaultCollectors).String(),
+		printCollectors: app.Flag(
+			"collectors.print",
+			"If true, print available collectors and exit.",
+		).Bool(),
+		degradedStartup: app.Flag(
+			"collectors.degraded-startup",
+			"If true, collectors which fail to build are retried in the background and windows_exporter starts with the remaining collectors.",
+		).Bool(),
+		buildRetryBackoff: app.Flag(
+			"collectors.build-retry.backoff",
+			"Delay before the first build retry of a collector, which failed to build on startup. The delay doubles after every failed retry.",
+		).Default("30s").Duration(),
+		buildRetryMaxBackoff: app.Flag(
+			"collectors.build-retry.max-backoff",
+			"Maximum delay between build retries of a collector.",
+		).Default("10m").Duration(),
+		timeoutMargin: app.Flag(
+			"scrape.timeout-margin",
+			"Seconds to subtract from the timeout allowed by the client. Tune to allow for overhead or high loads.",
+		).Default("0.5").Float64(),
+		coalesceWindow: app.Flag(
+			"scrape.coalesce-window",
+			"Scrapes of the same collectors and timeout arriving within this window share a single collection run. 0 to disable.",
+		).Default("0s").Duration(),
+		requiredCollectors: app.Flag(
+			"health.required-collectors",
+			"Comma-separated list of collectors, which make the readiness endpoint /-/ready report unhealthy, if they are failing. Other failing collectors report degraded.",
+		).Default("").String(),
+		failureThreshold: app.Flag(
+			"health.failure-threshold",
+			"Number of consecutive failed runs, after which a collector is considered failing by the readiness endpoint /-/ready.",
+		).Default(strconv.Itoa(health.DefaultFailureThreshold)).Int(),
+		constLabels: app.Flag(
+			"telemetry.const-label",
+			"Label in the form name=value, which is attached to every exposed metric. Can be specified multiple times.",
+		).StringMap(),
+		debugEnabled: app.Flag(
+			"debug.enabled",
+			"If true, windows_exporter will expose debug endpoints under /debug/pprof.",
+		).Default("false").Bool(),
+		processPriority: app.Flag(
+			"process.priority",
+			"Priority of the exporter process. Higher priorities may improve exporter responsiveness during periods of system load. Can be one of [\"realtime\", \"high\", \"abovenormal\", \"normal\", \"belownormal\", \"low\"]",
+		).Default("normal").String(),
+		logConfig: &log.Config{},
+	}
+
+	addRemoteFlags(app, flags.remoteOptions)
+	flag.AddFlags(app, flags.logConfig)
+
+	app.Version(version.Print("windows_exporter"))
+	app.HelpFlag.Short('h')
+
+	// Initialize collectors before loading and parsing CLI arguments
+	collectors := collector.NewWithFlags(app, collectorConfig)
+
+	return app, flags, collectors
+}
+
+// addRemoteFlags adds the flags of the loading of the configuration file from a URL.
+func addRemoteFlags(app *kingpin.Application, options *config.RemoteOptions) {
+	app.Flag(
+		"config.file.insecure-skip-verify",
+		"Skip TLS verification in loading YAML configuration.",
+	).Default("false").BoolVar(&options.InsecureSkipVerify)
+	app.Flag(
+		"config.file.ca-file",
+		"File with PEM encoded CA certificates to verify the server in loading YAML configuration from URL.",
+	).StringVar(&options.CAFile)
+	app.Flag(
+		"config.file.cert-file",
+		"File with the PEM encoded client certificate for loading YAML configuration from URL.",
+	).StringVar(&options.CertFile)
+	app.Flag(
+		"config.file.key-file",
+		"File with the PEM encoded key of the client certificate for loading YAML configuration from URL.",
+	).StringVar(&options.KeyFile)
+	app.Flag(
+		"config.file.bearer-token-file",
+		"File with the bearer token for loading YAML configuration from URL. The file is read on every request.",
+	).StringVar(&options.BearerTokenFile)
+	app.Flag(
+		"config.file.basic-auth.username",
+		"Username of the basic authentication for loading YAML configuration from URL.",
+	).StringVar(&options.BasicAuthUsername)
+	app.Flag(
+		"config.file.basic-auth.password-file",
+		"File with the password of the basic authentication for loading YAML configuration from URL. The file is read on every request.",
+	).StringVar(&options.BasicAuthPasswordFile)
+	app.Flag(
+		"config.file.retry-timeout",
+		"Maximum duration of the retries with backoff, if the YAML configuration can't be loaded from URL on startup. 0 to disable.",
+	).Default("30s").DurationVar(&options.RetryTimeout)
+	app.Flag(
+		"config.file.cache-dir",
+		"Directory to keep the last known good YAML configuration loaded from URL. It is used, if the server is unavailable. Empty to disable.",
+	).StringVar(&options.CacheDir)
+}
+
+// loadCollectorConfig loads the configuration of the collectors from the collector key of the configuration file.
+// Values, which are not present in the file, are taken from collector.ConfigDefaults.
+func loadCollectorConfig(resolver *config.Resolver) (*collector.Config, error) {
+	collectorConfig := collector.ConfigDefaults
+
+	if err := resolver.Unmarshal("collector", &collectorConfig); err != nil {
+		return nil, err
+	}
+
+	return &collectorConfig, nil
+}
@@ -0,0 +1,174 @@
+// Package breaker implements a circuit breaker, which skips an operation after repeated failures.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+type State int
+
+const (
+	// Closed allows all calls.
+	Closed State = iota
+	// Open rejects all calls until the backoff period has passed.
+	Open
+	// HalfOpen allows a single probe call. The outcome of the probe closes or re-opens the breaker.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Options configures a Breaker.
+type Options struct {
+	// Threshold is the number of consecutive failures, which opens the breaker. 0 disables the breaker.
+	Threshold int
+	// Backoff is the duration the breaker stays open after it opened for the first time.
+	// The duration doubles every time a probe fails.
+	Backoff time.Duration
+	// MaxBackoff caps the duration the breaker stays open.
+	MaxBackoff time.Duration
+}
+
+// Enabled returns true, if the options enable the breaker.
+func (o Options) Enabled() bool {
+	return o.Threshold > 0
+}
+
+// Validate returns an error, if the options enable the breaker with a backoff, which is not positive.
+// A breaker without backoff would probe the failing operation on every call.
+func (o Options) Validate() error {
+	if !o.Enabled() {
+		return nil
+	}
+
+	if o.Backoff <= 0 {
+		return errors.New("circuit breaker backoff must be greater than 0")
+	}
+
+	return nil
+}
+
+// Breaker is a circuit breaker. It is safe for concurrent use.
+type Breaker struct {
+	options Options
+	now     func() time.Time
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	backoff   time.Duration
+	nextRetry time.Time
+	probing   bool
+}
+
+// New returns a closed Breaker.
+func New(options Options) *Breaker {
+	return &Breaker{
+		options: options,
+		now:     time.Now,
+	}
+}
+
+// Allow returns true, if the call may be executed. If true is returned, the outcome of the call has to be
+// reported by Success or Failure.
+// Once the backoff period has passed, an open breaker becomes half-open and allows a single probe.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Closed:
+		return true
+	case Open:
+		if b.now().Before(b.nextRetry) {
+			return false
+		}
+
+		b.state = HalfOpen
+		b.probing = true
+
+		return true
+	case HalfOpen:
+		if b.probing {
+			return false
+		}
+
+		b.probing = true
+
+		return true
+	default:
+		return true
+	}
+}
+
+// Success reports a successful call. The breaker is closed.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = Closed
+	b.failures = 0
+	b.backoff = 0
+	b.nextRetry = time.Time{}
+	b.probing = false
+}
+
+// Failure reports a failed call. It returns true, if the failure opened the breaker.
+func (b *Breaker) Failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Closed:
+		b.failures++
+
+		if b.failures < b.options.Threshold {
+			return false
+		}
+
+		b.backoff = b.options.Backoff
+	case HalfOpen:
+		b.backoff *= 2
+	case Open:
+		return false
+	}
+
+	if b.options.MaxBackoff > 0 && b.backoff > b.options.MaxBackoff {
+		b.backoff = b.options.MaxBackoff
+	}
+
+	b.state = Open
+	b.probing = false
+	b.nextRetry = b.now().Add(b.backoff)
+
+	return true
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// NextRetry returns the time of the next probe. The zero time is returned, if the breaker is closed.
+func (b *Breaker) NextRetry() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.nextRetry
+}
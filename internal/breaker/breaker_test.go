@@ -0,0 +1,78 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	b := New(Options{Threshold: 3, Backoff: time.Minute, MaxBackoff: 3 * time.Minute})
+	b.now = func() time.Time { return now }
+
+	for range 2 {
+		require.True(t, b.Allow())
+		require.False(t, b.Failure())
+	}
+
+	require.Equal(t, Closed, b.State())
+
+	require.True(t, b.Allow())
+	require.True(t, b.Failure())
+	require.Equal(t, Open, b.State())
+	require.Equal(t, now.Add(time.Minute), b.NextRetry())
+	require.False(t, b.Allow())
+
+	// The probe fails, the backoff doubles.
+	now = now.Add(time.Minute)
+
+	require.True(t, b.Allow())
+	require.Equal(t, HalfOpen, b.State())
+	require.False(t, b.Allow(), "only a single probe is allowed")
+	require.True(t, b.Failure())
+	require.Equal(t, now.Add(2*time.Minute), b.NextRetry())
+
+	// The backoff is capped by MaxBackoff.
+	now = now.Add(2 * time.Minute)
+
+	require.True(t, b.Allow())
+	require.True(t, b.Failure())
+	require.Equal(t, now.Add(3*time.Minute), b.NextRetry())
+
+	// A successful probe closes the breaker.
+	now = now.Add(3 * time.Minute)
+
+	require.True(t, b.Allow())
+	b.Success()
+	require.Equal(t, Closed, b.State())
+	require.True(t, b.NextRetry().IsZero())
+
+	// After closing, the threshold applies again.
+	require.True(t, b.Allow())
+	require.False(t, b.Failure())
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	t.Parallel()
+
+	b := New(Options{Threshold: 2, Backoff: time.Minute})
+
+	require.False(t, b.Failure())
+	b.Success()
+	require.False(t, b.Failure())
+	require.Equal(t, Closed, b.State())
+}
+
+func TestOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Options{}.Validate())
+	require.NoError(t, Options{Threshold: 3, Backoff: time.Minute}.Validate())
+	require.Error(t, Options{Threshold: 3}.Validate())
+	require.Error(t, Options{Threshold: 3, Backoff: -time.Minute}.Validate())
+}
@@ -3,6 +3,7 @@
 package ad
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -652,12 +653,12 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(_ *types.ScrapeContext, _ *slog.Logger, ch chan<- prometheus.Metric) error {
-	return c.collect(ch)
+func (c *Collector) Collect(ctx context.Context, _ *types.ScrapeContext, _ *slog.Logger, ch chan<- prometheus.Metric) error {
+	return c.collect(ctx, ch)
 }
 
-func (c *Collector) collect(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollector.Collect()
+func (c *Collector) collect(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollector.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect DirectoryServices (AD) metrics: %w", err)
 	}
@@ -3,6 +3,7 @@
 package adcs
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -182,13 +183,13 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 	return nil
 }
 
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	if utils.PDHEnabled() {
-		return c.collectPDH(ch)
+		return c.collectPDH(ctx, ch)
 	}
 
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collectADCSCounters(ctx, logger, ch); err != nil {
+	if err := c.collectADCSCounters(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting ADCS metrics",
 			slog.Any("err", err),
 		)
@@ -303,8 +304,8 @@ func (c *Collector) collectADCSCounters(ctx *types.ScrapeContext, logger *slog.L
 	return nil
 }
 
-func (c *Collector) collectPDH(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollector.Collect()
+func (c *Collector) collectPDH(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollector.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect Certification Authority (ADCS) metrics: %w", err)
 	}
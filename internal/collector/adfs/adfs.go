@@ -3,6 +3,7 @@
 package adfs
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -425,14 +426,14 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 	return nil
 }
 
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	if utils.PDHEnabled() {
-		return c.collectPDH(ch)
+		return c.collectPDH(ctx, ch)
 	}
 
 	logger = logger.With(slog.String("collector", Name))
 
-	return c.collect(ctx, logger, ch)
+	return c.collect(scrapeCtx, logger, ch)
 }
 
 func (c *Collector) collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
@@ -704,8 +705,8 @@ func (c *Collector) collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 	return nil
 }
 
-func (c *Collector) collectPDH(ch chan<- prometheus.Metric) error {
-	data, err := c.perfDataCollector.Collect()
+func (c *Collector) collectPDH(ctx context.Context, ch chan<- prometheus.Metric) error {
+	data, err := c.perfDataCollector.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect ADFS metrics: %w", err)
 	}
@@ -3,6 +3,7 @@
 package cache
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -313,13 +314,13 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 }
 
 // Collect implements the Collector interface.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	if utils.PDHEnabled() {
-		return c.collectPDH(ch)
+		return c.collectPDH(ctx, ch)
 	}
 
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collect(ctx, logger, ch); err != nil {
+	if err := c.collect(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting cache metrics",
 			slog.Any("err", err),
 		)
@@ -518,8 +519,8 @@ func (c *Collector) collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 	return nil
 }
 
-func (c *Collector) collectPDH(ch chan<- prometheus.Metric) error {
-	data, err := c.perfDataCollector.Collect()
+func (c *Collector) collectPDH(ctx context.Context, ch chan<- prometheus.Metric) error {
+	data, err := c.perfDataCollector.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect Cache metrics: %w", err)
 	}
@@ -3,6 +3,7 @@
 package cpu
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -230,14 +231,14 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 	return nil
 }
 
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	if utils.PDHEnabled() {
-		return c.collectPDH(ch)
+		return c.collectPDH(ctx, ch)
 	}
 
 	logger = logger.With(slog.String("collector", Name))
 
-	return c.collectFull(ctx, logger, ch)
+	return c.collectFull(scrapeCtx, logger, ch)
 }
 
 func (c *Collector) collectFull(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
@@ -410,8 +411,8 @@ func (c *Collector) collectFull(ctx *types.ScrapeContext, logger *slog.Logger, c
 	return nil
 }
 
-func (c *Collector) collectPDH(ch chan<- prometheus.Metric) error {
-	data, err := c.perfDataCollector.Collect()
+func (c *Collector) collectPDH(ctx context.Context, ch chan<- prometheus.Metric) error {
+	data, err := c.perfDataCollector.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect Processor Information metrics: %w", err)
 	}
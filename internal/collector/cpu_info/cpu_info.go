@@ -3,6 +3,7 @@
 package cpu_info
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -159,9 +160,9 @@ type miProcessor struct {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, _ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collect(ch); err != nil {
+	if err := c.collect(ctx, ch); err != nil {
 		logger.Error("failed collecting cpu_info metrics",
 			slog.Any("err", err),
 		)
@@ -172,9 +173,9 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 	return nil
 }
 
-func (c *Collector) collect(ch chan<- prometheus.Metric) error {
+func (c *Collector) collect(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []miProcessor
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, c.miQuery); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, c.miQuery); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -3,6 +3,7 @@
 package cs
 
 import (
+	"context"
 	"log/slog"
 
 	"github.com/alecthomas/kingpin/v2"
@@ -95,7 +96,7 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, _ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
 
 	if err := c.collect(ch); err != nil {
@@ -3,6 +3,7 @@
 package dfsr
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -21,7 +22,7 @@ import (
 const Name = "dfsr"
 
 type Config struct {
-	CollectorsEnabled []string `yaml:"collectors_enabled"`
+	CollectorsEnabled []string `yaml:"collectors_enabled" flag:"sources-enabled"`
 }
 
 var ConfigDefaults = Config{
@@ -123,19 +124,28 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
-	c.config.CollectorsEnabled = make([]string, 0)
 
 	var collectorsEnabled string
 
+	var collectorsEnabledSet bool
+
 	app.Flag("collector.dfsr.sources-enabled", "Comma-separated list of DFSR Perflib sources to use.").
-		Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
+		Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).IsSetByUser(&collectorsEnabledSet).StringVar(&collectorsEnabled)
 
 	app.Action(func(*kingpin.ParseContext) error {
-		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+		c.config = *config
+
+		if collectorsEnabledSet {
+			c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+		}
 
 		return nil
 	})
@@ -566,14 +576,14 @@ func (c *Collector) getDFSRChildCollectors(enabledCollectors []string) []dfsrCol
 
 // Collect implements the Collector interface.
 // Sends metric values for each metric to the provided prometheus Metric channel.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	if utils.PDHEnabled() {
-		return c.collectPDH(ch)
+		return c.collectPDH(ctx, ch)
 	}
 
 	logger = logger.With(slog.String("collector", Name))
 	for _, fn := range c.dfsrChildCollectors {
-		err := fn(ctx, logger, ch)
+		err := fn(scrapeCtx, logger, ch)
 		if err != nil {
 			return err
 		}
@@ -905,26 +915,26 @@ func (c *Collector) collectVolume(ctx *types.ScrapeContext, logger *slog.Logger,
 	return nil
 }
 
-func (c *Collector) collectPDH(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectPDH(ctx context.Context, ch chan<- prometheus.Metric) error {
 	errs := make([]error, 0, 3)
 
 	if slices.Contains(c.config.CollectorsEnabled, "connection") {
-		errs = append(errs, c.collectPDHConnection(ch))
+		errs = append(errs, c.collectPDHConnection(ctx, ch))
 	}
 
 	if slices.Contains(c.config.CollectorsEnabled, "folder") {
-		errs = append(errs, c.collectPDHFolder(ch))
+		errs = append(errs, c.collectPDHFolder(ctx, ch))
 	}
 
 	if slices.Contains(c.config.CollectorsEnabled, "volume") {
-		errs = append(errs, c.collectPDHVolume(ch))
+		errs = append(errs, c.collectPDHVolume(ctx, ch))
 	}
 
 	return errors.Join(errs...)
 }
 
-func (c *Collector) collectPDHConnection(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollectorConnection.Collect()
+func (c *Collector) collectPDHConnection(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollectorConnection.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect DFS Replication Connections metrics: %w", err)
 	}
@@ -1001,8 +1011,8 @@ func (c *Collector) collectPDHConnection(ch chan<- prometheus.Metric) error {
 	return nil
 }
 
-func (c *Collector) collectPDHFolder(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollectorFolder.Collect()
+func (c *Collector) collectPDHFolder(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollectorFolder.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect DFS Replicated Folders metrics: %w", err)
 	}
@@ -1205,8 +1215,8 @@ func (c *Collector) collectPDHFolder(ch chan<- prometheus.Metric) error {
 	return nil
 }
 
-func (c *Collector) collectPDHVolume(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollectorVolume.Collect()
+func (c *Collector) collectPDHVolume(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollectorVolume.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect DFS Replication Volumes metrics: %w", err)
 	}
@@ -8,5 +8,5 @@ import (
 )
 
 func BenchmarkCollector(b *testing.B) {
-	testutils.FuncBenchmarkCollector(b, dfsr.Name, dfsr.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, dfsr.Name, dfsr.NewWithFlags, nil)
 }
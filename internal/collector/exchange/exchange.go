@@ -3,6 +3,7 @@
 package exchange
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -33,7 +34,7 @@ const (
 )
 
 type Config struct {
-	CollectorsEnabled []string `yaml:"collectors_enabled"`
+	CollectorsEnabled []string `yaml:"collectors_enabled" flag:"enabled"`
 }
 
 var ConfigDefaults = Config{
@@ -121,16 +122,21 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
-	c.config.CollectorsEnabled = make([]string, 0)
 
 	var listAllCollectors bool
 
 	var collectorsEnabled string
 
+	var collectorsEnabledSet bool
+
 	app.Flag(
 		"collector.exchange.list",
 		"List the collectors along with their perflib object name/ids",
@@ -139,7 +145,7 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 	app.Flag(
 		"collector.exchange.enabled",
 		"Comma-separated list of collectors to use. Defaults to all, if not specified.",
-	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
+	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).IsSetByUser(&collectorsEnabledSet).StringVar(&collectorsEnabled)
 
 	app.PreAction(func(*kingpin.ParseContext) error {
 		if listAllCollectors {
@@ -172,7 +178,11 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 	})
 
 	app.Action(func(*kingpin.ParseContext) error {
-		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+		c.config = *config
+
+		if collectorsEnabledSet {
+			c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+		}
 
 		return nil
 	})
@@ -282,13 +292,13 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 }
 
 // Collect collects exchange metrics and sends them to prometheus.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	if utils.PDHEnabled() {
-		return c.collectPDH(ch)
+		return c.collectPDH(ctx, ch)
 	}
 
 	logger = logger.With(slog.String("collector", Name))
-	collectorFuncs := map[string]func(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error{
+	collectorFuncs := map[string]func(scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error{
 		adAccessProcesses:   c.collectADAccessProcesses,
 		transportQueues:     c.collectTransportQueues,
 		httpProxy:           c.collectHTTPProxy,
@@ -302,7 +312,7 @@ func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 	}
 
 	for _, collectorName := range c.config.CollectorsEnabled {
-		if err := collectorFuncs[collectorName](ctx, logger, ch); err != nil {
+		if err := collectorFuncs[collectorName](scrapeCtx, logger, ch); err != nil {
 			logger.Error("Error in "+collectorName,
 				slog.Any("err", err),
 			)
@@ -315,8 +325,8 @@ func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 }
 
 // Collect collects exchange metrics and sends them to prometheus.
-func (c *Collector) collectPDH(ch chan<- prometheus.Metric) error {
-	collectorFuncs := map[string]func(ch chan<- prometheus.Metric) error{
+func (c *Collector) collectPDH(ctx context.Context, ch chan<- prometheus.Metric) error {
+	collectorFuncs := map[string]func(ctx context.Context, ch chan<- prometheus.Metric) error{
 		adAccessProcesses:   c.collectPDHADAccessProcesses,
 		transportQueues:     c.collectPDHTransportQueues,
 		httpProxy:           c.collectPDHHTTPProxy,
@@ -332,7 +342,7 @@ func (c *Collector) collectPDH(ch chan<- prometheus.Metric) error {
 	errs := make([]error, len(c.config.CollectorsEnabled))
 
 	for i, collectorName := range c.config.CollectorsEnabled {
-		errs[i] = collectorFuncs[collectorName](ch)
+		errs[i] = collectorFuncs[collectorName](ctx, ch)
 	}
 
 	return errors.Join(errs...)
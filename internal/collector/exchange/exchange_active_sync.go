@@ -1,6 +1,7 @@
 package exchange
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -69,8 +70,8 @@ func (c *Collector) collectActiveSync(ctx *types.ScrapeContext, logger *slog.Log
 	return nil
 }
 
-func (c *Collector) collectPDHActiveSync(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollectorActiveSync.Collect()
+func (c *Collector) collectPDHActiveSync(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollectorActiveSync.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect MSExchange ActiveSync metrics: %w", err)
 	}
@@ -1,6 +1,7 @@
 package exchange
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -106,8 +107,8 @@ func (c *Collector) collectADAccessProcesses(ctx *types.ScrapeContext, logger *s
 	return nil
 }
 
-func (c *Collector) collectPDHADAccessProcesses(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollectorADAccessProcesses.Collect()
+func (c *Collector) collectPDHADAccessProcesses(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollectorADAccessProcesses.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect MSExchange ADAccess Processes metrics: %w", err)
 	}
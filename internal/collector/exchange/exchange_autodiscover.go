@@ -1,6 +1,7 @@
 package exchange
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -49,8 +50,8 @@ func (c *Collector) collectAutoDiscover(ctx *types.ScrapeContext, logger *slog.L
 	return nil
 }
 
-func (c *Collector) collectPDHAutoDiscover(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollectorAutoDiscover.Collect()
+func (c *Collector) collectPDHAutoDiscover(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollectorAutoDiscover.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect MSExchange Autodiscover metrics: %w", err)
 	}
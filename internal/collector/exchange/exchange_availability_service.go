@@ -1,6 +1,7 @@
 package exchange
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -47,8 +48,8 @@ func (c *Collector) collectAvailabilityService(ctx *types.ScrapeContext, logger
 	return nil
 }
 
-func (c *Collector) collectPDHAvailabilityService(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollectorAvailabilityService.Collect()
+func (c *Collector) collectPDHAvailabilityService(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollectorAvailabilityService.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect MSExchange Availability Service metrics: %w", err)
 	}
@@ -1,6 +1,7 @@
 package exchange
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -102,8 +103,8 @@ func (c *Collector) collectHTTPProxy(ctx *types.ScrapeContext, logger *slog.Logg
 	return nil
 }
 
-func (c *Collector) collectPDHHTTPProxy(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollectorHttpProxy.Collect()
+func (c *Collector) collectPDHHTTPProxy(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollectorHttpProxy.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect MSExchange HttpProxy Service metrics: %w", err)
 	}
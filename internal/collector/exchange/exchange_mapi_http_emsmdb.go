@@ -1,6 +1,7 @@
 package exchange
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -53,8 +54,8 @@ func (c *Collector) collectMapiHttpEmsmdb(ctx *types.ScrapeContext, logger *slog
 	return nil
 }
 
-func (c *Collector) collectPDHMapiHttpEmsmdb(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollectorMapiHttpEmsmdb.Collect()
+func (c *Collector) collectPDHMapiHttpEmsmdb(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollectorMapiHttpEmsmdb.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect MSExchange MapiHttp Emsmdb metrics: %w", err)
 	}
@@ -1,6 +1,7 @@
 package exchange
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -61,8 +62,8 @@ func (c *Collector) collectOWA(ctx *types.ScrapeContext, logger *slog.Logger, ch
 	return nil
 }
 
-func (c *Collector) collectPDHOWA(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollectorOWA.Collect()
+func (c *Collector) collectPDHOWA(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollectorOWA.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect MSExchange OWA metrics: %w", err)
 	}
@@ -1,6 +1,7 @@
 package exchange
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -93,8 +94,8 @@ func (c *Collector) collectRPC(ctx *types.ScrapeContext, logger *slog.Logger, ch
 	return nil
 }
 
-func (c *Collector) collectPDHRPC(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollectorRpcClientAccess.Collect()
+func (c *Collector) collectPDHRPC(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollectorRpcClientAccess.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect MSExchange RpcClientAccess: %w", err)
 	}
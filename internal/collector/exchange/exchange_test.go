@@ -8,5 +8,5 @@ import (
 )
 
 func BenchmarkCollector(b *testing.B) {
-	testutils.FuncBenchmarkCollector(b, exchange.Name, exchange.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, exchange.Name, exchange.NewWithFlags, nil)
 }
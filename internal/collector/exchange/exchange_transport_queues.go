@@ -1,6 +1,7 @@
 package exchange
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -124,8 +125,8 @@ func (c *Collector) collectTransportQueues(ctx *types.ScrapeContext, logger *slo
 	return nil
 }
 
-func (c *Collector) collectPDHTransportQueues(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollectorTransportQueues.Collect()
+func (c *Collector) collectPDHTransportQueues(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollectorTransportQueues.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect MSExchangeTransport Queues: %w", err)
 	}
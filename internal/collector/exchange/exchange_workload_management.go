@@ -1,6 +1,7 @@
 package exchange
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -97,8 +98,8 @@ func (c *Collector) collectWorkloadManagementWorkloads(ctx *types.ScrapeContext,
 	return nil
 }
 
-func (c *Collector) collectPDHWorkloadManagementWorkloads(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollectorWorkloadManagementWorkloads.Collect()
+func (c *Collector) collectPDHWorkloadManagementWorkloads(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollectorWorkloadManagementWorkloads.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect MSExchange WorkloadManagement Workloads: %w", err)
 	}
@@ -3,6 +3,7 @@
 package filetime
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
@@ -20,7 +21,7 @@ import (
 const Name = "filetime"
 
 type Config struct {
-	FilePatterns []string
+	FilePatterns []string `flag:"file-patterns"`
 }
 
 var ConfigDefaults = Config{
@@ -50,22 +51,38 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
-	c.config.FilePatterns = make([]string, 0)
 
 	var filePatterns string
 
+	var filePatternsSet bool
+
 	app.Flag(
 		"collector.filetime.file-patterns",
 		"Comma-separated list of file patterns. Each pattern is a glob pattern that can contain `*`, `?`, and `**` (recursive). See https://github.com/bmatcuk/doublestar#patterns",
-	).Default(strings.Join(ConfigDefaults.FilePatterns, ",")).StringVar(&filePatterns)
+	).Default(strings.Join(ConfigDefaults.FilePatterns, ",")).IsSetByUser(&filePatternsSet).StringVar(&filePatterns)
 
 	app.Action(func(*kingpin.ParseContext) error {
+		c.config = *config
+
+		if filePatternsSet {
+			c.config.FilePatterns = strings.Split(filePatterns, ",")
+		}
+
 		// doublestar.Glob() requires forward slashes
-		c.config.FilePatterns = strings.Split(filepath.ToSlash(filePatterns), ",")
+		patterns := make([]string, 0, len(c.config.FilePatterns))
+		for _, pattern := range c.config.FilePatterns {
+			patterns = append(patterns, filepath.ToSlash(pattern))
+		}
+
+		c.config.FilePatterns = patterns
 
 		return nil
 	})
@@ -111,7 +128,7 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, _ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
 
 	return c.collectGlob(logger, ch)
@@ -8,7 +8,7 @@ import (
 )
 
 func BenchmarkCollector(b *testing.B) {
-	testutils.FuncBenchmarkCollector(b, filetime.Name, filetime.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, filetime.Name, filetime.NewWithFlags, nil)
 }
 
 func TestCollector(t *testing.T) {
@@ -3,6 +3,7 @@
 package fsrmquota
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -138,9 +139,9 @@ func (c *Collector) Build(_ *slog.Logger, miSession *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, _ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collect(ch); err != nil {
+	if err := c.collect(ctx, ch); err != nil {
 		logger.Error("failed collecting fsrmquota metrics",
 			slog.Any("err", err),
 		)
@@ -168,9 +169,9 @@ type MSFT_FSRMQuota struct {
 	SoftLimit       bool `mi:"SoftLimit"`
 }
 
-func (c *Collector) collect(ch chan<- prometheus.Metric) error {
+func (c *Collector) collect(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []MSFT_FSRMQuota
-	if err := c.miSession.Query(&dst, mi.NamespaceRootWindowsFSRM, c.miQuery); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootWindowsFSRM, c.miQuery); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
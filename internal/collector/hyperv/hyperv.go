@@ -3,6 +3,7 @@
 package hyperv
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -756,9 +757,9 @@ func (c *Collector) Build(_ *slog.Logger, miSession *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, _ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collectVmHealth(ch); err != nil {
+	if err := c.collectVmHealth(ctx, ch); err != nil {
 		logger.Error("failed collecting hyperV health status metrics",
 			slog.Any("err", err),
 		)
@@ -766,7 +767,7 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 		return err
 	}
 
-	if err := c.collectVmVid(ch); err != nil {
+	if err := c.collectVmVid(ctx, ch); err != nil {
 		logger.Error("failed collecting hyperV pages metrics",
 			slog.Any("err", err),
 		)
@@ -774,7 +775,7 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 		return err
 	}
 
-	if err := c.collectVmHv(ch); err != nil {
+	if err := c.collectVmHv(ctx, ch); err != nil {
 		logger.Error("failed collecting hyperV hv status metrics",
 			slog.Any("err", err),
 		)
@@ -782,7 +783,7 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 		return err
 	}
 
-	if err := c.collectVmProcessor(ch); err != nil {
+	if err := c.collectVmProcessor(ctx, ch); err != nil {
 		logger.Error("failed collecting hyperV processor metrics",
 			slog.Any("err", err),
 		)
@@ -790,7 +791,7 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 		return err
 	}
 
-	if err := c.collectHostLPUsage(logger, ch); err != nil {
+	if err := c.collectHostLPUsage(ctx, logger, ch); err != nil {
 		logger.Error("failed collecting hyperV host logical processors metrics",
 			slog.Any("err", err),
 		)
@@ -798,7 +799,7 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 		return err
 	}
 
-	if err := c.collectHostCpuUsage(logger, ch); err != nil {
+	if err := c.collectHostCpuUsage(ctx, logger, ch); err != nil {
 		logger.Error("failed collecting hyperV host CPU metrics",
 			slog.Any("err", err),
 		)
@@ -806,7 +807,7 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 		return err
 	}
 
-	if err := c.collectVmCpuUsage(logger, ch); err != nil {
+	if err := c.collectVmCpuUsage(ctx, logger, ch); err != nil {
 		logger.Error("failed collecting hyperV VM CPU metrics",
 			slog.Any("err", err),
 		)
@@ -814,7 +815,7 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 		return err
 	}
 
-	if err := c.collectVmSwitch(ch); err != nil {
+	if err := c.collectVmSwitch(ctx, ch); err != nil {
 		logger.Error("failed collecting hyperV switch metrics",
 			slog.Any("err", err),
 		)
@@ -822,7 +823,7 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 		return err
 	}
 
-	if err := c.collectVmEthernet(ch); err != nil {
+	if err := c.collectVmEthernet(ctx, ch); err != nil {
 		logger.Error("failed collecting hyperV ethernet metrics",
 			slog.Any("err", err),
 		)
@@ -830,7 +831,7 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 		return err
 	}
 
-	if err := c.collectVmStorage(ch); err != nil {
+	if err := c.collectVmStorage(ctx, ch); err != nil {
 		logger.Error("failed collecting hyperV virtual storage metrics",
 			slog.Any("err", err),
 		)
@@ -838,7 +839,7 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 		return err
 	}
 
-	if err := c.collectVmNetwork(ch); err != nil {
+	if err := c.collectVmNetwork(ctx, ch); err != nil {
 		logger.Error("failed collecting hyperV virtual network metrics",
 			slog.Any("err", err),
 		)
@@ -846,7 +847,7 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 		return err
 	}
 
-	if err := c.collectVmMemory(ch); err != nil {
+	if err := c.collectVmMemory(ctx, ch); err != nil {
 		logger.Error("failed collecting hyperV virtual memory metrics",
 			slog.Any("err", err),
 		)
@@ -863,9 +864,9 @@ type Win32_PerfRawData_VmmsVirtualMachineStats_HyperVVirtualMachineHealthSummary
 	HealthOk       uint32 `mi:"HealthOK"`
 }
 
-func (c *Collector) collectVmHealth(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectVmHealth(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_VmmsVirtualMachineStats_HyperVVirtualMachineHealthSummary
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_VmmsVirtualMachineStats_HyperVVirtualMachineHealthSummary"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_VmmsVirtualMachineStats_HyperVVirtualMachineHealthSummary"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -894,9 +895,9 @@ type Win32_PerfRawData_VidPerfProvider_HyperVVMVidPartition struct {
 	RemotePhysicalPages    uint64 `mi:"RemotePhysicalPages"`
 }
 
-func (c *Collector) collectVmVid(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectVmVid(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_VidPerfProvider_HyperVVMVidPartition
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_VidPerfProvider_HyperVVMVidPartition"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_VidPerfProvider_HyperVVMVidPartition"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -956,9 +957,9 @@ type Win32_PerfRawData_HvStats_HyperVHypervisorRootPartition struct {
 	VirtualTLBPages               uint64 `mi:"VirtualTLBPages"`
 }
 
-func (c *Collector) collectVmHv(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectVmHv(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_HvStats_HyperVHypervisorRootPartition
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_HvStats_HyperVHypervisorRootPartition"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_HvStats_HyperVHypervisorRootPartition"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -1092,9 +1093,9 @@ type Win32_PerfRawData_HvStats_HyperVHypervisor struct {
 	VirtualProcessors uint64 `mi:"VirtualProcessors"`
 }
 
-func (c *Collector) collectVmProcessor(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectVmProcessor(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_HvStats_HyperVHypervisor
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_HvStats_HyperVHypervisor"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_HvStats_HyperVHypervisor"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -1123,9 +1124,9 @@ type Win32_PerfRawData_HvStats_HyperVHypervisorLogicalProcessor struct {
 	PercentTotalRunTime      uint64 `mi:"PercentTotalRunTime"`
 }
 
-func (c *Collector) collectHostLPUsage(logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) collectHostLPUsage(ctx context.Context, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_HvStats_HyperVHypervisorLogicalProcessor
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_HvStats_HyperVHypervisorLogicalProcessor"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_HvStats_HyperVHypervisorLogicalProcessor"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -1179,9 +1180,9 @@ type Win32_PerfRawData_HvStats_HyperVHypervisorRootVirtualProcessor struct {
 	CPUWaitTimePerDispatch   uint64 `mi:"CPUWaitTimePerDispatch"`
 }
 
-func (c *Collector) collectHostCpuUsage(logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) collectHostCpuUsage(ctx context.Context, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_HvStats_HyperVHypervisorRootVirtualProcessor
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_HvStats_HyperVHypervisorRootVirtualProcessor"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_HvStats_HyperVHypervisorRootVirtualProcessor"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -1249,9 +1250,9 @@ type Win32_PerfRawData_HvStats_HyperVHypervisorVirtualProcessor struct {
 	CPUWaitTimePerDispatch   uint64 `mi:"CPUWaitTimePerDispatch"`
 }
 
-func (c *Collector) collectVmCpuUsage(logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) collectVmCpuUsage(ctx context.Context, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_HvStats_HyperVHypervisorVirtualProcessor
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_HvStats_HyperVHypervisorVirtualProcessor"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_HvStats_HyperVHypervisorVirtualProcessor"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -1346,9 +1347,9 @@ type Win32_PerfRawData_NvspSwitchStats_HyperVVirtualSwitch struct {
 	PurgedMacAddressesPersec               uint64 `mi:"PurgedMacAddressesPersec"`
 }
 
-func (c *Collector) collectVmSwitch(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectVmSwitch(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_NvspSwitchStats_HyperVVirtualSwitch
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NvspSwitchStats_HyperVVirtualSwitch"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NvspSwitchStats_HyperVVirtualSwitch"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -1510,9 +1511,9 @@ type Win32_PerfRawData_EthernetPerfProvider_HyperVLegacyNetworkAdapter struct {
 	FramesSentPersec     uint64 `mi:"FramesSentPersec"`
 }
 
-func (c *Collector) collectVmEthernet(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectVmEthernet(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_EthernetPerfProvider_HyperVLegacyNetworkAdapter
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_EthernetPerfProvider_HyperVLegacyNetworkAdapter"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_EthernetPerfProvider_HyperVLegacyNetworkAdapter"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -1578,9 +1579,9 @@ type Win32_PerfRawData_Counters_HyperVVirtualStorageDevice struct {
 	WriteOperationsPerSec uint64 `mi:"WriteOperationsPerSec"`
 }
 
-func (c *Collector) collectVmStorage(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectVmStorage(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_Counters_HyperVVirtualStorageDevice
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_Counters_HyperVVirtualStorageDevice"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_Counters_HyperVVirtualStorageDevice"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -1646,9 +1647,9 @@ type Win32_PerfRawData_NvspNicStats_HyperVVirtualNetworkAdapter struct {
 	PacketsSentPersec            uint64 `mi:"PacketsSentPersec"`
 }
 
-func (c *Collector) collectVmNetwork(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectVmNetwork(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_NvspNicStats_HyperVVirtualNetworkAdapter
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NvspNicStats_HyperVVirtualNetworkAdapter"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NvspNicStats_HyperVVirtualNetworkAdapter"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -1718,9 +1719,9 @@ type Win32_PerfRawData_BalancerStats_HyperVDynamicMemoryVM struct {
 	RemovedMemory              uint64 `mi:"RemovedMemory"`
 }
 
-func (c *Collector) collectVmMemory(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectVmMemory(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_BalancerStats_HyperVDynamicMemoryVM
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_BalancerStats_HyperVDynamicMemoryVM"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_BalancerStats_HyperVDynamicMemoryVM"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
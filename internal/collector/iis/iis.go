@@ -3,6 +3,7 @@
 package iis
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"regexp"
@@ -20,10 +21,10 @@ import (
 const Name = "iis"
 
 type Config struct {
-	SiteInclude *regexp.Regexp `yaml:"site_include"`
-	SiteExclude *regexp.Regexp `yaml:"site_exclude"`
-	AppInclude  *regexp.Regexp `yaml:"app_include"`
-	AppExclude  *regexp.Regexp `yaml:"app_exclude"`
+	SiteInclude *regexp.Regexp `yaml:"site_include" flag:"site-include"`
+	SiteExclude *regexp.Regexp `yaml:"site_exclude" flag:"site-exclude"`
+	AppInclude  *regexp.Regexp `yaml:"app_include" flag:"app-include"`
+	AppExclude  *regexp.Regexp `yaml:"app_exclude" flag:"app-exclude"`
 }
 
 var ConfigDefaults = Config{
@@ -189,54 +190,70 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
 
 	var appExclude, appInclude, siteExclude, siteInclude string
 
+	var appExcludeSet, appIncludeSet, siteExcludeSet, siteIncludeSet bool
+
 	app.Flag(
 		"collector.iis.app-exclude",
 		"Regexp of apps to exclude. App name must both match include and not match exclude to be included.",
-	).Default("").StringVar(&appExclude)
+	).Default("").IsSetByUser(&appExcludeSet).StringVar(&appExclude)
 
 	app.Flag(
 		"collector.iis.app-include",
 		"Regexp of apps to include. App name must both match include and not match exclude to be included.",
-	).Default(".+").StringVar(&appInclude)
+	).Default(".+").IsSetByUser(&appIncludeSet).StringVar(&appInclude)
 
 	app.Flag(
 		"collector.iis.site-exclude",
 		"Regexp of sites to exclude. Site name must both match include and not match exclude to be included.",
-	).Default("").StringVar(&siteExclude)
+	).Default("").IsSetByUser(&siteExcludeSet).StringVar(&siteExclude)
 
 	app.Flag(
 		"collector.iis.site-include",
 		"Regexp of sites to include. Site name must both match include and not match exclude to be included.",
-	).Default(".+").StringVar(&siteInclude)
+	).Default(".+").IsSetByUser(&siteIncludeSet).StringVar(&siteInclude)
 
 	app.Action(func(*kingpin.ParseContext) error {
+		c.config = *config
+
 		var err error
 
-		c.config.AppExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", appExclude))
-		if err != nil {
-			return fmt.Errorf("collector.iis.app-exclude: %w", err)
+		if appExcludeSet {
+			c.config.AppExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", appExclude))
+			if err != nil {
+				return fmt.Errorf("collector.iis.app-exclude: %w", err)
+			}
 		}
 
-		c.config.AppInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", appInclude))
-		if err != nil {
-			return fmt.Errorf("collector.iis.app-include: %w", err)
+		if appIncludeSet {
+			c.config.AppInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", appInclude))
+			if err != nil {
+				return fmt.Errorf("collector.iis.app-include: %w", err)
+			}
 		}
 
-		c.config.SiteExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", siteExclude))
-		if err != nil {
-			return fmt.Errorf("collector.iis.site-exclude: %w", err)
+		if siteExcludeSet {
+			c.config.SiteExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", siteExclude))
+			if err != nil {
+				return fmt.Errorf("collector.iis.site-exclude: %w", err)
+			}
 		}
 
-		c.config.SiteInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", siteInclude))
-		if err != nil {
-			return fmt.Errorf("collector.iis.site-include: %w", err)
+		if siteIncludeSet {
+			c.config.SiteInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", siteInclude))
+			if err != nil {
+				return fmt.Errorf("collector.iis.site-include: %w", err)
+			}
 		}
 
 		return nil
@@ -940,9 +957,9 @@ func getIISVersion(logger *slog.Logger) simpleVersion {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collectWebService(ctx, logger, ch); err != nil {
+	if err := c.collectWebService(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting iis metrics",
 			slog.Any("err", err),
 		)
@@ -950,7 +967,7 @@ func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 		return err
 	}
 
-	if err := c.collectAPP_POOL_WAS(ctx, logger, ch); err != nil {
+	if err := c.collectAPP_POOL_WAS(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting iis metrics",
 			slog.Any("err", err),
 		)
@@ -958,7 +975,7 @@ func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 		return err
 	}
 
-	if err := c.collectW3SVC_W3WP(ctx, logger, ch); err != nil {
+	if err := c.collectW3SVC_W3WP(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting iis metrics",
 			slog.Any("err", err),
 		)
@@ -966,7 +983,7 @@ func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 		return err
 	}
 
-	if err := c.collectWebServiceCache(ctx, logger, ch); err != nil {
+	if err := c.collectWebServiceCache(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting iis metrics",
 			slog.Any("err", err),
 		)
@@ -8,5 +8,5 @@ import (
 )
 
 func BenchmarkCollector(b *testing.B) {
-	testutils.FuncBenchmarkCollector(b, iis.Name, iis.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, iis.Name, iis.NewWithFlags, nil)
 }
@@ -3,6 +3,7 @@
 package logical_disk
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -26,8 +27,8 @@ import (
 const Name = "logical_disk"
 
 type Config struct {
-	VolumeInclude *regexp.Regexp `yaml:"volume_include"`
-	VolumeExclude *regexp.Regexp `yaml:"volume_exclude"`
+	VolumeInclude *regexp.Regexp `yaml:"volume_include" flag:"volume-include"`
+	VolumeExclude *regexp.Regexp `yaml:"volume_exclude" flag:"volume-exclude"`
 }
 
 var ConfigDefaults = Config{
@@ -89,34 +90,46 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
 
 	var volumeExclude, volumeInclude string
 
+	var volumeExcludeSet, volumeIncludeSet bool
+
 	app.Flag(
 		"collector.logical_disk.volume-exclude",
 		"Regexp of volumes to exclude. Volume name must both match include and not match exclude to be included.",
-	).Default("").StringVar(&volumeExclude)
+	).Default("").IsSetByUser(&volumeExcludeSet).StringVar(&volumeExclude)
 
 	app.Flag(
 		"collector.logical_disk.volume-include",
 		"Regexp of volumes to include. Volume name must both match include and not match exclude to be included.",
-	).Default(".+").StringVar(&volumeInclude)
+	).Default(".+").IsSetByUser(&volumeIncludeSet).StringVar(&volumeInclude)
 
 	app.Action(func(*kingpin.ParseContext) error {
+		c.config = *config
+
 		var err error
 
-		c.config.VolumeExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", volumeExclude))
-		if err != nil {
-			return fmt.Errorf("collector.logical_disk.volume-exclude: %w", err)
+		if volumeExcludeSet {
+			c.config.VolumeExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", volumeExclude))
+			if err != nil {
+				return fmt.Errorf("collector.logical_disk.volume-exclude: %w", err)
+			}
 		}
 
-		c.config.VolumeInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", volumeInclude))
-		if err != nil {
-			return fmt.Errorf("collector.logical_disk.volume-include: %w", err)
+		if volumeIncludeSet {
+			c.config.VolumeInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", volumeInclude))
+			if err != nil {
+				return fmt.Errorf("collector.logical_disk.volume-include: %w", err)
+			}
 		}
 
 		return nil
@@ -299,14 +312,14 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
 
 	if utils.PDHEnabled() {
-		return c.collectPDH(logger, ch)
+		return c.collectPDH(ctx, logger, ch)
 	}
 
-	if err := c.collect(ctx, logger, ch); err != nil {
+	if err := c.collect(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting logical_disk metrics",
 			slog.Any("err", err),
 		)
@@ -317,14 +330,14 @@ func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 	return nil
 }
 
-func (c *Collector) collectPDH(logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) collectPDH(ctx context.Context, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	var (
 		err    error
 		diskID string
 		info   volumeInfo
 	)
 
-	perfData, err := c.perfDataCollector.Collect()
+	perfData, err := c.perfDataCollector.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect LogicalDisk metrics: %w", err)
 	}
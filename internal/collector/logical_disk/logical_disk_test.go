@@ -13,7 +13,7 @@ func BenchmarkCollector(b *testing.B) {
 	// Whitelist is not set in testing context (kingpin flags not parsed), causing the Collector to skip all disks.
 	localVolumeInclude := ".+"
 	kingpin.CommandLine.GetArg("collector.logical_disk.volume-include").StringVar(&localVolumeInclude)
-	testutils.FuncBenchmarkCollector(b, "logical_disk", logical_disk.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, "logical_disk", logical_disk.NewWithFlags, nil)
 }
 
 func TestCollector(t *testing.T) {
@@ -6,6 +6,7 @@
 package memory
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -380,16 +381,16 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
 
 	errs := make([]error, 0, 2)
 
 	var err error
 	if utils.PDHEnabled() {
-		err = c.collectPDH(ch)
+		err = c.collectPDH(ctx, ch)
 	} else {
-		err = c.collectPerformanceData(ctx, logger, ch)
+		err = c.collectPerformanceData(scrapeCtx, logger, ch)
 	}
 
 	if err != nil {
@@ -642,8 +643,8 @@ func (c *Collector) collectPerformanceData(ctx *types.ScrapeContext, logger *slo
 	return nil
 }
 
-func (c *Collector) collectPDH(ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollector.Collect()
+func (c *Collector) collectPDH(ctx context.Context, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollector.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect Memory metrics: %w", err)
 	}
@@ -1,6 +1,7 @@
 package mscluster
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -16,7 +17,7 @@ import (
 const Name = "mscluster"
 
 type Config struct {
-	CollectorsEnabled []string `yaml:"collectors_enabled"`
+	CollectorsEnabled []string `yaml:"collectors_enabled" flag:"enabled"`
 }
 
 var ConfigDefaults = Config{
@@ -187,21 +188,30 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
-	c.config.CollectorsEnabled = make([]string, 0)
 
 	var collectorsEnabled string
 
+	var collectorsEnabledSet bool
+
 	app.Flag(
 		"collector.mscluster.enabled",
 		"Comma-separated list of collectors to use.",
-	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
+	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).IsSetByUser(&collectorsEnabledSet).StringVar(&collectorsEnabled)
 
 	app.Action(func(*kingpin.ParseContext) error {
-		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+		c.config = *config
+
+		if collectorsEnabledSet {
+			c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+		}
 
 		return nil
 	})
@@ -257,7 +267,7 @@ func (c *Collector) Build(_ *slog.Logger, miSession *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(_ *types.ScrapeContext, _ *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, _ *types.ScrapeContext, _ *slog.Logger, ch chan<- prometheus.Metric) error {
 	if len(c.config.CollectorsEnabled) == 0 {
 		return nil
 	}
@@ -269,31 +279,31 @@ func (c *Collector) Collect(_ *types.ScrapeContext, _ *slog.Logger, ch chan<- pr
 	)
 
 	if slices.Contains(c.config.CollectorsEnabled, "cluster") {
-		if err = c.collectCluster(ch); err != nil {
+		if err = c.collectCluster(ctx, ch); err != nil {
 			errs = append(errs, fmt.Errorf("failed to collect cluster metrics: %w", err))
 		}
 	}
 
 	if slices.Contains(c.config.CollectorsEnabled, "network") {
-		if err = c.collectNetwork(ch); err != nil {
+		if err = c.collectNetwork(ctx, ch); err != nil {
 			errs = append(errs, fmt.Errorf("failed to collect network metrics: %w", err))
 		}
 	}
 
 	if slices.Contains(c.config.CollectorsEnabled, "node") {
-		if nodeNames, err = c.collectNode(ch); err != nil {
+		if nodeNames, err = c.collectNode(ctx, ch); err != nil {
 			errs = append(errs, fmt.Errorf("failed to collect node metrics: %w", err))
 		}
 	}
 
 	if slices.Contains(c.config.CollectorsEnabled, "resource") {
-		if err = c.collectResource(ch, nodeNames); err != nil {
+		if err = c.collectResource(ctx, ch, nodeNames); err != nil {
 			errs = append(errs, fmt.Errorf("failed to collect resource metrics: %w", err))
 		}
 	}
 
 	if slices.Contains(c.config.CollectorsEnabled, "resourcegroup") {
-		if err = c.collectResourceGroup(ch, nodeNames); err != nil {
+		if err = c.collectResourceGroup(ctx, ch, nodeNames); err != nil {
 			errs = append(errs, fmt.Errorf("failed to collect resource group metrics: %w", err))
 		}
 	}
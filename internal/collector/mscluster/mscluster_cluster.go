@@ -1,6 +1,7 @@
 package mscluster
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/prometheus-community/windows_exporter/internal/mi"
@@ -560,9 +561,9 @@ func (c *Collector) buildCluster() {
 	)
 }
 
-func (c *Collector) collectCluster(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectCluster(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []msClusterCluster
-	if err := c.miSession.Query(&dst, mi.NamespaceRootMSCluster, utils.Must(mi.NewQuery("SELECT * MSCluster_Cluster"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootMSCluster, utils.Must(mi.NewQuery("SELECT * MSCluster_Cluster"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
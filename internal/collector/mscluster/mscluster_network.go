@@ -1,6 +1,7 @@
 package mscluster
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/prometheus-community/windows_exporter/internal/mi"
@@ -58,10 +59,10 @@ func (c *Collector) buildNetwork() {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus metric channel.
-func (c *Collector) collectNetwork(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectNetwork(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []msClusterNetwork
 
-	if err := c.miSession.Query(&dst, mi.NamespaceRootMSCluster, utils.Must(mi.NewQuery("SELECT * MSCluster_Node"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootMSCluster, utils.Must(mi.NewQuery("SELECT * MSCluster_Node"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
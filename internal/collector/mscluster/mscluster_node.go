@@ -1,6 +1,7 @@
 package mscluster
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/prometheus-community/windows_exporter/internal/mi"
@@ -121,10 +122,10 @@ func (c *Collector) buildNode() {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) collectNode(ch chan<- prometheus.Metric) ([]string, error) {
+func (c *Collector) collectNode(ctx context.Context, ch chan<- prometheus.Metric) ([]string, error) {
 	var dst []msClusterNode
 
-	if err := c.miSession.Query(&dst, mi.NamespaceRootMSCluster, utils.Must(mi.NewQuery("SELECT * FROM MSCluster_Node"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootMSCluster, utils.Must(mi.NewQuery("SELECT * FROM MSCluster_Node"))); err != nil {
 		return nil, fmt.Errorf("WMI query failed: %w", err)
 	}
 
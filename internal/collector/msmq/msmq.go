@@ -3,6 +3,7 @@
 package msmq
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -18,7 +19,7 @@ import (
 const Name = "msmq"
 
 type Config struct {
-	QueryWhereClause *string `yaml:"query_where_clause"`
+	QueryWhereClause *string `yaml:"query_where_clause" flag:"msmq-where"`
 }
 
 var ConfigDefaults = Config{
@@ -52,14 +53,36 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
 
+	var queryWhereClause string
+
+	var queryWhereClauseSet bool
+
 	app.Flag("collector.msmq.msmq-where", "WQL 'where' clause to use in WMI metrics query. "+
 		"Limits the response to the msmqs you specify and reduces the size of the response.").
-		Default(*c.config.QueryWhereClause).StringVar(c.config.QueryWhereClause)
+		Default(*ConfigDefaults.QueryWhereClause).IsSetByUser(&queryWhereClauseSet).StringVar(&queryWhereClause)
+
+	app.Action(func(*kingpin.ParseContext) error {
+		c.config = *config
+
+		if queryWhereClauseSet {
+			c.config.QueryWhereClause = &queryWhereClause
+		}
+
+		if c.config.QueryWhereClause == nil {
+			c.config.QueryWhereClause = ConfigDefaults.QueryWhereClause
+		}
+
+		return nil
+	})
 
 	return c
 }
@@ -119,9 +142,9 @@ func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, _ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collect(ch); err != nil {
+	if err := c.collect(ctx, ch); err != nil {
 		logger.Error("failed collecting msmq metrics",
 			slog.Any("err", err),
 		)
@@ -141,7 +164,7 @@ type msmqQueue struct {
 	MessagesInQueue        uint64 `mi:"MessagesInQueue"`
 }
 
-func (c *Collector) collect(ch chan<- prometheus.Metric) error {
+func (c *Collector) collect(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []msmqQueue
 
 	query := "SELECT * FROM Win32_PerfRawData_MSMQ_MSMQQueue"
@@ -154,7 +177,7 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) error {
 		return fmt.Errorf("failed to create WMI query: %w", err)
 	}
 
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, queryExpression); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, queryExpression); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -9,5 +9,5 @@ import (
 
 func BenchmarkCollector(b *testing.B) {
 	// No context name required as Collector source is WMI
-	testutils.FuncBenchmarkCollector(b, msmq.Name, msmq.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, msmq.Name, msmq.NewWithFlags, nil)
 }
@@ -3,6 +3,7 @@
 package mssql
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -23,7 +24,7 @@ import (
 const Name = "mssql"
 
 type Config struct {
-	CollectorsEnabled []string `yaml:"collectors_enabled"`
+	CollectorsEnabled []string `yaml:"collectors_enabled" flag:"classes-enabled"`
 }
 
 var ConfigDefaults = Config{
@@ -442,15 +443,21 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
 
 	var listAllCollectors bool
 
 	var collectorsEnabled string
 
+	var collectorsEnabledSet bool
+
 	app.Flag(
 		"collector.mssql.class-print",
 		"If true, print available mssql WMI classes and exit.  Only displays if the mssql collector is enabled.",
@@ -459,7 +466,7 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 	app.Flag(
 		"collector.mssql.classes-enabled",
 		"Comma-separated list of mssql WMI classes to use.",
-	).Default(strings.Join(c.config.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
+	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).IsSetByUser(&collectorsEnabledSet).StringVar(&collectorsEnabled)
 
 	app.PreAction(func(*kingpin.ParseContext) error {
 		if listAllCollectors {
@@ -479,7 +486,11 @@ func NewWithFlags(app *kingpin.Application) *Collector {
 	})
 
 	app.Action(func(*kingpin.ParseContext) error {
-		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+		c.config = *config
+
+		if collectorsEnabledSet {
+			c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+		}
 
 		return nil
 	})
@@ -2026,7 +2037,7 @@ func (c *Collector) execute(ctx *types.ScrapeContext, logger *slog.Logger, name
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
 	wg := sync.WaitGroup{}
 
@@ -2036,7 +2047,7 @@ func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 
 			wg.Add(1)
 
-			go c.execute(ctx, logger, name, function, ch, sqlInstance, &wg)
+			go c.execute(scrapeCtx, logger, name, function, ch, sqlInstance, &wg)
 		}
 	}
 
@@ -8,5 +8,5 @@ import (
 )
 
 func BenchmarkCollector(b *testing.B) {
-	testutils.FuncBenchmarkCollector(b, mssql.Name, mssql.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, mssql.Name, mssql.NewWithFlags, nil)
 }
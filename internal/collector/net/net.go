@@ -3,6 +3,7 @@
 package net
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -25,9 +26,9 @@ import (
 const Name = "net"
 
 type Config struct {
-	NicExclude        *regexp.Regexp `yaml:"nic_exclude"`
-	NicInclude        *regexp.Regexp `yaml:"nic_include"`
-	CollectorsEnabled []string       `yaml:"collectors_enabled"`
+	NicExclude        *regexp.Regexp `yaml:"nic_exclude" flag:"nic-exclude"`
+	NicInclude        *regexp.Regexp `yaml:"nic_include" flag:"nic-include"`
+	CollectorsEnabled []string       `yaml:"collectors_enabled" flag:"enabled"`
 }
 
 var ConfigDefaults = Config{
@@ -87,44 +88,57 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
-	c.config.CollectorsEnabled = make([]string, 0)
 
 	var nicExclude, nicInclude string
 
 	var collectorsEnabled string
 
+	var nicExcludeSet, nicIncludeSet, collectorsEnabledSet bool
+
 	app.Flag(
 		"collector.net.nic-exclude",
 		"Regexp of NIC:s to exclude. NIC name must both match include and not match exclude to be included.",
-	).Default("").StringVar(&nicExclude)
+	).Default("").IsSetByUser(&nicExcludeSet).StringVar(&nicExclude)
 
 	app.Flag(
 		"collector.net.nic-include",
 		"Regexp of NIC:s to include. NIC name must both match include and not match exclude to be included.",
-	).Default(".+").StringVar(&nicInclude)
+	).Default(".+").IsSetByUser(&nicIncludeSet).StringVar(&nicInclude)
 
 	app.Flag(
 		"collector.net.enabled",
 		"Comma-separated list of collectors to use. Defaults to all, if not specified.",
-	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
+	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).IsSetByUser(&collectorsEnabledSet).StringVar(&collectorsEnabled)
 
 	app.Action(func(*kingpin.ParseContext) error {
-		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+		c.config = *config
+
+		if collectorsEnabledSet {
+			c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+		}
 
 		var err error
 
-		c.config.NicExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", nicExclude))
-		if err != nil {
-			return fmt.Errorf("collector.net.nic-exclude: %w", err)
+		if nicExcludeSet {
+			c.config.NicExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", nicExclude))
+			if err != nil {
+				return fmt.Errorf("collector.net.nic-exclude: %w", err)
+			}
 		}
 
-		c.config.NicInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", nicInclude))
-		if err != nil {
-			return fmt.Errorf("collector.net.nic-include: %w", err)
+		if nicIncludeSet {
+			c.config.NicInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", nicInclude))
+			if err != nil {
+				return fmt.Errorf("collector.net.nic-include: %w", err)
+			}
 		}
 
 		return nil
@@ -277,16 +291,16 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
 
 	if slices.Contains(c.config.CollectorsEnabled, "metrics") {
 		var err error
 
 		if utils.PDHEnabled() {
-			err = c.collectPDH(ch)
+			err = c.collectPDH(ctx, ch)
 		} else {
-			err = c.collect(ctx, logger, ch)
+			err = c.collect(scrapeCtx, logger, ch)
 		}
 
 		if err != nil {
@@ -400,8 +414,8 @@ func (c *Collector) collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 	return nil
 }
 
-func (c *Collector) collectPDH(ch chan<- prometheus.Metric) error {
-	data, err := c.perfDataCollector.Collect()
+func (c *Collector) collectPDH(ctx context.Context, ch chan<- prometheus.Metric) error {
+	data, err := c.perfDataCollector.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect Network Information metrics: %w", err)
 	}
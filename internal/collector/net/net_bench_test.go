@@ -15,5 +15,5 @@ func BenchmarkCollector(b *testing.B) {
 	localNicInclude := ".+"
 
 	kingpin.CommandLine.GetArg("collector.net.nic-include").StringVar(&localNicInclude)
-	testutils.FuncBenchmarkCollector(b, net.Name, net.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, net.Name, net.NewWithFlags, nil)
 }
@@ -3,6 +3,7 @@
 package netframework
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -17,7 +18,7 @@ import (
 const Name = "netframework"
 
 type Config struct {
-	CollectorsEnabled []string `yaml:"collectors_enabled"`
+	CollectorsEnabled []string `yaml:"collectors_enabled" flag:"enabled"`
 }
 
 var ConfigDefaults = Config{
@@ -127,8 +128,22 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(_ *kingpin.Application) *Collector {
-	return &Collector{}
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
+	c := &Collector{
+		config: *config,
+	}
+
+	app.Action(func(*kingpin.ParseContext) error {
+		c.config = *config
+
+		return nil
+	})
+
+	return c
 }
 
 func (c *Collector) GetName() string {
@@ -187,56 +202,56 @@ func (c *Collector) Build(_ *slog.Logger, miSession *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(_ *types.ScrapeContext, _ *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, _ *types.ScrapeContext, _ *slog.Logger, ch chan<- prometheus.Metric) error {
 	var (
 		err  error
 		errs []error
 	)
 
 	if slices.Contains(c.config.CollectorsEnabled, collectorClrExceptions) {
-		if err = c.collectClrExceptions(ch); err != nil {
+		if err = c.collectClrExceptions(ctx, ch); err != nil {
 			errs = append(errs, fmt.Errorf("failed to collect %s metrics: %w", collectorClrExceptions, err))
 		}
 	}
 
 	if slices.Contains(c.config.CollectorsEnabled, collectorClrInterop) {
-		if err = c.collectClrInterop(ch); err != nil {
+		if err = c.collectClrInterop(ctx, ch); err != nil {
 			errs = append(errs, fmt.Errorf("failed to collect %s metrics: %w", collectorClrInterop, err))
 		}
 	}
 
 	if slices.Contains(c.config.CollectorsEnabled, collectorClrJIT) {
-		if err = c.collectClrJIT(ch); err != nil {
+		if err = c.collectClrJIT(ctx, ch); err != nil {
 			errs = append(errs, fmt.Errorf("failed to collect %s metrics: %w", collectorClrJIT, err))
 		}
 	}
 
 	if slices.Contains(c.config.CollectorsEnabled, collectorClrLoading) {
-		if err = c.collectClrLoading(ch); err != nil {
+		if err = c.collectClrLoading(ctx, ch); err != nil {
 			errs = append(errs, fmt.Errorf("failed to collect %s metrics: %w", collectorClrLoading, err))
 		}
 	}
 
 	if slices.Contains(c.config.CollectorsEnabled, collectorClrLocksAndThreads) {
-		if err = c.collectClrLocksAndThreads(ch); err != nil {
+		if err = c.collectClrLocksAndThreads(ctx, ch); err != nil {
 			errs = append(errs, fmt.Errorf("failed to collect %s metrics: %w", collectorClrLocksAndThreads, err))
 		}
 	}
 
 	if slices.Contains(c.config.CollectorsEnabled, collectorClrMemory) {
-		if err = c.collectClrMemory(ch); err != nil {
+		if err = c.collectClrMemory(ctx, ch); err != nil {
 			errs = append(errs, fmt.Errorf("failed to collect %s metrics: %w", collectorClrMemory, err))
 		}
 	}
 
 	if slices.Contains(c.config.CollectorsEnabled, collectorClrRemoting) {
-		if err = c.collectClrRemoting(ch); err != nil {
+		if err = c.collectClrRemoting(ctx, ch); err != nil {
 			errs = append(errs, fmt.Errorf("failed to collect %s metrics: %w", collectorClrRemoting, err))
 		}
 	}
 
 	if slices.Contains(c.config.CollectorsEnabled, collectorClrSecurity) {
-		if err = c.collectClrSecurity(ch); err != nil {
+		if err = c.collectClrSecurity(ctx, ch); err != nil {
 			errs = append(errs, fmt.Errorf("failed to collect %s metrics: %w", collectorClrSecurity, err))
 		}
 	}
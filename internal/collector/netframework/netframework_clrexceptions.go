@@ -3,6 +3,7 @@
 package netframework
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/prometheus-community/windows_exporter/internal/mi"
@@ -48,9 +49,9 @@ type Win32_PerfRawData_NETFramework_NETCLRExceptions struct {
 	ThrowToCatchDepthPersec    uint32 `mi:"ThrowToCatchDepthPersec"`
 }
 
-func (c *Collector) collectClrExceptions(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectClrExceptions(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_NETFramework_NETCLRExceptions
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NETFramework_NETCLRExceptions"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NETFramework_NETCLRExceptions"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -3,6 +3,7 @@
 package netframework
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/prometheus-community/windows_exporter/internal/mi"
@@ -42,9 +43,9 @@ type Win32_PerfRawData_NETFramework_NETCLRInterop struct {
 	NumberofTLBimportsPersec uint32 `mi:"NumberofTLBimportsPersec"`
 }
 
-func (c *Collector) collectClrInterop(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectClrInterop(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_NETFramework_NETCLRInterop
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NETFramework_NETCLRInterop"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NETFramework_NETCLRInterop"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -3,6 +3,7 @@
 package netframework
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/prometheus-community/windows_exporter/internal/mi"
@@ -50,9 +51,9 @@ type Win32_PerfRawData_NETFramework_NETCLRJit struct {
 	TotalNumberofILBytesJitted uint32 `mi:"TotalNumberofILBytesJitted"`
 }
 
-func (c *Collector) collectClrJIT(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectClrJIT(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_NETFramework_NETCLRJit
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NETFramework_NETCLRJit"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NETFramework_NETCLRJit"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -3,6 +3,7 @@
 package netframework
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/prometheus-community/windows_exporter/internal/mi"
@@ -71,9 +72,9 @@ type Win32_PerfRawData_NETFramework_NETCLRLocksAndThreads struct {
 	TotalNumberofContentions         uint32 `mi:"TotalNumberofContentions"`
 }
 
-func (c *Collector) collectClrLocksAndThreads(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectClrLocksAndThreads(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_NETFramework_NETCLRLocksAndThreads
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NETFramework_NETCLRLocksAndThreads"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NETFramework_NETCLRLocksAndThreads"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -3,6 +3,7 @@
 package netframework
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/prometheus-community/windows_exporter/internal/mi"
@@ -62,9 +63,9 @@ type Win32_PerfRawData_NETFramework_NETCLRRemoting struct {
 	TotalRemoteCalls               uint32 `mi:"TotalRemoteCalls"`
 }
 
-func (c *Collector) collectClrRemoting(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectClrRemoting(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_NETFramework_NETCLRRemoting
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NETFramework_NETCLRRemoting"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NETFramework_NETCLRRemoting"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
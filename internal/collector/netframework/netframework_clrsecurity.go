@@ -3,6 +3,7 @@
 package netframework
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/prometheus-community/windows_exporter/internal/mi"
@@ -49,9 +50,9 @@ type Win32_PerfRawData_NETFramework_NETCLRSecurity struct {
 	TotalRuntimeChecks           uint32 `mi:"TotalRuntimeChecks"`
 }
 
-func (c *Collector) collectClrSecurity(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectClrSecurity(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_NETFramework_NETCLRSecurity
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NETFramework_NETCLRSecurity"))); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, utils.Must(mi.NewQuery("SELECT * Win32_PerfRawData_NETFramework_NETCLRSecurity"))); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
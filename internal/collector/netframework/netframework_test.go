@@ -9,5 +9,5 @@ import (
 
 func BenchmarkCollector(b *testing.B) {
 	// No context name required as Collector source is WMI
-	testutils.FuncBenchmarkCollector(b, netframework.Name, netframework.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, netframework.Name, netframework.NewWithFlags, nil)
 }
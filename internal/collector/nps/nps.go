@@ -1,6 +1,7 @@
 package nps
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -258,15 +259,15 @@ func (c *Collector) Build(_ *slog.Logger, miSession *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, _ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.CollectAccept(ch); err != nil {
+	if err := c.CollectAccept(ctx, ch); err != nil {
 		logger.Error(fmt.Sprintf("failed collecting NPS accept data: %s", err))
 
 		return err
 	}
 
-	if err := c.CollectAccounting(ch); err != nil {
+	if err := c.CollectAccounting(ctx, ch); err != nil {
 		logger.Error(fmt.Sprintf("failed collecting NPS accounting data: %s", err))
 
 		return err
@@ -314,9 +315,9 @@ type Win32_PerfRawData_IAS_NPSAccountingServer struct {
 
 // CollectAccept sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) CollectAccept(ch chan<- prometheus.Metric) error {
+func (c *Collector) CollectAccept(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_IAS_NPSAuthenticationServer
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, c.miQueryAuthenticationServer); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, c.miQueryAuthenticationServer); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -401,9 +402,9 @@ func (c *Collector) CollectAccept(ch chan<- prometheus.Metric) error {
 	return nil
 }
 
-func (c *Collector) CollectAccounting(ch chan<- prometheus.Metric) error {
+func (c *Collector) CollectAccounting(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_IAS_NPSAccountingServer
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, c.miQueryAccountingServer); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, c.miQueryAccountingServer); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -3,6 +3,7 @@
 package os
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -227,7 +228,7 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
 
 	errs := make([]error, 0, 5)
@@ -266,7 +267,7 @@ func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 		errs = append(errs, err)
 	}
 
-	if err := c.collectPaging(ctx, logger, ch); err != nil {
+	if err := c.collectPaging(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting os paging metrics",
 			slog.Any("err", err),
 		)
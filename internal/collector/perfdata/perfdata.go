@@ -3,6 +3,7 @@
 package perfdata
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -49,20 +50,30 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
 
 	var objects string
 
+	var objectsSet bool
+
 	app.Flag(
 		"collector.perfdata.objects",
 		"Objects of performance data to observe. See docs for more information on how to use this flag. By default, no objects are observed.",
-	).Default("").StringVar(&objects)
+	).Default("").IsSetByUser(&objectsSet).StringVar(&objects)
 
 	app.Action(func(*kingpin.ParseContext) error {
-		if objects == "" {
+		c.config = *config
+		// Build stores the performance data collectors in the objects.
+		c.config.Objects = slices.Clone(config.Objects)
+
+		if !objectsSet || objects == "" {
 			return nil
 		}
 
@@ -85,8 +96,14 @@ func (c *Collector) GetPerfCounter(_ *slog.Logger) ([]string, error) {
 }
 
 func (c *Collector) Close(_ *slog.Logger) error {
-	for _, object := range c.config.Objects {
+	for i, object := range c.config.Objects {
+		// The objects after a failed build have no collector.
+		if object.collector == nil {
+			continue
+		}
+
 		object.collector.Close()
+		c.config.Objects[i].collector = nil
 	}
 
 	return nil
@@ -113,8 +130,8 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
-	if err := c.collect(ch); err != nil {
+func (c *Collector) Collect(ctx context.Context, _ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+	if err := c.collect(ctx, ch); err != nil {
 		logger.Error("failed collecting performance data metrics",
 			slog.Any("err", err),
 		)
@@ -125,9 +142,9 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 	return nil
 }
 
-func (c *Collector) collect(ch chan<- prometheus.Metric) error {
+func (c *Collector) collect(ctx context.Context, ch chan<- prometheus.Metric) error {
 	for _, object := range c.config.Objects {
-		data, err := object.collector.Collect()
+		data, err := object.collector.Collect(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to collect data: %w", err)
 		}
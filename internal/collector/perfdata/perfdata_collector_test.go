@@ -3,6 +3,7 @@
 package perfdata_test
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
@@ -29,7 +30,7 @@ func (a collectorAdapter) Describe(_ chan<- *prometheus.Desc) {}
 func (a collectorAdapter) Collect(ch chan<- prometheus.Metric) {
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
-	if err := a.Collector.Collect(nil, logger, ch); err != nil {
+	if err := a.Collector.Collect(context.Background(), nil, logger, ch); err != nil {
 		panic(fmt.Sprintf("failed to update collector: %v", err))
 	}
 }
@@ -14,5 +14,5 @@ func BenchmarkCollector(b *testing.B) {
 	perfDataObjects := `[{"object":"Processor Information","instances":["*"],"counters":{"*": {}}}]`
 	kingpin.CommandLine.GetArg("collector.perfdata.objects").StringVar(&perfDataObjects)
 
-	testutils.FuncBenchmarkCollector(b, perfdata.Name, perfdata.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, perfdata.Name, perfdata.NewWithFlags, nil)
 }
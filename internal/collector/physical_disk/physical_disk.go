@@ -3,6 +3,7 @@
 package physical_disk
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"regexp"
@@ -19,8 +20,8 @@ import (
 const Name = "physical_disk"
 
 type Config struct {
-	DiskInclude *regexp.Regexp `yaml:"disk_include"`
-	DiskExclude *regexp.Regexp `yaml:"disk_exclude"`
+	DiskInclude *regexp.Regexp `yaml:"disk_include" flag:"disk-include"`
+	DiskExclude *regexp.Regexp `yaml:"disk_exclude" flag:"disk-exclude"`
 }
 
 var ConfigDefaults = Config{
@@ -66,34 +67,46 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
 
 	var diskExclude, diskInclude string
 
+	var diskExcludeSet, diskIncludeSet bool
+
 	app.Flag(
 		"collector.physical_disk.disk-exclude",
 		"Regexp of disks to exclude. Disk number must both match include and not match exclude to be included.",
-	).Default("").StringVar(&diskExclude)
+	).Default("").IsSetByUser(&diskExcludeSet).StringVar(&diskExclude)
 
 	app.Flag(
 		"collector.physical_disk.disk-include",
 		"Regexp of disks to include. Disk number must both match include and not match exclude to be included.",
-	).Default(".+").StringVar(&diskInclude)
+	).Default(".+").IsSetByUser(&diskIncludeSet).StringVar(&diskInclude)
 
 	app.Action(func(*kingpin.ParseContext) error {
+		c.config = *config
+
 		var err error
 
-		c.config.DiskExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", diskExclude))
-		if err != nil {
-			return fmt.Errorf("collector.physical_disk.disk-exclude: %w", err)
+		if diskExcludeSet {
+			c.config.DiskExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", diskExclude))
+			if err != nil {
+				return fmt.Errorf("collector.physical_disk.disk-exclude: %w", err)
+			}
 		}
 
-		c.config.DiskInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", diskInclude))
-		if err != nil {
-			return fmt.Errorf("collector.physical_disk.disk-include: %w", err)
+		if diskIncludeSet {
+			c.config.DiskInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", diskInclude))
+			if err != nil {
+				return fmt.Errorf("collector.physical_disk.disk-include: %w", err)
+			}
 		}
 
 		return nil
@@ -204,9 +217,9 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collect(ctx, logger, ch); err != nil {
+	if err := c.collect(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting physical_disk metrics",
 			slog.Any("err", err),
 		)
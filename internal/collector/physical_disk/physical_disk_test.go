@@ -8,7 +8,7 @@ import (
 )
 
 func BenchmarkCollector(b *testing.B) {
-	testutils.FuncBenchmarkCollector(b, physical_disk.Name, physical_disk.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, physical_disk.Name, physical_disk.NewWithFlags, nil)
 }
 
 func TestCollector(t *testing.T) {
@@ -3,6 +3,7 @@
 package printer
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -29,8 +30,8 @@ var printerStatusMap = map[uint16]string{
 }
 
 type Config struct {
-	PrinterInclude *regexp.Regexp `yaml:"printer_include"`
-	PrinterExclude *regexp.Regexp `yaml:"printer_exclude"`
+	PrinterInclude *regexp.Regexp `yaml:"printer_include" flag:"include"`
+	PrinterExclude *regexp.Regexp `yaml:"printer_exclude" flag:"exclude"`
 }
 
 var ConfigDefaults = Config{
@@ -69,34 +70,46 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
 
 	var printerInclude, printerExclude string
 
+	var printerIncludeSet, printerExcludeSet bool
+
 	app.Flag(
 		"collector.printer.include",
 		"Regular expression to match printers to collect metrics for",
-	).Default(".+").StringVar(&printerInclude)
+	).Default(".+").IsSetByUser(&printerIncludeSet).StringVar(&printerInclude)
 
 	app.Flag(
 		"collector.printer.exclude",
 		"Regular expression to match printers to exclude",
-	).Default("").StringVar(&printerExclude)
+	).Default("").IsSetByUser(&printerExcludeSet).StringVar(&printerExclude)
 
 	app.Action(func(*kingpin.ParseContext) error {
+		c.config = *config
+
 		var err error
 
-		c.config.PrinterInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", printerInclude))
-		if err != nil {
-			return fmt.Errorf("collector.printer.include: %w", err)
+		if printerIncludeSet {
+			c.config.PrinterInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", printerInclude))
+			if err != nil {
+				return fmt.Errorf("collector.printer.include: %w", err)
+			}
 		}
 
-		c.config.PrinterExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", printerExclude))
-		if err != nil {
-			return fmt.Errorf("collector.printer.exclude: %w", err)
+		if printerExcludeSet {
+			c.config.PrinterExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", printerExclude))
+			if err != nil {
+				return fmt.Errorf("collector.printer.exclude: %w", err)
+			}
 		}
 
 		return nil
@@ -169,23 +182,23 @@ type wmiPrintJob struct {
 	Status string `mi:"Status"`
 }
 
-func (c *Collector) Collect(_ *types.ScrapeContext, _ *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, _ *types.ScrapeContext, _ *slog.Logger, ch chan<- prometheus.Metric) error {
 	var errs []error
 
-	if err := c.collectPrinterStatus(ch); err != nil {
+	if err := c.collectPrinterStatus(ctx, ch); err != nil {
 		errs = append(errs, fmt.Errorf("failed to collect printer status metrics: %w", err))
 	}
 
-	if err := c.collectPrinterJobStatus(ch); err != nil {
+	if err := c.collectPrinterJobStatus(ctx, ch); err != nil {
 		errs = append(errs, fmt.Errorf("failed to collect printer job status metrics: %w", err))
 	}
 
 	return errors.Join(errs...)
 }
 
-func (c *Collector) collectPrinterStatus(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectPrinterStatus(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var printers []wmiPrinter
-	if err := c.miSession.Query(&printers, mi.NamespaceRootCIMv2, c.miQueryPrinter); err != nil {
+	if err := c.miSession.QueryContext(ctx, &printers, mi.NamespaceRootCIMv2, c.miQueryPrinter); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -221,9 +234,9 @@ func (c *Collector) collectPrinterStatus(ch chan<- prometheus.Metric) error {
 	return nil
 }
 
-func (c *Collector) collectPrinterJobStatus(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectPrinterJobStatus(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var printJobs []wmiPrintJob
-	if err := c.miSession.Query(&printJobs, mi.NamespaceRootCIMv2, c.miQueryPrinterJobs); err != nil {
+	if err := c.miSession.QueryContext(ctx, &printJobs, mi.NamespaceRootCIMv2, c.miQueryPrinterJobs); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -12,7 +12,7 @@ func BenchmarkCollector(b *testing.B) {
 	// Whitelist is not set in testing context (kingpin flags not parsed), causing the collector to skip all printers.
 	printersInclude := ".+"
 	kingpin.CommandLine.GetArg("collector.printer.include").StringVar(&printersInclude)
-	testutils.FuncBenchmarkCollector(b, "printer", printer.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, "printer", printer.NewWithFlags, nil)
 }
 
 func TestCollector(t *testing.T) {
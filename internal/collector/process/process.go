@@ -3,6 +3,7 @@
 package process
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -25,9 +26,9 @@ import (
 const Name = "process"
 
 type Config struct {
-	ProcessInclude      *regexp.Regexp `yaml:"process_include"`
-	ProcessExclude      *regexp.Regexp `yaml:"process_exclude"`
-	EnableWorkerProcess bool           `yaml:"enable_iis_worker_process"` //nolint:tagliatelle
+	ProcessInclude      *regexp.Regexp `yaml:"process_include" flag:"include"`
+	ProcessExclude      *regexp.Regexp `yaml:"process_exclude" flag:"exclude"`
+	EnableWorkerProcess bool           `yaml:"enable_iis_worker_process" flag:"iis"` //nolint:tagliatelle
 }
 
 var ConfigDefaults = Config{
@@ -84,39 +85,57 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
 
 	var processExclude, processInclude string
 
+	var enableWorkerProcess bool
+
+	var processExcludeSet, processIncludeSet, enableWorkerProcessSet bool
+
 	app.Flag(
 		"collector.process.exclude",
 		"Regexp of processes to exclude. Process name must both match include and not match exclude to be included.",
-	).Default("").StringVar(&processExclude)
+	).Default("").IsSetByUser(&processExcludeSet).StringVar(&processExclude)
 
 	app.Flag(
 		"collector.process.include",
 		"Regexp of processes to include. Process name must both match include and not match exclude to be included.",
-	).Default(".+").StringVar(&processInclude)
+	).Default(".+").IsSetByUser(&processIncludeSet).StringVar(&processInclude)
 
 	app.Flag(
 		"collector.process.iis",
 		"Enable IIS worker process name queries. May cause the collector to leak memory.",
-	).Default(strconv.FormatBool(c.config.EnableWorkerProcess)).BoolVar(&c.config.EnableWorkerProcess)
+	).Default(strconv.FormatBool(ConfigDefaults.EnableWorkerProcess)).IsSetByUser(&enableWorkerProcessSet).BoolVar(&enableWorkerProcess)
 
 	app.Action(func(*kingpin.ParseContext) error {
+		c.config = *config
+
 		var err error
 
-		c.config.ProcessExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", processExclude))
-		if err != nil {
-			return fmt.Errorf("collector.process.exclude: %w", err)
+		if processExcludeSet {
+			c.config.ProcessExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", processExclude))
+			if err != nil {
+				return fmt.Errorf("collector.process.exclude: %w", err)
+			}
 		}
 
-		c.config.ProcessInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", processInclude))
-		if err != nil {
-			return fmt.Errorf("collector.process.include: %w", err)
+		if processIncludeSet {
+			c.config.ProcessInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", processInclude))
+			if err != nil {
+				return fmt.Errorf("collector.process.include: %w", err)
+			}
+		}
+
+		if enableWorkerProcessSet {
+			c.config.EnableWorkerProcess = enableWorkerProcess
 		}
 
 		return nil
@@ -314,13 +333,13 @@ type WorkerProcess struct {
 	ProcessId   uint64 `mi:"ProcessId"`
 }
 
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	if utils.PDHEnabled() {
-		return c.collectPDH(logger, ch)
+		return c.collectPDH(ctx, logger, ch)
 	}
 
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collect(ctx, logger, ch); err != nil {
+	if err := c.collect(ctx, scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting metrics",
 			slog.Any("err", err),
 		)
@@ -331,17 +350,17 @@ func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 	return nil
 }
 
-func (c *Collector) collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) collect(ctx context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	data := make([]perflibProcess, 0)
 
-	err := v1.UnmarshalObject(ctx.PerfObjects["Process"], &data, logger)
+	err := v1.UnmarshalObject(scrapeCtx.PerfObjects["Process"], &data, logger)
 	if err != nil {
 		return err
 	}
 
 	var workerProcesses []WorkerProcess
 	if c.config.EnableWorkerProcess {
-		if err := c.miSession.Query(&workerProcesses, mi.NamespaceRootWebAdministration, c.workerProcessMIQueryQuery); err != nil {
+		if err := c.miSession.QueryContext(ctx, &workerProcesses, mi.NamespaceRootWebAdministration, c.workerProcessMIQueryQuery); err != nil {
 			return fmt.Errorf("WMI query failed: %w", err)
 		}
 	}
@@ -534,8 +553,8 @@ func (c *Collector) collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 	return nil
 }
 
-func (c *Collector) collectPDH(logger *slog.Logger, ch chan<- prometheus.Metric) error {
-	perfData, err := c.perfDataCollector.Collect()
+func (c *Collector) collectPDH(ctx context.Context, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+	perfData, err := c.perfDataCollector.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect metrics: %w", err)
 	}
@@ -546,7 +565,7 @@ func (c *Collector) collectPDH(logger *slog.Logger, ch chan<- prometheus.Metric)
 
 	var workerProcesses []WorkerProcess
 	if c.config.EnableWorkerProcess {
-		if err := c.miSession.Query(&workerProcesses, mi.NamespaceRootWebAdministration, c.workerProcessMIQueryQuery); err != nil {
+		if err := c.miSession.QueryContext(ctx, &workerProcesses, mi.NamespaceRootWebAdministration, c.workerProcessMIQueryQuery); err != nil {
 			return fmt.Errorf("WMI query failed: %w", err)
 		}
 	}
@@ -13,7 +13,7 @@ func BenchmarkProcessCollector(b *testing.B) {
 	localProcessInclude := ".+"
 	kingpin.CommandLine.GetArg("collector.process.include").StringVar(&localProcessInclude)
 	// No context name required as collector source is WMI
-	testutils.FuncBenchmarkCollector(b, process.Name, process.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, process.Name, process.NewWithFlags, nil)
 }
 
 func TestCollector(t *testing.T) {
@@ -3,6 +3,7 @@
 package remote_fx
 
 import (
+	"context"
 	"log/slog"
 	"strings"
 
@@ -211,9 +212,9 @@ func (c *Collector) Build(*slog.Logger, *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collectRemoteFXNetworkCount(ctx, logger, ch); err != nil {
+	if err := c.collectRemoteFXNetworkCount(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting terminal services session count metrics",
 			slog.Any("err", err),
 		)
@@ -221,7 +222,7 @@ func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 		return err
 	}
 
-	if err := c.collectRemoteFXGraphicsCounters(ctx, logger, ch); err != nil {
+	if err := c.collectRemoteFXGraphicsCounters(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting terminal services session count metrics",
 			slog.Any("err", err),
 		)
@@ -3,6 +3,7 @@
 package scheduled_task
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -21,8 +22,8 @@ import (
 const Name = "scheduled_task"
 
 type Config struct {
-	TaskExclude *regexp.Regexp `yaml:"task_exclude"`
-	TaskInclude *regexp.Regexp `yaml:"task_include"`
+	TaskExclude *regexp.Regexp `yaml:"task_exclude" flag:"exclude"`
+	TaskInclude *regexp.Regexp `yaml:"task_include" flag:"include"`
 }
 
 var ConfigDefaults = Config{
@@ -33,8 +34,8 @@ var ConfigDefaults = Config{
 type Collector struct {
 	config Config
 
-	scheduledTasksReqCh chan struct{}
-	scheduledTasksCh    chan *scheduledTaskResults
+	// scheduledTasksReqCh receives the requests of the scrapes. Each request carries its own reply channel.
+	scheduledTasksReqCh chan chan<- *scheduledTaskResults
 
 	lastResult *prometheus.Desc
 	missedRuns *prometheus.Desc
@@ -96,34 +97,46 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
 
 	var taskExclude, taskInclude string
 
+	var taskExcludeSet, taskIncludeSet bool
+
 	app.Flag(
 		"collector.scheduled_task.exclude",
 		"Regexp of tasks to exclude. Task path must both match include and not match exclude to be included.",
-	).Default("").StringVar(&taskExclude)
+	).Default("").IsSetByUser(&taskExcludeSet).StringVar(&taskExclude)
 
 	app.Flag(
 		"collector.scheduled_task.include",
 		"Regexp of tasks to include. Task path must both match include and not match exclude to be included.",
-	).Default(".+").StringVar(&taskInclude)
+	).Default(".+").IsSetByUser(&taskIncludeSet).StringVar(&taskInclude)
 
 	app.Action(func(*kingpin.ParseContext) error {
+		c.config = *config
+
 		var err error
 
-		c.config.TaskExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", taskExclude))
-		if err != nil {
-			return fmt.Errorf("collector.scheduled_task.exclude: %w", err)
+		if taskExcludeSet {
+			c.config.TaskExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", taskExclude))
+			if err != nil {
+				return fmt.Errorf("collector.scheduled_task.exclude: %w", err)
+			}
 		}
 
-		c.config.TaskInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", taskInclude))
-		if err != nil {
-			return fmt.Errorf("collector.scheduled_task.include: %w", err)
+		if taskIncludeSet {
+			c.config.TaskInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", taskInclude))
+			if err != nil {
+				return fmt.Errorf("collector.scheduled_task.include: %w", err)
+			}
 		}
 
 		return nil
@@ -141,6 +154,10 @@ func (c *Collector) GetPerfCounter(_ *slog.Logger) ([]string, error) {
 }
 
 func (c *Collector) Close(_ *slog.Logger) error {
+	if c.scheduledTasksReqCh == nil {
+		return nil
+	}
+
 	close(c.scheduledTasksReqCh)
 
 	c.scheduledTasksReqCh = nil
@@ -150,8 +167,7 @@ func (c *Collector) Close(_ *slog.Logger) error {
 
 func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 	initErrCh := make(chan error)
-	c.scheduledTasksReqCh = make(chan struct{})
-	c.scheduledTasksCh = make(chan *scheduledTaskResults)
+	c.scheduledTasksReqCh = make(chan chan<- *scheduledTaskResults)
 
 	go c.initializeScheduleService(initErrCh)
 
@@ -183,9 +199,9 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 	return nil
 }
 
-func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, _ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collect(ch); err != nil {
+	if err := c.collect(ctx, ch); err != nil {
 		logger.Error("failed collecting user metrics",
 			slog.Any("err", err),
 		)
@@ -196,8 +212,8 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 	return nil
 }
 
-func (c *Collector) collect(ch chan<- prometheus.Metric) error {
-	scheduledTasks, err := c.getScheduledTasks()
+func (c *Collector) collect(ctx context.Context, ch chan<- prometheus.Metric) error {
+	scheduledTasks, err := c.getScheduledTasks(ctx)
 	if err != nil {
 		return fmt.Errorf("get scheduled tasks: %w", err)
 	}
@@ -251,24 +267,30 @@ func (c *Collector) collect(ch chan<- prometheus.Metric) error {
 	return nil
 }
 
-func (c *Collector) getScheduledTasks() ([]scheduledTask, error) {
-	c.scheduledTasksReqCh <- struct{}{}
+func (c *Collector) getScheduledTasks(ctx context.Context) ([]scheduledTask, error) {
+	// The reply channel is buffered, so the schedule service goroutine doesn't block on the reply to a timed-out request.
+	scheduledTasksCh := make(chan *scheduledTaskResults, 1)
 
-	scheduledTasks, ok := <-c.scheduledTasksCh
-
-	if !ok {
-		return []scheduledTask{}, nil
+	select {
+	case c.scheduledTasksReqCh <- scheduledTasksCh:
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
 
-	if scheduledTasks == nil {
-		return nil, errors.New("scheduled tasks channel is nil")
+	var scheduledTasks *scheduledTaskResults
+
+	select {
+	case scheduledTasks = <-scheduledTasksCh:
+	case <-ctx.Done():
+		// The OLE calls can't be interrupted. The result of the request is discarded.
+		return nil, ctx.Err()
 	}
 
 	if scheduledTasks.err != nil {
 		return nil, scheduledTasks.err
 	}
 
-	return scheduledTasks.scheduledTasks, scheduledTasks.err
+	return scheduledTasks.scheduledTasks, nil
 }
 
 func (c *Collector) initializeScheduleService(initErrCh chan<- error) {
@@ -321,17 +343,14 @@ func (c *Collector) initializeScheduleService(initErrCh chan<- error) {
 
 	close(initErrCh)
 
-	scheduledTasks := make([]scheduledTask, 0, 100)
-
-	for range c.scheduledTasksReqCh {
+	for scheduledTasksCh := range c.scheduledTasksReqCh {
 		func() {
-			// Clear the slice to avoid memory leaks
-			clear(scheduledTasks)
-			scheduledTasks = scheduledTasks[:0]
+			// Each request gets its own slice, because the scrape of a previous request may still read its result.
+			scheduledTasks := make([]scheduledTask, 0, 100)
 
 			res, err := oleutil.CallMethod(taskServiceObj, "GetFolder", `\`)
 			if err != nil {
-				c.scheduledTasksCh <- &scheduledTaskResults{err: err}
+				scheduledTasksCh <- &scheduledTaskResults{err: err}
 
 				return
 			}
@@ -341,13 +360,9 @@ func (c *Collector) initializeScheduleService(initErrCh chan<- error) {
 
 			err = fetchTasksRecursively(rootFolderObj, &scheduledTasks)
 
-			c.scheduledTasksCh <- &scheduledTaskResults{scheduledTasks: scheduledTasks, err: err}
+			scheduledTasksCh <- &scheduledTaskResults{scheduledTasks: scheduledTasks, err: err}
 		}()
 	}
-
-	close(c.scheduledTasksCh)
-
-	c.scheduledTasksCh = nil
 }
 
 func fetchTasksRecursively(folder *ole.IDispatch, scheduledTasks *[]scheduledTask) error {
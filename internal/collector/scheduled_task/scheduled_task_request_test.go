@@ -0,0 +1,48 @@
+//go:build windows
+
+package scheduled_task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetScheduledTasksAfterTimeout(t *testing.T) {
+	t.Parallel()
+
+	c := &Collector{scheduledTasksReqCh: make(chan chan<- *scheduledTaskResults)}
+
+	// The fake schedule service replies to the first request after it timed out.
+	timedOut := make(chan struct{})
+
+	go func() {
+		scheduledTasksCh := <-c.scheduledTasksReqCh
+
+		<-timedOut
+
+		scheduledTasksCh <- &scheduledTaskResults{scheduledTasks: []scheduledTask{{Path: `\stale`}}}
+
+		for scheduledTasksCh := range c.scheduledTasksReqCh {
+			scheduledTasksCh <- &scheduledTaskResults{scheduledTasks: []scheduledTask{{Path: `\current`}}}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.getScheduledTasks(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(timedOut)
+
+	// The next scrape receives the result of its own request, not the discarded one.
+	scheduledTasks, err := c.getScheduledTasks(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []scheduledTask{{Path: `\current`}}, scheduledTasks)
+
+	require.NoError(t, c.Close(nil))
+	require.NoError(t, c.Close(nil))
+}
@@ -8,5 +8,5 @@ import (
 )
 
 func BenchmarkCollector(b *testing.B) {
-	testutils.FuncBenchmarkCollector(b, scheduled_task.Name, scheduled_task.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, scheduled_task.Name, scheduled_task.NewWithFlags, nil)
 }
@@ -3,6 +3,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -21,8 +22,8 @@ import (
 const Name = "service"
 
 type Config struct {
-	ServiceInclude *regexp.Regexp `yaml:"service_include"`
-	ServiceExclude *regexp.Regexp `yaml:"service_exclude"`
+	ServiceInclude *regexp.Regexp `yaml:"service_include" flag:"include"`
+	ServiceExclude *regexp.Regexp `yaml:"service_exclude" flag:"exclude"`
 }
 
 var ConfigDefaults = Config{
@@ -62,34 +63,46 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
 
 	var serviceExclude, serviceInclude string
 
+	var serviceExcludeSet, serviceIncludeSet bool
+
 	app.Flag(
 		"collector.service.exclude",
 		"Regexp of service to exclude. Service name (not the display name!) must both match include and not match exclude to be included.",
-	).Default("").StringVar(&serviceExclude)
+	).Default("").IsSetByUser(&serviceExcludeSet).StringVar(&serviceExclude)
 
 	app.Flag(
 		"collector.service.include",
 		"Regexp of service to include. Process name (not the display name!) must both match include and not match exclude to be included.",
-	).Default(".+").StringVar(&serviceInclude)
+	).Default(".+").IsSetByUser(&serviceIncludeSet).StringVar(&serviceInclude)
 
 	app.Action(func(*kingpin.ParseContext) error {
+		c.config = *config
+
 		var err error
 
-		c.config.ServiceExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", serviceExclude))
-		if err != nil {
-			return fmt.Errorf("collector.process.exclude: %w", err)
+		if serviceExcludeSet {
+			c.config.ServiceExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", serviceExclude))
+			if err != nil {
+				return fmt.Errorf("collector.process.exclude: %w", err)
+			}
 		}
 
-		c.config.ServiceInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", serviceInclude))
-		if err != nil {
-			return fmt.Errorf("collector.process.include: %w", err)
+		if serviceIncludeSet {
+			c.config.ServiceInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", serviceInclude))
+			if err != nil {
+				return fmt.Errorf("collector.process.include: %w", err)
+			}
 		}
 
 		return nil
@@ -161,7 +174,7 @@ func (c *Collector) Close(logger *slog.Logger) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, _ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
 
 	if err := c.collect(logger, ch); err != nil {
@@ -8,5 +8,5 @@ import (
 )
 
 func BenchmarkCollector(b *testing.B) {
-	testutils.FuncBenchmarkCollector(b, service.Name, service.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, service.Name, service.NewWithFlags, nil)
 }
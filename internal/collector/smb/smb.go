@@ -3,6 +3,7 @@
 package smb
 
 import (
+	"context"
 	"log/slog"
 	"strings"
 
@@ -74,9 +75,9 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 }
 
 // Collect collects smb metrics and sends them to prometheus.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collectServerShares(ctx, logger, ch); err != nil {
+	if err := c.collectServerShares(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed to collect server share metrics",
 			slog.Any("err", err),
 		)
@@ -3,6 +3,7 @@
 package smbclient
 
 import (
+	"context"
 	"log/slog"
 	"strings"
 
@@ -179,9 +180,9 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 }
 
 // Collect collects smb client metrics and sends them to prometheus.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collectClientShares(ctx, logger, ch); err != nil {
+	if err := c.collectClientShares(scrapeCtx, logger, ch); err != nil {
 		logger.Error("Error in ClientShares",
 			slog.Any("err", err),
 		)
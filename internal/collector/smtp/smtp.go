@@ -3,6 +3,7 @@
 package smtp
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"regexp"
@@ -17,8 +18,8 @@ import (
 const Name = "smtp"
 
 type Config struct {
-	ServerInclude *regexp.Regexp `yaml:"server_include"`
-	ServerExclude *regexp.Regexp `yaml:"server_exclude"`
+	ServerInclude *regexp.Regexp `yaml:"server_include" flag:"server-include"`
+	ServerExclude *regexp.Regexp `yaml:"server_exclude" flag:"server-exclude"`
 }
 
 var ConfigDefaults = Config{
@@ -93,34 +94,46 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
 
 	var serverExclude, serverInclude string
 
+	var serverExcludeSet, serverIncludeSet bool
+
 	app.Flag(
 		"collector.smtp.server-exclude",
 		"Regexp of virtual servers to exclude. Server name must both match include and not match exclude to be included.",
-	).Default("").StringVar(&serverExclude)
+	).Default("").IsSetByUser(&serverExcludeSet).StringVar(&serverExclude)
 
 	app.Flag(
 		"collector.smtp.server-include",
 		"Regexp of virtual servers to include. Server name must both match include and not match exclude to be included.",
-	).Default(".+").StringVar(&serverInclude)
+	).Default(".+").IsSetByUser(&serverIncludeSet).StringVar(&serverInclude)
 
 	app.Action(func(*kingpin.ParseContext) error {
+		c.config = *config
+
 		var err error
 
-		c.config.ServerExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", serverExclude))
-		if err != nil {
-			return fmt.Errorf("collector.smtp.server-exclude: %w", err)
+		if serverExcludeSet {
+			c.config.ServerExclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", serverExclude))
+			if err != nil {
+				return fmt.Errorf("collector.smtp.server-exclude: %w", err)
+			}
 		}
 
-		c.config.ServerInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", serverInclude))
-		if err != nil {
-			return fmt.Errorf("collector.smtp.server-include: %w", err)
+		if serverIncludeSet {
+			c.config.ServerInclude, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", serverInclude))
+			if err != nil {
+				return fmt.Errorf("collector.smtp.server-include: %w", err)
+			}
 		}
 
 		return nil
@@ -404,9 +417,9 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collect(ctx, logger, ch); err != nil {
+	if err := c.collect(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting smtp metrics",
 			slog.Any("err", err),
 		)
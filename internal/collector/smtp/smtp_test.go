@@ -8,5 +8,5 @@ import (
 )
 
 func BenchmarkCollector(b *testing.B) {
-	testutils.FuncBenchmarkCollector(b, smtp.Name, smtp.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, smtp.Name, smtp.NewWithFlags, nil)
 }
@@ -3,6 +3,7 @@
 package system
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 
@@ -117,9 +118,9 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collect(ctx, logger, ch); err != nil {
+	if err := c.collect(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting system metrics",
 			slog.Any("err", err),
 		)
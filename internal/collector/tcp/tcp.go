@@ -3,6 +3,7 @@
 package tcp
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"slices"
@@ -21,7 +22,7 @@ import (
 const Name = "tcp"
 
 type Config struct {
-	CollectorsEnabled []string `yaml:"collectors_enabled"`
+	CollectorsEnabled []string `yaml:"collectors_enabled" flag:"enabled"`
 }
 
 var ConfigDefaults = Config{
@@ -66,21 +67,30 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
-	c.config.CollectorsEnabled = make([]string, 0)
 
 	var collectorsEnabled string
 
+	var collectorsEnabledSet bool
+
 	app.Flag(
 		"collector.tcp.enabled",
 		"Comma-separated list of collectors to use. Defaults to all, if not specified.",
-	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).StringVar(&collectorsEnabled)
+	).Default(strings.Join(ConfigDefaults.CollectorsEnabled, ",")).IsSetByUser(&collectorsEnabledSet).StringVar(&collectorsEnabled)
 
 	app.Action(func(*kingpin.ParseContext) error {
-		c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+		c.config = *config
+
+		if collectorsEnabledSet {
+			c.config.CollectorsEnabled = strings.Split(collectorsEnabled, ",")
+		}
 
 		return nil
 	})
@@ -190,11 +200,11 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, _ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
 
 	if slices.Contains(c.config.CollectorsEnabled, "metrics") {
-		if err := c.collect(ch); err != nil {
+		if err := c.collect(ctx, ch); err != nil {
 			logger.Error("failed collecting tcp metrics",
 				slog.Any("err", err),
 			)
@@ -216,15 +226,15 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 	return nil
 }
 
-func (c *Collector) collect(ch chan<- prometheus.Metric) error {
-	data, err := c.perfDataCollector4.Collect()
+func (c *Collector) collect(ctx context.Context, ch chan<- prometheus.Metric) error {
+	data, err := c.perfDataCollector4.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect TCPv4 metrics: %w", err)
 	}
 
 	c.writeTCPCounters(ch, data[perftypes.EmptyInstance], []string{"ipv4"})
 
-	data, err = c.perfDataCollector6.Collect()
+	data, err = c.perfDataCollector6.Collect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to collect TCPv6 metrics: %w", err)
 	}
@@ -8,7 +8,7 @@ import (
 )
 
 func BenchmarkCollector(b *testing.B) {
-	testutils.FuncBenchmarkCollector(b, tcp.Name, tcp.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, tcp.Name, tcp.NewWithFlags, nil)
 }
 
 func TestCollector(t *testing.T) {
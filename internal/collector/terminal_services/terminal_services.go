@@ -3,6 +3,7 @@
 package terminal_services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -225,7 +226,7 @@ func (c *Collector) Build(logger *slog.Logger, miSession *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
 	if err := c.collectWTSSessions(logger, ch); err != nil {
 		logger.Error("failed collecting terminal services session infos",
@@ -235,7 +236,7 @@ func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 		return err
 	}
 
-	if err := c.collectTSSessionCounters(ctx, logger, ch); err != nil {
+	if err := c.collectTSSessionCounters(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting terminal services session count metrics",
 			slog.Any("err", err),
 		)
@@ -245,7 +246,7 @@ func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 
 	// only collect CollectionBrokerPerformance if host is a Connection Broker
 	if c.connectionBrokerEnabled {
-		if err := c.collectCollectionBrokerPerformanceCounter(ctx, logger, ch); err != nil {
+		if err := c.collectCollectionBrokerPerformanceCounter(scrapeCtx, logger, ch); err != nil {
 			logger.Error("failed collecting Connection Broker performance metrics",
 				slog.Any("err", err),
 			)
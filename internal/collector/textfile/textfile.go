@@ -16,6 +16,7 @@
 package textfile
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -39,7 +40,7 @@ import (
 const Name = "textfile"
 
 type Config struct {
-	TextFileDirectories []string `yaml:"text_file_directories"`
+	TextFileDirectories []string `yaml:"text_file_directories" flag:"directories"`
 }
 
 var ConfigDefaults = Config{
@@ -71,20 +72,30 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
 
 	var textFileDirectories string
 
+	var textFileDirectoriesSet bool
+
 	app.Flag(
 		"collector.textfile.directories",
 		"Directory or Directories to read text files with metrics from.",
-	).Default(strings.Join(ConfigDefaults.TextFileDirectories, ",")).StringVar(&textFileDirectories)
+	).Default(strings.Join(ConfigDefaults.TextFileDirectories, ",")).IsSetByUser(&textFileDirectoriesSet).StringVar(&textFileDirectories)
 
 	app.Action(func(*kingpin.ParseContext) error {
-		c.config.TextFileDirectories = strings.Split(textFileDirectories, ",")
+		c.config = *config
+
+		if textFileDirectoriesSet {
+			c.config.TextFileDirectories = strings.Split(textFileDirectories, ",")
+		}
 
 		return nil
 	})
@@ -308,7 +319,7 @@ func (cr carriageReturnFilteringReader) Read(p []byte) (int, error) {
 }
 
 // Collect implements the Collector interface.
-func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, _ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
 	errorMetric := 0.0
 	mTimes := map[string]time.Time{}
@@ -1,6 +1,7 @@
 package textfile_test
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
@@ -52,7 +53,7 @@ func TestMultipleDirectories(t *testing.T) {
 		}
 	}()
 
-	err = textFileCollector.Collect(scrapeContext, logger, metrics)
+	err = textFileCollector.Collect(context.Background(), scrapeContext, logger, metrics)
 	if err != nil {
 		t.Errorf("Unexpected error %s", err)
 	}
@@ -98,7 +99,7 @@ func TestDuplicateFileName(t *testing.T) {
 		}
 	}()
 
-	err = textFileCollector.Collect(scrapeContext, logger, metrics)
+	err = textFileCollector.Collect(context.Background(), scrapeContext, logger, metrics)
 	if err != nil {
 		t.Errorf("Unexpected error %s", err)
 	}
@@ -3,6 +3,7 @@
 package time
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"time"
@@ -119,7 +120,7 @@ func (c *Collector) Build(_ *slog.Logger, _ *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
 
 	errs := make([]error, 0, 2)
@@ -132,7 +133,7 @@ func (c *Collector) Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch ch
 		errs = append(errs, err)
 	}
 
-	if err := c.collectNTP(ctx, logger, ch); err != nil {
+	if err := c.collectNTP(scrapeCtx, logger, ch); err != nil {
 		logger.Error("failed collecting time ntp metrics",
 			slog.Any("err", err),
 		)
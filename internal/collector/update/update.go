@@ -3,6 +3,7 @@
 package update
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -23,13 +24,13 @@ import (
 const Name = "update"
 
 type Config struct {
-	online         bool          `yaml:"online"`
-	scrapeInterval time.Duration `yaml:"scrape_interval"`
+	Online         bool          `yaml:"online"`
+	ScrapeInterval time.Duration `yaml:"scrape_interval" flag:"scrape-interval"`
 }
 
 var ConfigDefaults = Config{
-	online:         false,
-	scrapeInterval: 6 * time.Hour,
+	Online:         false,
+	ScrapeInterval: 6 * time.Hour,
 }
 
 var ErrNoUpdates = errors.New("no updates available")
@@ -58,20 +59,44 @@ func New(config *Config) *Collector {
 	return c
 }
 
-func NewWithFlags(app *kingpin.Application) *Collector {
+func NewWithFlags(app *kingpin.Application, config *Config) *Collector {
+	if config == nil {
+		config = &ConfigDefaults
+	}
+
 	c := &Collector{
-		config: ConfigDefaults,
+		config: *config,
 	}
 
+	var online bool
+
+	var scrapeInterval time.Duration
+
+	var onlineSet, scrapeIntervalSet bool
+
 	app.Flag(
 		"collector.updates.online",
 		"Whether to search for updates online.",
-	).Default(strconv.FormatBool(ConfigDefaults.online)).BoolVar(&c.config.online)
+	).Default(strconv.FormatBool(ConfigDefaults.Online)).IsSetByUser(&onlineSet).BoolVar(&online)
 
 	app.Flag(
 		"collector.updates.scrape-interval",
 		"Define the interval of scraping Windows Update information.",
-	).Default(ConfigDefaults.scrapeInterval.String()).DurationVar(&c.config.scrapeInterval)
+	).Default(ConfigDefaults.ScrapeInterval.String()).IsSetByUser(&scrapeIntervalSet).DurationVar(&scrapeInterval)
+
+	app.Action(func(*kingpin.ParseContext) error {
+		c.config = *config
+
+		if onlineSet {
+			c.config.Online = online
+		}
+
+		if scrapeIntervalSet {
+			c.config.ScrapeInterval = scrapeInterval
+		}
+
+		return nil
+	})
 
 	return c
 }
@@ -86,7 +111,7 @@ func (c *Collector) Build(logger *slog.Logger, _ *mi.Session) error {
 	logger.Info("update collector is in an experimental state! The configuration and metrics may change in future. Please report any issues.")
 
 	initErrCh := make(chan error, 1)
-	go c.scheduleUpdateStatus(logger, initErrCh, c.config.online)
+	go c.scheduleUpdateStatus(logger, initErrCh, c.config.Online)
 
 	if err := <-initErrCh; err != nil {
 		return fmt.Errorf("failed to initialize Windows Update collector: %w", err)
@@ -122,7 +147,7 @@ func (c *Collector) GetPerfCounter(_ *slog.Logger) ([]string, error) {
 	return []string{}, nil
 }
 
-func (c *Collector) Collect(_ *types.ScrapeContext, _ *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(_ context.Context, _ *types.ScrapeContext, _ *slog.Logger, ch chan<- prometheus.Metric) error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -244,7 +269,7 @@ func (c *Collector) scheduleUpdateStatus(logger *slog.Logger, initErrCh chan<- e
 		c.metricsBuf = metricsBuf
 		c.mu.Unlock()
 
-		time.Sleep(c.config.scrapeInterval)
+		time.Sleep(c.config.ScrapeInterval)
 	}
 }
 
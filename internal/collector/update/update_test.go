@@ -8,5 +8,5 @@ import (
 )
 
 func BenchmarkCollector(b *testing.B) {
-	testutils.FuncBenchmarkCollector(b, "printer", update.NewWithFlags)
+	testutils.FuncBenchmarkCollectorWithConfig(b, "printer", update.NewWithFlags, nil)
 }
@@ -3,6 +3,7 @@
 package vmware
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -218,9 +219,9 @@ func (c *Collector) Build(_ *slog.Logger, miSession *mi.Session) error {
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
-func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+func (c *Collector) Collect(ctx context.Context, _ *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
 	logger = logger.With(slog.String("collector", Name))
-	if err := c.collectMem(ch); err != nil {
+	if err := c.collectMem(ctx, ch); err != nil {
 		logger.Error("failed collecting vmware memory metrics",
 			slog.Any("err", err),
 		)
@@ -228,7 +229,7 @@ func (c *Collector) Collect(_ *types.ScrapeContext, logger *slog.Logger, ch chan
 		return err
 	}
 
-	if err := c.collectCpu(ch); err != nil {
+	if err := c.collectCpu(ctx, ch); err != nil {
 		logger.Error("failed collecting vmware cpu metrics",
 			slog.Any("err", err),
 		)
@@ -264,9 +265,9 @@ type Win32_PerfRawData_vmGuestLib_VCPU struct {
 	HostProcessorSpeedMHz uint64 `mi:"HostProcessorSpeedMHz"`
 }
 
-func (c *Collector) collectMem(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectMem(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_vmGuestLib_VMem
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, c.miQueryMem); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, c.miQueryMem); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
@@ -354,9 +355,9 @@ func mbToBytes(mb uint64) float64 {
 	return float64(mb * 1024 * 1024)
 }
 
-func (c *Collector) collectCpu(ch chan<- prometheus.Metric) error {
+func (c *Collector) collectCpu(ctx context.Context, ch chan<- prometheus.Metric) error {
 	var dst []Win32_PerfRawData_vmGuestLib_VCPU
-	if err := c.miSession.Query(&dst, mi.NamespaceRootCIMv2, c.miQueryCPU); err != nil {
+	if err := c.miSession.QueryContext(ctx, &dst, mi.NamespaceRootCIMv2, c.miQueryCPU); err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
 
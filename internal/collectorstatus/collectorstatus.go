@@ -0,0 +1,126 @@
+// Package collectorstatus records the outcome of the runs of a collector, so that the last error is available
+// without access to the log of windows_exporter.
+package collectorstatus
+
+import (
+	"sync"
+	"time"
+)
+
+// HistorySize is the number of failed runs kept per collector.
+const HistorySize = 10
+
+type Outcome string
+
+const (
+	Success Outcome = "success"
+	Failure Outcome = "failure"
+	Timeout Outcome = "timeout"
+)
+
+// Run is the outcome of a single run of a collector.
+type Run struct {
+	Time            time.Time `json:"time"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Metrics         int       `json:"metrics"`
+	Outcome         Outcome   `json:"outcome"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// Status is the recorded state of a collector.
+type Status struct {
+	Name string `json:"name"`
+	// Available is false, if the build of the collector failed and is retried in the background.
+	Available  bool   `json:"available"`
+	BuildError string `json:"build_error,omitempty"`
+	// CircuitBreaker is the state of the circuit breaker of the collector, if the circuit breaker is enabled.
+	CircuitBreaker string `json:"circuit_breaker,omitempty"`
+
+	Runs                uint64 `json:"runs"`
+	Failures            uint64 `json:"failures"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	LastRun             *Run   `json:"last_run"`
+	// FailureHistory holds the last failed runs, the most recent first.
+	FailureHistory []Run `json:"failure_history"`
+}
+
+// Tracker records the runs of a collector. It is safe for concurrent use.
+type Tracker struct {
+	mu                  sync.Mutex
+	runs                uint64
+	failures            uint64
+	consecutiveFailures int
+	lastRun             *Run
+	buildError          string
+	// history is a ring buffer of the last failed runs. next is the index of the next failed run.
+	history []Run
+	next    int
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{history: make([]Run, 0, HistorySize)}
+}
+
+// Record records a run of the collector.
+func (t *Tracker) Record(run Run) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.runs++
+	t.lastRun = &run
+
+	if run.Outcome == Success {
+		t.consecutiveFailures = 0
+
+		return
+	}
+
+	t.failures++
+	t.consecutiveFailures++
+
+	if len(t.history) < HistorySize {
+		t.history = append(t.history, run)
+	} else {
+		t.history[t.next] = run
+	}
+
+	t.next = (t.next + 1) % HistorySize
+}
+
+// SetBuildError records the error of the last build of the collector. nil clears the error.
+func (t *Tracker) SetBuildError(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err == nil {
+		t.buildError = ""
+	} else {
+		t.buildError = err.Error()
+	}
+}
+
+// Status returns the recorded state of the collector. Available and CircuitBreaker are left to the caller.
+func (t *Tracker) Status(name string) Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := Status{
+		Name:                name,
+		BuildError:          t.buildError,
+		Runs:                t.runs,
+		Failures:            t.failures,
+		ConsecutiveFailures: t.consecutiveFailures,
+		FailureHistory:      make([]Run, 0, len(t.history)),
+	}
+
+	if t.lastRun != nil {
+		lastRun := *t.lastRun
+		status.LastRun = &lastRun
+	}
+
+	for i := range len(t.history) {
+		status.FailureHistory = append(status.FailureHistory, t.history[(t.next-1-i+HistorySize)%HistorySize])
+	}
+
+	return status
+}
@@ -0,0 +1,68 @@
+package collectorstatus
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewTracker()
+
+	status := tracker.Status("cpu")
+	require.Equal(t, "cpu", status.Name)
+	require.Nil(t, status.LastRun)
+	require.Empty(t, status.FailureHistory)
+
+	start := time.Unix(1700000000, 0)
+
+	for i := range HistorySize + 2 {
+		tracker.Record(Run{Time: start.Add(time.Duration(i) * time.Second), Outcome: Failure, Error: strconv.Itoa(i)})
+	}
+
+	status = tracker.Status("cpu")
+	require.Equal(t, uint64(HistorySize+2), status.Runs)
+	require.Equal(t, uint64(HistorySize+2), status.Failures)
+	require.Equal(t, HistorySize+2, status.ConsecutiveFailures)
+	require.Len(t, status.FailureHistory, HistorySize)
+	require.Equal(t, strconv.Itoa(HistorySize+1), status.FailureHistory[0].Error)
+	require.Equal(t, "2", status.FailureHistory[HistorySize-1].Error)
+
+	tracker.Record(Run{Time: start.Add(time.Minute), Outcome: Success, Metrics: 12})
+
+	status = tracker.Status("cpu")
+	require.Equal(t, 0, status.ConsecutiveFailures)
+	require.Equal(t, uint64(HistorySize+2), status.Failures)
+	require.Equal(t, Success, status.LastRun.Outcome)
+	require.Equal(t, 12, status.LastRun.Metrics)
+	require.Len(t, status.FailureHistory, HistorySize)
+}
+
+func TestTrackerHistoryNotFull(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewTracker()
+	tracker.Record(Run{Outcome: Timeout, Error: "first"})
+	tracker.Record(Run{Outcome: Success})
+	tracker.Record(Run{Outcome: Failure, Error: "second"})
+
+	status := tracker.Status("os")
+	require.Equal(t, 1, status.ConsecutiveFailures)
+	require.Equal(t, []Run{{Outcome: Failure, Error: "second"}, {Outcome: Timeout, Error: "first"}}, status.FailureHistory)
+}
+
+func TestTrackerBuildError(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewTracker()
+	tracker.SetBuildError(errors.New("access denied"))
+	require.Equal(t, "access denied", tracker.Status("ad").BuildError)
+
+	tracker.SetBuildError(nil)
+	require.Empty(t, tracker.Status("ad").BuildError)
+}
@@ -14,13 +14,10 @@
 package config
 
 import (
-	"context"
-	"crypto/tls"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/alecthomas/kingpin/v2"
@@ -32,45 +29,56 @@ type getFlagger interface {
 }
 
 // Resolver represents a configuration file resolver for kingpin.
+//
+// Nested keys of the configuration file, like log.level, set the defaults of the flags with the same name.
+// Structured values, like the configuration of the collectors, are decoded by Unmarshal.
 type Resolver struct {
-	flags map[string]string
+	// root is the top-level mapping of the merged configuration files.
+	root *yaml.Node
+	// files are the names of the merged configuration files in the order they are merged.
+	files []string
+	// origins maps the nodes of root to the name of their file.
+	origins map[*yaml.Node]string
+	// remote loads the files of URLs.
+	remote *Remote
 }
 
-// NewResolver returns a Resolver structure.
-func NewResolver(file string, logger *slog.Logger, insecureSkipVerify bool) (*Resolver, error) {
-	flags := map[string]string{}
-
-	var fileBytes []byte
+// NewResolver returns a Resolver structure. file is a file, a URL, a directory or a glob pattern. See loader for
+// the merge of multiple configuration files. remote loads the files of URLs, it may be nil without URLs.
+func NewResolver(file string, logger *slog.Logger, remote *Remote) (*Resolver, error) {
+	if remote == nil {
+		var err error
 
-	var err error
-	if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
-		fileBytes, err = readFromURL(file, logger, insecureSkipVerify)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		fileBytes, err = readFromFile(file, logger)
-		if err != nil {
+		if remote, err = NewRemote(logger, RemoteOptions{}); err != nil {
 			return nil, err
 		}
 	}
 
-	var rawValues map[string]interface{}
+	l := newLoader(logger, remote, false)
 
-	err = yaml.Unmarshal(fileBytes, &rawValues)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal configuration file: %w", err)
+	if err := l.load(file, true); err != nil {
+		return nil, err
 	}
 
-	// Flatten nested YAML values
-	flattenedValues := flatten(rawValues)
-	for k, v := range flattenedValues {
-		if _, ok := flags[k]; !ok {
-			flags[k] = v
-		}
-	}
+	return &Resolver{root: l.root, files: l.files, origins: l.origins, remote: remote}, nil
+}
+
+// SaveCache stores the configuration files loaded from URLs as last known good configuration files.
+// It is called, after the configuration is applied successfully.
+func (c *Resolver) SaveCache() error {
+	return c.remote.SaveCache(slices.DeleteFunc(slices.Clone(c.files), func(file string) bool {
+		return !IsURL(file)
+	}))
+}
+
+// Files returns the names of the merged configuration files in the order they are merged.
+func (c *Resolver) Files() []string {
+	return c.files
+}
 
-	return &Resolver{flags: flags}, nil
+// IsURL returns true, if the configuration file is loaded from a URL.
+func IsURL(file string) bool {
+	return strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://")
 }
 
 func readFromFile(file string, logger *slog.Logger) ([]byte, error) {
@@ -88,46 +96,61 @@ func readFromFile(file string, logger *slog.Logger) ([]byte, error) {
 	return fileBytes, nil
 }
 
-func readFromURL(file string, logger *slog.Logger, insecureSkipVerify bool) ([]byte, error) {
-	logger.Info("Loading configuration file from URL: " + file)
-
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: insecureSkipVerify}, //nolint:gosec
-	}
-
-	if insecureSkipVerify {
-		logger.Warn("Loading configuration file with TLS verification disabled")
-	}
-
-	client := &http.Client{Transport: tr}
-
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, file, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read configuration file from URL: %w", err)
-	}
-
-	defer resp.Body.Close()
-
-	fileBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	return fileBytes, nil
-}
-
+// setDefault sets the defaults of the flags of v, which are named by the path of a value in the configuration file.
+// Lists set all values of repeatable flags and are joined by commas otherwise, mappings set flags like --telemetry.const-label.
+// Lists of objects are passed as YAML.
 func (c *Resolver) setDefault(v getFlagger) {
-	for name, value := range c.flags {
+	var walk func(name string, node *yaml.Node)
+
+	walk = func(name string, node *yaml.Node) {
 		f := v.GetFlag(name)
-		if f != nil {
-			f.Default(value)
+
+		switch node.Kind {
+		case yaml.ScalarNode:
+			if f != nil && node.ShortTag() != "!!null" {
+				f.Default(node.Value)
+			}
+		case yaml.SequenceNode:
+			if f == nil {
+				return
+			}
+
+			values, ok := scalarValues(node.Content)
+			if !ok {
+				// Lists of objects set flags, which take YAML or JSON, like --collector.perfdata.objects.
+				if value, err := yaml.Marshal(node); err == nil && !isCumulative(f) {
+					f.Default(string(value))
+				}
+
+				return
+			}
+
+			if isCumulative(f) {
+				f.Default(values...)
+			} else {
+				f.Default(strings.Join(values, ","))
+			}
+		case yaml.MappingNode:
+			if f != nil && isCumulative(f) {
+				values := make([]string, 0, len(node.Content)/2)
+
+				for i := 0; i < len(node.Content); i += 2 {
+					values = append(values, node.Content[i].Value+"="+node.Content[i+1].Value)
+				}
+
+				f.Default(values...)
+
+				return
+			}
+
+			for i := 0; i < len(node.Content); i += 2 {
+				walk(join(name, node.Content[i].Value), node.Content[i+1])
+			}
+		case yaml.DocumentNode, yaml.AliasNode:
 		}
 	}
+
+	walk("", c.root)
 }
 
 // Bind sets active flags with their default values from the configuration file(s).
@@ -146,3 +169,31 @@ func (c *Resolver) Bind(app *kingpin.Application, args []string) error {
 
 	return nil
 }
+
+// Unmarshal decodes the value of a top-level key of the configuration file into v.
+// It is used for structured values, which can't be expressed by flags.
+// v is left unchanged, if the key is not present.
+func (c *Resolver) Unmarshal(key string, v interface{}) error {
+	value := lookup(c.root, key)
+	if value == nil {
+		return nil
+	}
+
+	if err := Decode(value, v); err != nil {
+		return fmt.Errorf("failed to unmarshal configuration key %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// isCumulative returns true, if the flag can be repeated.
+func isCumulative(f *kingpin.FlagClause) bool {
+	return isCumulativeValue(f.Model().Value)
+}
+
+// isCumulativeValue returns true, if the value of a flag can be repeated.
+func isCumulativeValue(value kingpin.Value) bool {
+	v, ok := value.(interface{ IsCumulative() bool })
+
+	return ok && v.IsCumulative()
+}
@@ -0,0 +1,290 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/otlp"
+	"github.com/prometheus-community/windows_exporter/internal/remotewrite"
+	"github.com/prometheus/common/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestResolver(t *testing.T, content string) *Resolver {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+	resolver, err := NewResolver(file, slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+	require.NoError(t, err)
+
+	return resolver
+}
+
+func TestResolverBind(t *testing.T) {
+	t.Parallel()
+
+	resolver := newTestResolver(t, `
+collectors:
+  enabled: [cpu, net, service]
+log.level: debug
+web:
+  listen-address:
+    - ":9182"
+    - ":9183"
+telemetry:
+  const-label:
+    site: ams
+collector.cpu:
+  interval: 30s
+collector:
+  service:
+    include: windows_exporter
+`)
+
+	app := kingpin.New("test", "")
+	enabled := app.Flag("collectors.enabled", "").Default("cpu").String()
+	level := app.Flag("log.level", "").Default("info").String()
+	listenAddresses := app.Flag("web.listen-address", "").Default(":9182").Strings()
+	constLabels := app.Flag("telemetry.const-label", "").StringMap()
+	interval := app.Flag("collector.cpu.interval", "").Default("0s").Duration()
+	include := app.Flag("collector.service.include", "").Default(".+").String()
+
+	args := []string{"--log.level=warn"}
+
+	require.NoError(t, resolver.Bind(app, args))
+
+	_, err := app.Parse(args)
+	require.NoError(t, err)
+
+	require.Equal(t, "cpu,net,service", *enabled)
+	require.Equal(t, "warn", *level)
+	require.Equal(t, []string{":9182", ":9183"}, *listenAddresses)
+	require.Equal(t, map[string]string{"site": "ams"}, *constLabels)
+	require.Equal(t, "30s", interval.String())
+	require.Equal(t, "windows_exporter", *include)
+}
+
+func TestResolverUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	type object struct {
+		Object    string   `yaml:"object"`
+		Instances []string `yaml:"instances"`
+	}
+
+	type collectorConfig struct {
+		Include *regexp.Regexp `yaml:"include"`
+		Exclude *regexp.Regexp `yaml:"exclude"`
+		Enabled []string       `yaml:"enabled"`
+		Objects []object       `yaml:"objects"`
+		Where   *string        `yaml:"where"`
+		Labels  map[string]string
+	}
+
+	where := "Name='default'"
+	defaults := collectorConfig{
+		Include: regexp.MustCompile("^.+$"),
+		Exclude: regexp.MustCompile("^$"),
+		Enabled: []string{"a", "b"},
+		Where:   &where,
+		Labels:  map[string]string{"a": "b"},
+	}
+
+	resolver := newTestResolver(t, `
+collector:
+  service:
+    include: windows_exporter|wuauserv
+    enabled: c, d
+    objects: '[{"object": "Processor", "instances": ["*"]}]'
+    where: "Name='other'"
+    labels:
+      c: d
+`)
+
+	decoded := struct {
+		Service collectorConfig `yaml:"service"`
+	}{defaults}
+	require.NoError(t, resolver.Unmarshal("collector", &decoded))
+
+	config := decoded.Service
+
+	require.True(t, config.Include.MatchString("wuauserv"))
+	require.False(t, config.Include.MatchString("windows_exporter_helper"))
+	require.Equal(t, "^$", config.Exclude.String())
+	require.Equal(t, []string{"c", "d"}, config.Enabled)
+	require.Equal(t, []object{{Object: "Processor", Instances: []string{"*"}}}, config.Objects)
+	require.Equal(t, "Name='other'", *config.Where)
+	require.Equal(t, map[string]string{"a": "b", "c": "d"}, config.Labels)
+
+	// The defaults are left unchanged.
+	require.Equal(t, "^.+$", defaults.Include.String())
+	require.Equal(t, "Name='default'", where)
+	require.Equal(t, map[string]string{"a": "b"}, defaults.Labels)
+
+	var missing []string
+
+	require.NoError(t, resolver.Unmarshal("metrics_endpoints", &missing))
+	require.Nil(t, missing)
+}
+
+func TestResolverUnmarshalFlagKeys(t *testing.T) {
+	t.Parallel()
+
+	type updateConfig struct {
+		Online         bool          `yaml:"online"`
+		ScrapeInterval time.Duration `yaml:"scrape_interval" flag:"scrape-interval"`
+	}
+
+	type collectorConfig struct {
+		Update updateConfig `yaml:"update" flag:"updates"`
+	}
+
+	// The keys of the flags, like collector.updates.scrape-interval, and the YAML keys are both decoded.
+	for _, content := range []string{
+		"collector.updates.online: true\ncollector.updates.scrape-interval: 1h\n",
+		"collector:\n  update:\n    online: true\n    scrape_interval: 1h\n",
+	} {
+		var decoded collectorConfig
+
+		require.NoError(t, newTestResolver(t, content).Unmarshal("collector", &decoded))
+		require.Equal(t, updateConfig{Online: true, ScrapeInterval: time.Hour}, decoded.Update)
+	}
+}
+
+func TestResolverValidate(t *testing.T) {
+	t.Parallel()
+
+	type endpoint struct {
+		Path       string   `yaml:"path"`
+		Collectors []string `yaml:"collectors"`
+	}
+
+	type serviceConfig struct {
+		Include *regexp.Regexp `yaml:"include"`
+	}
+
+	resolver := newTestResolver(t, `
+collectors:
+  enabled: cpu,foo
+colector.service.include: windows_exporter
+collector:
+  service:
+    include: "windows_exporter("
+    exclude: wuauserv
+log:
+  level: [debug]
+  format: {}
+metrics_endpoints:
+  - path: /metrics/cpu
+    colectors: [cpu]
+const_labels:
+  site: ams
+`)
+
+	app := kingpin.New("test", "")
+	app.Flag("collectors.enabled", "").String()
+	app.Flag("collector.service.include", "").String()
+	app.Flag("log.level", "").String()
+	app.Flag("log.format", "").String()
+
+	err := resolver.Validate(app, Schema{
+		Sections: map[string]interface{}{
+			"collector":         &struct{ Service serviceConfig }{},
+			"metrics_endpoints": &[]endpoint{},
+			"const_labels":      &map[string]string{},
+		},
+		Values: map[string]func(string) error{
+			"collectors.enabled": func(value string) error {
+				if value != "cpu" {
+					return errors.New("unknown collector " + value)
+				}
+
+				return nil
+			},
+		},
+	})
+	require.Error(t, err)
+
+	require.Equal(t, []string{
+		"config.yaml:3:12: invalid value of collectors.enabled: unknown collector foo",
+		"config.yaml:4:1: unknown key colector",
+		"config.yaml:7:14: invalid regular expression: error parsing regexp: missing closing ): `^(?:windows_exporter()$`",
+		"config.yaml:8:5: unknown key collector.service.exclude",
+		"config.yaml:11:11: the value of log.format must not be a mapping",
+		"config.yaml:14:5: unknown key metrics_endpoints.colectors",
+	}, strings.Split(strings.ReplaceAll(err.Error(), filepath.Dir(resolver.Files()[0])+string(filepath.Separator), ""), "\n"))
+}
+
+func TestEffective(t *testing.T) {
+	t.Parallel()
+
+	type secretConfig struct {
+		Password config.Secret `yaml:"password"`
+	}
+
+	resolver := newTestResolver(t, `
+log.level: debug
+push:
+  password: secret
+`)
+
+	app := kingpin.New("test", "")
+	app.Flag("log.level", "").Default("info").String()
+	app.Flag("web.listen-address", "").Default(":9182").Strings()
+
+	schema := Schema{Sections: map[string]interface{}{"push": &secretConfig{}}}
+
+	require.NoError(t, resolver.Bind(app, nil))
+
+	_, err := app.Parse(nil)
+	require.NoError(t, err)
+
+	effective, err := Effective(app, resolver, schema)
+	require.NoError(t, err)
+	require.Equal(t, `log.level: debug
+web.listen-address:
+    - :9182
+push:
+    password: <secret>
+`, string(effective))
+}
+
+func TestEffectiveHeaders(t *testing.T) {
+	t.Setenv("WINDOWS_EXPORTER_TEST_TOKEN", "s3cr3t-token")
+
+	resolver := newTestResolver(t, `
+remote_write:
+  endpoints:
+    - url: https://prometheus.example.com/api/v1/write
+      headers:
+        Authorization: Bearer ${WINDOWS_EXPORTER_TEST_TOKEN}
+otlp:
+  endpoint: https://otel.example.com:4318
+  headers:
+    api-key: ${WINDOWS_EXPORTER_TEST_TOKEN}
+`)
+
+	app := kingpin.New("test", "")
+
+	_, err := app.Parse(nil)
+	require.NoError(t, err)
+
+	effective, err := Effective(app, resolver, Schema{Sections: map[string]interface{}{
+		"remote_write": &remotewrite.Config{},
+		"otlp":         &otlp.Config{},
+	}})
+	require.NoError(t, err)
+	require.NotContains(t, string(effective), "s3cr3t-token")
+	require.Contains(t, string(effective), "Authorization: <secret>")
+	require.Contains(t, string(effective), "api-key: <secret>")
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"encoding"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	regexpType          = reflect.TypeOf((*regexp.Regexp)(nil))
+	unmarshalerType     = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	// obsoleteUnmarshalerType is the unmarshaler of yaml.v2, which is still implemented by the types of prometheus/common.
+	obsoleteUnmarshalerType = reflect.TypeOf((*interface {
+		UnmarshalYAML(unmarshal func(interface{}) error) error
+	})(nil)).Elem()
+)
+
+// Decode decodes value into v, which must be a pointer. Values, which are not present in value, are left unchanged.
+//
+// In addition to the decoding of yaml.v3, the values follow the conventions of the flags:
+//   - Regular expressions of type *regexp.Regexp have to match the whole string.
+//   - Lists of strings can be written as comma-separated string.
+//   - Other lists can be written as string, which contains YAML or JSON.
+//   - Fields with a flag tag can also be written with the key of the tag. See fieldByKey.
+//
+// Pointers and maps of v are copied before they are decoded, so values shared with v, like defaults, are not modified.
+func Decode(value *yaml.Node, v interface{}) error {
+	value = clone(value)
+
+	if err := normalize(value, reflect.ValueOf(v)); err != nil {
+		return err
+	}
+
+	return value.Decode(v)
+}
+
+// normalize rewrites node according to the conventions of Decode for the type of v.
+func normalize(node *yaml.Node, v reflect.Value) error {
+	if node.Kind == yaml.AliasNode || node.ShortTag() == "!!null" {
+		return nil
+	}
+
+	if v.Type() == regexpType {
+		if node.Kind == yaml.ScalarNode {
+			node.Value = "^(?:" + node.Value + ")$"
+		}
+
+		// The regular expression is allocated by the decoder.
+		if v.CanSet() {
+			v.Set(reflect.Zero(v.Type()))
+		}
+
+		return nil
+	}
+
+	// Types with their own decoding are left as they are.
+	if v.Kind() != reflect.Pointer && decodesItself(v.Type()) {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.CanSet() {
+			copied := reflect.New(v.Type().Elem())
+			if !v.IsNil() {
+				copied.Elem().Set(v.Elem())
+			}
+
+			v.Set(copied)
+		}
+
+		if v.IsNil() {
+			return normalize(node, reflect.New(v.Type().Elem()).Elem())
+		}
+
+		return normalize(node, v.Elem())
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		for i := 0; i < len(node.Content); i += 2 {
+			field, name, ok := fieldByKey(v, node.Content[i].Value)
+			if !ok {
+				continue
+			}
+
+			// Keys named after the flag are decoded like the key of the field.
+			node.Content[i].Value = name
+
+			if err := normalize(node.Content[i+1], field); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!str" {
+			if err := expandList(node, v.Type().Elem().Kind() == reflect.String); err != nil {
+				return err
+			}
+		}
+
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+
+		for _, item := range node.Content {
+			if err := normalize(item, reflect.New(v.Type().Elem()).Elem()); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		if v.CanSet() && !v.IsNil() {
+			copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+
+			iter := v.MapRange()
+			for iter.Next() {
+				copied.SetMapIndex(iter.Key(), iter.Value())
+			}
+
+			v.Set(copied)
+		}
+
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := normalize(node.Content[i], reflect.New(v.Type().Elem()).Elem()); err != nil {
+				return err
+			}
+		}
+	default:
+	}
+
+	return nil
+}
+
+// decodesItself returns true, if the values of type t are decoded by their own methods.
+func decodesItself(t reflect.Type) bool {
+	t = reflect.PointerTo(t)
+
+	return t.Implements(unmarshalerType) || t.Implements(textUnmarshalerType) || t.Implements(obsoleteUnmarshalerType)
+}
+
+// expandList replaces the string of node with the list it contains.
+func expandList(node *yaml.Node, commaSeparated bool) error {
+	if commaSeparated {
+		content := make([]*yaml.Node, 0)
+
+		for _, item := range strings.Split(node.Value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				content = append(content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item, Line: node.Line, Column: node.Column})
+			}
+		}
+
+		*node = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: content, Line: node.Line, Column: node.Column}
+
+		return nil
+	}
+
+	var document yaml.Node
+
+	if err := yaml.Unmarshal([]byte(node.Value), &document); err != nil {
+		return err
+	}
+
+	if len(document.Content) == 0 {
+		*node = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: node.Line, Column: node.Column}
+
+		return nil
+	}
+
+	line, column := node.Line, node.Column
+	*node = *document.Content[0]
+	node.Line, node.Column = line, column
+
+	return nil
+}
+
+// fieldByKey returns the field of the struct v, which is decoded from key, and the YAML key of the field.
+// Besides the YAML key, a field is decoded from the key of its flag tag, which follows the name of the flag of the field,
+// like diskdrive for collector.diskdrive.
+func fieldByKey(v reflect.Value, key string) (reflect.Value, string, bool) {
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+
+		switch {
+		case name == "-":
+			continue
+		case options == "inline" && field.Type.Kind() == reflect.Struct:
+			if inlined, inlinedName, ok := fieldByKey(v.Field(i), key); ok {
+				return inlined, inlinedName, true
+			}
+
+			continue
+		case name == "":
+			name = strings.ToLower(field.Name)
+		}
+
+		if name == key || field.Tag.Get("flag") == key {
+			return v.Field(i), name, true
+		}
+	}
+
+	return reflect.Value{}, "", false
+}
+
+// clone returns a deep copy of node.
+func clone(node *yaml.Node) *yaml.Node {
+	copied := *node
+	copied.Content = make([]*yaml.Node, len(node.Content))
+
+	for i, child := range node.Content {
+		copied.Content[i] = clone(child)
+	}
+
+	return &copied
+}
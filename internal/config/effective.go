@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Effective returns the effective configuration as YAML. It contains the values of all flags of app,
+// which must be parsed, followed by the sections of schema, which are present in the configuration file.
+// The flags are written with their full name, like log.level, and reflect the CLI args, the configuration file and the defaults.
+// The sections are decoded and encoded again, so values of the type config.Secret, like passwords and the values of headers,
+// are written as <secret>. resolver is nil without configuration file.
+func Effective(app *kingpin.Application, resolver *Resolver, schema Schema) ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+	flags := slices.Clone(app.Model().Flags)
+	slices.SortFunc(flags, func(a, b *kingpin.FlagModel) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	for _, f := range flags {
+		if f.Hidden || f.Name == "help" || f.Name == "version" {
+			continue
+		}
+
+		value, err := flagValue(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode flag %s: %w", f.Name, err)
+		}
+
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f.Name}, value)
+	}
+
+	if resolver != nil {
+		for i := 0; i < len(resolver.root.Content); i += 2 {
+			key := resolver.root.Content[i]
+
+			// The values of sections, which contain flags, like collector, are written as flags.
+			section, ok := schema.Sections[key.Value]
+			if !ok || hasPrefix(flags, key.Value) {
+				continue
+			}
+
+			decoded := reflect.New(reflect.TypeOf(section).Elem())
+			if err := Decode(resolver.root.Content[i+1], decoded.Interface()); err != nil {
+				return nil, fmt.Errorf("failed to decode configuration key %s: %w", key.Value, err)
+			}
+
+			value := &yaml.Node{}
+			if err := value.Encode(decoded.Interface()); err != nil {
+				return nil, fmt.Errorf("failed to encode configuration key %s: %w", key.Value, err)
+			}
+
+			root.Content = append(root.Content, scalarKey(key, key.Value), value)
+		}
+	}
+
+	return yaml.Marshal(root)
+}
+
+// flagValue returns the value of the flag f. Repeatable flags are returned as list or mapping.
+func flagValue(f *kingpin.FlagModel) (*yaml.Node, error) {
+	value := &yaml.Node{}
+
+	getter, ok := f.Value.(kingpin.Getter)
+	if !ok || !isCumulativeValue(f.Value) {
+		value.SetString(f.Value.String())
+
+		return value, nil
+	}
+
+	if err := value.Encode(getter.Get()); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// hasPrefix returns true, if name is the first part of the name of any of flags, like collector for collector.service.include.
+func hasPrefix(flags []*kingpin.FlagModel, name string) bool {
+	return slices.ContainsFunc(flags, func(f *kingpin.FlagModel) bool {
+		return strings.HasPrefix(f.Name, name+".")
+	})
+}
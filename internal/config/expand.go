@@ -0,0 +1,139 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// quotedStyles are the styles of scalars, which are strings regardless of their value.
+const quotedStyles = yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle | yaml.LiteralStyle | yaml.FoldedStyle
+
+// expandValues replaces the references in the values of node, see expandReferences. Keys are not expanded.
+// The type of an unquoted value is resolved from the expanded value, e.g. an expanded port is a number.
+// Relative paths of files are relative to dir.
+func expandValues(node *yaml.Node, dir string) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := expandValues(node.Content[i], dir); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode, yaml.DocumentNode:
+		for _, item := range node.Content {
+			if err := expandValues(item, dir); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		value, err := expandReferences(node.Value, dir)
+		if err != nil {
+			return newPositionError(node, err)
+		}
+
+		if value == node.Value {
+			return nil
+		}
+
+		node.Value = value
+
+		if node.Style&quotedStyles == 0 {
+			node.Tag = ""
+		}
+	case yaml.AliasNode:
+	}
+
+	return nil
+}
+
+// expandReferences replaces the references in value:
+//   - ${VAR} is replaced by the value of the environment variable VAR.
+//   - ${file:PATH} is replaced by the content of the file PATH without trailing line breaks.
+//   - ${VAR:-default} and ${file:PATH:-default} are replaced by default, if the variable is unset or empty
+//     or the file does not exist.
+//   - $${ is replaced by ${.
+//
+// An unset environment variable or a missing file without default is an error.
+func expandReferences(value, dir string) (string, error) {
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+
+	var expanded strings.Builder
+
+	for {
+		i := strings.Index(value, "${")
+		if i < 0 {
+			expanded.WriteString(value)
+
+			return expanded.String(), nil
+		}
+
+		// $${ escapes a reference.
+		if i > 0 && value[i-1] == '$' {
+			expanded.WriteString(value[:i-1] + "${")
+			value = value[i+2:]
+
+			continue
+		}
+
+		expanded.WriteString(value[:i])
+
+		end := strings.Index(value[i:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("missing closing } of reference in %q", value[i:])
+		}
+
+		resolved, err := resolveReference(value[i+2:i+end], dir)
+		if err != nil {
+			return "", err
+		}
+
+		expanded.WriteString(resolved)
+
+		value = value[i+end+1:]
+	}
+}
+
+// resolveReference returns the value of the reference ref without ${ and }.
+func resolveReference(ref, dir string) (string, error) {
+	ref, fallback, hasDefault := strings.Cut(ref, ":-")
+
+	if path, ok := strings.CutPrefix(ref, "file:"); ok {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if hasDefault && errors.Is(err, fs.ErrNotExist) {
+				return fallback, nil
+			}
+
+			return "", fmt.Errorf("failed to read file of reference: %w", err)
+		}
+
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+
+	if ref == "" {
+		return "", errors.New("reference without name of environment variable")
+	}
+
+	value, ok := os.LookupEnv(ref)
+
+	switch {
+	case hasDefault && value == "":
+		return fallback, nil
+	case !ok:
+		return "", fmt.Errorf("environment variable %s is not set", ref)
+	default:
+		return value, nil
+	}
+}
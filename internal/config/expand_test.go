@@ -0,0 +1,89 @@
+package config
+
+import (
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandReferences(t *testing.T) {
+	t.Setenv("WINDOWS_EXPORTER_TEST_SITE", "ams")
+	t.Setenv("WINDOWS_EXPORTER_TEST_EMPTY", "")
+
+	dir := writeFiles(t, map[string]string{
+		"token": "s3cr3t\r\n",
+	})
+
+	for _, tc := range []struct {
+		value    string
+		expected string
+		err      string
+	}{
+		{value: "no references", expected: "no references"},
+		{value: "pa$$word", expected: "pa$$word"},
+		{value: "site-${WINDOWS_EXPORTER_TEST_SITE}", expected: "site-ams"},
+		{value: "${WINDOWS_EXPORTER_TEST_EMPTY}", expected: ""},
+		{value: "${WINDOWS_EXPORTER_TEST_EMPTY:-fallback}", expected: "fallback"},
+		{value: "${WINDOWS_EXPORTER_TEST_UNSET:-fallback}", expected: "fallback"},
+		{value: "${WINDOWS_EXPORTER_TEST_UNSET}", err: "environment variable WINDOWS_EXPORTER_TEST_UNSET is not set"},
+		{value: "$${WINDOWS_EXPORTER_TEST_SITE}", expected: "${WINDOWS_EXPORTER_TEST_SITE}"},
+		{value: "Bearer ${file:token}", expected: "Bearer s3cr3t"},
+		{value: "${file:" + filepath.Join(dir, "token") + "}", expected: "s3cr3t"},
+		{value: "${file:missing:-none}", expected: "none"},
+		{value: "${file:missing}", err: "failed to read file of reference"},
+		{value: "${WINDOWS_EXPORTER_TEST_SITE", err: "missing closing }"},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			expanded, err := expandReferences(tc.value, dir)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, expanded)
+		})
+	}
+}
+
+func TestNewResolverExpand(t *testing.T) {
+	t.Setenv("WINDOWS_EXPORTER_TEST_PORT", "9183")
+	t.Setenv("WINDOWS_EXPORTER_TEST_ROLE", "iis")
+
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": `
+include: roles/${WINDOWS_EXPORTER_TEST_ROLE}.yaml
+push:
+  port: ${WINDOWS_EXPORTER_TEST_PORT}
+  name: "${WINDOWS_EXPORTER_TEST_PORT}"
+  "${WINDOWS_EXPORTER_TEST_ROLE}": key
+`,
+		"roles/iis.yaml": `
+push:
+  role: ${WINDOWS_EXPORTER_TEST_ROLE}
+`,
+	})
+
+	resolver, err := NewResolver(filepath.Join(dir, "config.yaml"), slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+	require.NoError(t, err)
+
+	var push struct {
+		Port int               `yaml:"port"`
+		Name string            `yaml:"name"`
+		Role string            `yaml:"role"`
+		Keys map[string]string `yaml:",inline"`
+	}
+
+	require.NoError(t, resolver.Unmarshal("push", &push))
+	require.Equal(t, 9183, push.Port)
+	require.Equal(t, "9183", push.Name)
+	require.Equal(t, "iis", push.Role)
+	require.Equal(t, map[string]string{"${WINDOWS_EXPORTER_TEST_ROLE}": "key"}, push.Keys)
+
+	_, err = NewResolver(writeFiles(t, map[string]string{"config.yaml": "log.level: ${WINDOWS_EXPORTER_TEST_UNSET}"}), slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+	require.ErrorContains(t, err, "config.yaml:1:12: environment variable WINDOWS_EXPORTER_TEST_UNSET is not set")
+}
@@ -0,0 +1,259 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeKey is the top-level key of a configuration file, which lists further configuration files.
+const includeKey = "include"
+
+// loader reads and merges configuration files.
+//
+// A configuration file can be a file, a URL, a directory or a glob pattern. A directory contains
+// the files with the extension .yaml or .yml. The files of a directory and a glob pattern are merged in
+// the lexical order of their names. The files listed by the include key of a file are merged after the file.
+// Relative paths of the include key are relative to the directory or URL of the file. Files of a URL can include URLs only.
+// Every file is merged once.
+// The references to environment variables and files in the values of local files are expanded, see expandReferences.
+type loader struct {
+	logger *slog.Logger
+	remote *Remote
+	// poll is true, if the files are loaded to detect changes. URLs are requested once without fallback to the cache.
+	poll bool
+
+	// root is the merged top-level mapping.
+	root *yaml.Node
+	// files are the names of the merged files in the order they are merged.
+	files []string
+	// origins maps the nodes of root to the name of their file.
+	origins map[*yaml.Node]string
+	// checksum covers the names and the content of the merged files.
+	checksum hash.Hash
+}
+
+func newLoader(logger *slog.Logger, remote *Remote, poll bool) *loader {
+	return &loader{
+		logger:   logger,
+		remote:   remote,
+		poll:     poll,
+		root:     &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"},
+		origins:  make(map[*yaml.Node]string),
+		checksum: sha256.New(),
+	}
+}
+
+// load merges the configuration files of pattern. If required is true, pattern has to match at least one file.
+func (l *loader) load(pattern string, required bool) error {
+	files, err := expandPattern(pattern)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 && required {
+		return fmt.Errorf("no configuration file matches %s", pattern)
+	}
+
+	for _, file := range files {
+		if err := l.loadFile(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadFile merges the configuration file and the files of its include key.
+func (l *loader) loadFile(file string) error {
+	if slices.Contains(l.files, file) {
+		return nil
+	}
+
+	l.files = append(l.files, file)
+
+	var (
+		fileBytes []byte
+		err       error
+	)
+
+	switch {
+	case IsURL(file) && l.poll:
+		fileBytes, err = l.remote.Get(context.Background(), file)
+	case IsURL(file):
+		fileBytes, err = l.remote.Load(context.Background(), file)
+	default:
+		fileBytes, err = readFromFile(file, l.logger)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	l.checksum.Write([]byte(file))
+	l.checksum.Write(fileBytes)
+
+	root, err := parse(fileBytes)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal configuration file %s: %w", file, err)
+	}
+
+	// Files of a URL are not expanded, so the server can't read environment variables and files of the host.
+	if !IsURL(file) {
+		if err = expandValues(root, filepath.Dir(file)); err != nil {
+			var positionError *PositionError
+			if errors.As(err, &positionError) {
+				positionError.File = file
+			}
+
+			return err
+		}
+	}
+
+	l.addOrigin(root, file)
+
+	includes, includeErr := removeIncludes(root)
+	if includeErr != nil {
+		includeErr.File = file
+
+		return includeErr
+	}
+
+	for i := 0; i < len(root.Content); i += 2 {
+		merge(l.root, root.Content[i], root.Content[i+1])
+	}
+
+	for _, include := range includes {
+		pattern, err := resolveInclude(file, include)
+		if err != nil {
+			return fmt.Errorf("invalid include %s of configuration file %s: %w", include, file, err)
+		}
+
+		// Local files are expanded, so a file of a URL could read environment variables and files of the host by including them.
+		if IsURL(file) && !IsURL(pattern) {
+			return fmt.Errorf("invalid include %s of configuration file %s: a configuration file loaded from a URL can only include URLs", include, file)
+		}
+
+		if err = l.load(pattern, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sum returns the checksum of the merged files.
+func (l *loader) sum() []byte {
+	return l.checksum.Sum(nil)
+}
+
+// addOrigin records file as the origin of node and its children.
+func (l *loader) addOrigin(node *yaml.Node, file string) {
+	l.origins[node] = file
+
+	for _, child := range node.Content {
+		l.addOrigin(child, file)
+	}
+}
+
+// removeIncludes removes the include key from root and returns its values. The value is a file or a list of files.
+func removeIncludes(root *yaml.Node) ([]string, *PositionError) {
+	for i := 0; i < len(root.Content); i += 2 {
+		if root.Content[i].Value != includeKey {
+			continue
+		}
+
+		value := root.Content[i+1]
+		root.Content = slices.Delete(root.Content, i, i+2)
+
+		switch value.Kind {
+		case yaml.ScalarNode:
+			return []string{value.Value}, nil
+		case yaml.SequenceNode:
+			if includes, ok := scalarValues(value.Content); ok {
+				return includes, nil
+			}
+		case yaml.DocumentNode, yaml.MappingNode, yaml.AliasNode:
+		}
+
+		return nil, newPositionError(value, errors.New("the value of include must be a file or a list of files"))
+	}
+
+	return nil, nil
+}
+
+// resolveInclude returns the include of file relative to the directory or URL of file.
+func resolveInclude(file, include string) (string, error) {
+	if IsURL(include) || filepath.IsAbs(include) {
+		return include, nil
+	}
+
+	if IsURL(file) {
+		base, err := url.Parse(file)
+		if err != nil {
+			return "", err
+		}
+
+		reference, err := url.Parse(filepath.ToSlash(include))
+		if err != nil {
+			return "", err
+		}
+
+		return base.ResolveReference(reference).String(), nil
+	}
+
+	return filepath.Join(filepath.Dir(file), include), nil
+}
+
+// expandPattern returns the files of a file, URL, directory or glob pattern.
+func expandPattern(pattern string) ([]string, error) {
+	if IsURL(pattern) {
+		return []string{pattern}, nil
+	}
+
+	if strings.ContainsAny(pattern, "*?[") {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %s: %w", pattern, err)
+		}
+
+		slices.Sort(files)
+
+		return files, nil
+	}
+
+	info, err := os.Stat(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file: %w", err)
+	}
+
+	if !info.IsDir() {
+		return []string{pattern}, nil
+	}
+
+	entries, err := os.ReadDir(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration directory: %w", err)
+	}
+
+	files := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		if ext := filepath.Ext(entry.Name()); !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, filepath.Join(pattern, entry.Name()))
+		}
+	}
+
+	// os.ReadDir returns the entries sorted by name.
+	return files, nil
+}
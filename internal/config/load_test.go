@@ -0,0 +1,101 @@
+package config
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o700))
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	}
+
+	return dir
+}
+
+func TestNewResolverMerge(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": `
+include:
+  - conf.d/*.yaml
+  - config.yaml
+collectors:
+  enabled: [cpu, memory]
+log.level: info
+const_labels:
+  site: ams
+`,
+		"conf.d/10-iis.yaml": `
+collectors.enabled: [iis, cpu]
+const_labels:
+  role: iis
+`,
+		"conf.d/20-debug.yaml": `
+log:
+  level: debug
+web.listen-address: null
+`,
+		"conf.d/readme.txt": `not a configuration file`,
+	})
+
+	resolver, err := NewResolver(filepath.Join(dir, "config.yaml"), slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		filepath.Join(dir, "config.yaml"),
+		filepath.Join(dir, "conf.d", "10-iis.yaml"),
+		filepath.Join(dir, "conf.d", "20-debug.yaml"),
+	}, resolver.Files())
+
+	app := kingpin.New("test", "")
+	enabled := app.Flag("collectors.enabled", "").String()
+	level := app.Flag("log.level", "").String()
+	listenAddresses := app.Flag("web.listen-address", "").Default(":9182").Strings()
+
+	require.NoError(t, resolver.Bind(app, nil))
+
+	_, err = app.Parse(nil)
+	require.NoError(t, err)
+
+	require.Equal(t, "cpu,memory,iis", *enabled)
+	require.Equal(t, "debug", *level)
+	require.Equal(t, []string{":9182"}, *listenAddresses)
+
+	var constLabels map[string]string
+
+	require.NoError(t, resolver.Unmarshal("const_labels", &constLabels))
+	require.Equal(t, map[string]string{"site": "ams", "role": "iis"}, constLabels)
+}
+
+func TestNewResolverDirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t, map[string]string{
+		"b.yml":  `log.level: debug`,
+		"a.yaml": `log.level: info`,
+		"c.txt":  `log.level: warn`,
+	})
+
+	for _, file := range []string{dir, filepath.Join(dir, "*.y*ml")} {
+		resolver, err := NewResolver(file, slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yml")}, resolver.Files())
+	}
+
+	_, err := NewResolver(filepath.Join(dir, "*.json"), slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+	require.ErrorContains(t, err, "no configuration file matches")
+}
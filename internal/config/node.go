@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parse parses a configuration file into its top-level mapping.
+// Top-level keys with dots, like collector.service.include, are expanded into nested mappings.
+func parse(fileBytes []byte) (*yaml.Node, error) {
+	var document yaml.Node
+
+	if err := yaml.Unmarshal(fileBytes, &document); err != nil {
+		return nil, err
+	}
+
+	// An empty file has no content.
+	if len(document.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.New("the configuration file must be a mapping of keys to values")
+	}
+
+	expanded := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: root.Line, Column: root.Column}
+
+	for i := 0; i < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		names := strings.Split(key.Value, ".")
+
+		// The value is wrapped into a mapping for every name except the last one.
+		for j := len(names) - 1; j > 0; j-- {
+			value = &yaml.Node{
+				Kind:    yaml.MappingNode,
+				Tag:     "!!map",
+				Line:    value.Line,
+				Column:  value.Column,
+				Content: []*yaml.Node{scalarKey(key, names[j]), value},
+			}
+		}
+
+		merge(expanded, scalarKey(key, names[0]), value)
+	}
+
+	return expanded, nil
+}
+
+// merge adds key and value to mapping. If the mapping already contains the key, the values are merged:
+//   - Mappings are merged key by key.
+//   - Lists are concatenated. Scalars, which are already part of the existing list, are skipped.
+//   - Otherwise, the value replaces the existing value. A null value removes the existing value.
+func merge(mapping *yaml.Node, key *yaml.Node, value *yaml.Node) {
+	for i := 0; i < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key.Value {
+			continue
+		}
+
+		existing := mapping.Content[i+1]
+
+		switch {
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			for j := 0; j < len(value.Content); j += 2 {
+				merge(existing, value.Content[j], value.Content[j+1])
+			}
+		case existing.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode:
+			for _, item := range value.Content {
+				if item.Kind != yaml.ScalarNode || !slices.ContainsFunc(existing.Content, func(existingItem *yaml.Node) bool {
+					return existingItem.Kind == yaml.ScalarNode && existingItem.Value == item.Value
+				}) {
+					existing.Content = append(existing.Content, item)
+				}
+			}
+		default:
+			mapping.Content[i+1] = value
+		}
+
+		return
+	}
+
+	mapping.Content = append(mapping.Content, key, value)
+}
+
+// lookup returns the value of key in mapping or nil, if the key is not present.
+func lookup(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// scalarKey returns a key with the name and the position of key.
+func scalarKey(key *yaml.Node, name string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name, Line: key.Line, Column: key.Column}
+}
+
+// scalarValues returns the values of nodes. The second return value is false, if any node is not a scalar.
+func scalarValues(nodes []*yaml.Node) ([]string, bool) {
+	values := make([]string, 0, len(nodes))
+
+	for _, node := range nodes {
+		if node.Kind != yaml.ScalarNode {
+			return nil, false
+		}
+
+		values = append(values, node.Value)
+	}
+
+	return values, true
+}
+
+// join joins the name of a parent key and a key.
+func join(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return parent + "." + name
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Interface guards.
+var (
+	_ http.Handler         = (*Reloader)(nil)
+	_ prometheus.Collector = (*Reloader)(nil)
+)
+
+// Reloader triggers reloads of the configuration and tracks their outcome.
+type Reloader struct {
+	logger   *slog.Logger
+	reloadFn func() error
+
+	// reloadMu serializes reloads.
+	reloadMu sync.Mutex
+
+	mu              sync.Mutex
+	lastSuccessful  bool
+	lastSuccessTime time.Time
+
+	lastReloadSuccessfulDesc       *prometheus.Desc
+	lastReloadSuccessTimestampDesc *prometheus.Desc
+}
+
+// NewReloader returns a Reloader which calls reloadFn on every reload.
+func NewReloader(logger *slog.Logger, reloadFn func() error) *Reloader {
+	return &Reloader{
+		logger:          logger,
+		reloadFn:        reloadFn,
+		lastSuccessful:  true,
+		lastSuccessTime: time.Now(),
+		lastReloadSuccessfulDesc: prometheus.NewDesc(
+			"windows_exporter_config_last_reload_successful",
+			"windows_exporter: Whether the last configuration reload attempt was successful.",
+			nil,
+			nil,
+		),
+		lastReloadSuccessTimestampDesc: prometheus.NewDesc(
+			"windows_exporter_config_last_reload_success_timestamp_seconds",
+			"windows_exporter: Timestamp of the last successful configuration reload.",
+			nil,
+			nil,
+		),
+	}
+}
+
+// Reload reloads the configuration. Concurrent calls are serialized.
+func (r *Reloader) Reload() error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	r.logger.Info("Reloading configuration")
+
+	err := r.reloadFn()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastSuccessful = err == nil
+
+	if err != nil {
+		r.logger.Error("Failed to reload configuration",
+			slog.Any("err", err),
+		)
+
+		return err
+	}
+
+	r.lastSuccessTime = time.Now()
+
+	r.logger.Info("Configuration reloaded")
+
+	return nil
+}
+
+// Watch reloads the configuration, if the content of the configuration files of file has changed.
+// file is a file, a URL, a directory or a glob pattern. Added and removed files and changed includes are detected as well.
+// URLs are requested by remote, which asks the server for changes by ETag or Last-Modified.
+// The files are checked at the given interval until ctx is canceled.
+func (r *Reloader) Watch(ctx context.Context, file string, remote *Remote, interval time.Duration) {
+	lastChecksum, err := fileChecksum(file, remote)
+	if err != nil {
+		r.logger.Warn("Failed to read configuration file for change detection",
+			slog.Any("err", err),
+		)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		checksum, err := fileChecksum(file, remote)
+		if err != nil {
+			r.logger.Warn("Failed to read configuration file for change detection",
+				slog.Any("err", err),
+			)
+
+			continue
+		}
+
+		if bytes.Equal(checksum, lastChecksum) {
+			continue
+		}
+
+		r.logger.Info("Configuration file has changed: " + file)
+
+		// A failed reload is retried only after the next change of the file.
+		lastChecksum = checksum
+
+		_ = r.Reload()
+	}
+}
+
+// fileChecksum returns the checksum of the configuration files of file.
+func fileChecksum(file string, remote *Remote) ([]byte, error) {
+	l := newLoader(slog.New(slog.NewTextHandler(io.Discard, nil)), remote, true)
+
+	if err := l.load(file, true); err != nil {
+		return nil, err
+	}
+
+	return l.sum(), nil
+}
+
+// ServeHTTP triggers a reload of the configuration.
+func (r *Reloader) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	if err := r.Reload(); err != nil {
+		http.Error(w, fmt.Sprintf("failed to reload config: %s", err), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *Reloader) Describe(ch chan<- *prometheus.Desc) {
+	ch <- r.lastReloadSuccessfulDesc
+	ch <- r.lastReloadSuccessTimestampDesc
+}
+
+func (r *Reloader) Collect(ch chan<- prometheus.Metric) {
+	r.mu.Lock()
+	lastSuccessful, lastSuccessTime := r.lastSuccessful, r.lastSuccessTime
+	r.mu.Unlock()
+
+	var lastSuccessfulValue float64
+	if lastSuccessful {
+		lastSuccessfulValue = 1
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		r.lastReloadSuccessfulDesc,
+		prometheus.GaugeValue,
+		lastSuccessfulValue,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		r.lastReloadSuccessTimestampDesc,
+		prometheus.GaugeValue,
+		float64(lastSuccessTime.UnixNano())/1e9,
+	)
+}
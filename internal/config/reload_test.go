@@ -0,0 +1,46 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloaderServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	var reloadErr error
+
+	reloader := NewReloader(slog.New(slog.NewTextHandler(io.Discard, nil)), func() error {
+		return reloadErr
+	})
+
+	rec := httptest.NewRecorder()
+	reloader.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/-/reload", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	require.NoError(t, testutil.CollectAndCompare(reloader, strings.NewReader(`
+# HELP windows_exporter_config_last_reload_successful windows_exporter: Whether the last configuration reload attempt was successful.
+# TYPE windows_exporter_config_last_reload_successful gauge
+windows_exporter_config_last_reload_successful 1
+`), "windows_exporter_config_last_reload_successful"))
+
+	reloadErr = errors.New("broken config")
+
+	rec = httptest.NewRecorder()
+	reloader.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/-/reload", nil))
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	require.NoError(t, testutil.CollectAndCompare(reloader, strings.NewReader(`
+# HELP windows_exporter_config_last_reload_successful windows_exporter: Whether the last configuration reload attempt was successful.
+# TYPE windows_exporter_config_last_reload_successful gauge
+windows_exporter_config_last_reload_successful 0
+`), "windows_exporter_config_last_reload_successful"))
+}
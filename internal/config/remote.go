@@ -0,0 +1,311 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	remoteRetryBackoff    = time.Second
+	remoteRetryMaxBackoff = 30 * time.Second
+	remoteRequestTimeout  = time.Minute
+)
+
+// RemoteOptions configures the loading of configuration files from URLs.
+type RemoteOptions struct {
+	// InsecureSkipVerify disables the verification of the certificate of the server.
+	InsecureSkipVerify bool
+	// CAFile is a file with the PEM encoded CA certificates, which verify the certificate of the server.
+	// The CA certificates of the system are used, if empty.
+	CAFile string
+	// CertFile and KeyFile are the PEM encoded client certificate and key for TLS client authentication.
+	CertFile string
+	KeyFile  string
+	// BearerTokenFile is a file with the token of the Authorization header. The file is read on every request.
+	BearerTokenFile string
+	// BasicAuthUsername and BasicAuthPasswordFile set the Authorization header with basic authentication.
+	// The password file is read on every request.
+	BasicAuthUsername     string
+	BasicAuthPasswordFile string
+	// RetryTimeout is the maximum duration of the retries of a failed request on startup. 0 to disable.
+	RetryTimeout time.Duration
+	// CacheDir is the directory, which keeps the last known good configuration files. Empty to disable.
+	CacheDir string
+}
+
+// Remote loads configuration files from URLs.
+//
+// A file is requested with If-None-Match and If-Modified-Since based on the last response of the server,
+// so unchanged files are not transferred again.
+type Remote struct {
+	logger  *slog.Logger
+	options RemoteOptions
+	client  *http.Client
+
+	mu sync.Mutex
+	// responses are the last successful responses by URL.
+	responses map[string]remoteResponse
+}
+
+type remoteResponse struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// statusError is an unexpected status code of the server.
+type statusError struct {
+	url  string
+	code int
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("failed to read configuration file from URL %s: unexpected status %s", e.url, http.StatusText(e.code))
+}
+
+// NewRemote returns a Remote with the given options.
+func NewRemote(logger *slog.Logger, options RemoteOptions) (*Remote, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: options.InsecureSkipVerify} //nolint:gosec
+
+	if options.InsecureSkipVerify {
+		logger.Warn("Loading configuration file with TLS verification disabled")
+	}
+
+	if options.CAFile != "" {
+		ca, err := os.ReadFile(options.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in CA file %s", options.CAFile)
+		}
+	}
+
+	if options.CertFile != "" || options.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	if options.BearerTokenFile != "" && (options.BasicAuthUsername != "" || options.BasicAuthPasswordFile != "") {
+		return nil, errors.New("at most one of bearer token and basic authentication can be configured")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+	transport.TLSClientConfig = tlsConfig
+
+	return &Remote{
+		logger:    logger,
+		options:   options,
+		client:    &http.Client{Transport: transport, Timeout: remoteRequestTimeout},
+		responses: make(map[string]remoteResponse),
+	}, nil
+}
+
+// Load returns the configuration file of url. Failed requests are retried with backoff for RetryTimeout.
+// If the server is still unavailable, the last known good file of the cache is returned.
+func (r *Remote) Load(ctx context.Context, url string) ([]byte, error) {
+	r.logger.Info("Loading configuration file from URL: " + url)
+
+	deadline := time.Now().Add(r.options.RetryTimeout)
+	backoff := remoteRetryBackoff
+
+	for {
+		body, err := r.Get(ctx, url)
+		if err == nil {
+			return body, nil
+		}
+
+		if !retryable(err) || time.Now().Add(backoff).After(deadline) {
+			return r.loadCache(url, err)
+		}
+
+		r.logger.Warn("Failed to load configuration file from URL, retrying",
+			slog.String("url", url),
+			slog.Duration("backoff", backoff),
+			slog.Any("err", err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, remoteRetryMaxBackoff)
+	}
+}
+
+// Get returns the configuration file of url without retries. If the server reports an unchanged file,
+// the body of the last response is returned.
+func (r *Remote) Get(ctx context.Context, url string) ([]byte, error) {
+	r.mu.Lock()
+	last, ok := r.responses[url]
+	r.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	if err = r.authorize(req); err != nil {
+		return nil, err
+	}
+
+	if ok {
+		if last.etag != "" {
+			req.Header.Set("If-None-Match", last.etag)
+		}
+
+		if last.lastModified != "" {
+			req.Header.Set("If-Modified-Since", last.lastModified)
+		}
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file from URL: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ok:
+		return last.body, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, statusError{url: url, code: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file from URL: %w", err)
+	}
+
+	r.mu.Lock()
+	r.responses[url] = remoteResponse{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		body:         body,
+	}
+	r.mu.Unlock()
+
+	return body, nil
+}
+
+// authorize sets the Authorization header of req.
+func (r *Remote) authorize(req *http.Request) error {
+	switch {
+	case r.options.BearerTokenFile != "":
+		token, err := os.ReadFile(r.options.BearerTokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read bearer token file: %w", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	case r.options.BasicAuthUsername != "":
+		var password []byte
+
+		if r.options.BasicAuthPasswordFile != "" {
+			var err error
+
+			password, err = os.ReadFile(r.options.BasicAuthPasswordFile)
+			if err != nil {
+				return fmt.Errorf("failed to read basic auth password file: %w", err)
+			}
+		}
+
+		req.SetBasicAuth(r.options.BasicAuthUsername, strings.TrimRight(string(password), "\r\n"))
+	}
+
+	return nil
+}
+
+// retryable returns true, if a request, which failed with err, may succeed later.
+// Responses of the server with a client error, like 404 Not Found, are not retried.
+func retryable(err error) bool {
+	var statusErr statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= http.StatusInternalServerError || statusErr.code == http.StatusTooManyRequests
+	}
+
+	return true
+}
+
+// SaveCache stores the configuration files of urls as last known good configuration files.
+// It is called, after the configuration is loaded successfully.
+func (r *Remote) SaveCache(urls []string) error {
+	if r.options.CacheDir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(r.options.CacheDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	for _, url := range urls {
+		r.mu.Lock()
+		response, ok := r.responses[url]
+		r.mu.Unlock()
+
+		if !ok {
+			continue
+		}
+
+		file := r.cacheFile(url)
+
+		// The file is replaced atomically, so a crash doesn't leave a partial file.
+		if err := os.WriteFile(file+".tmp", response.body, 0o600); err != nil {
+			return fmt.Errorf("failed to write cached configuration file: %w", err)
+		}
+
+		if err := os.Rename(file+".tmp", file); err != nil {
+			return fmt.Errorf("failed to write cached configuration file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// loadCache returns the cached configuration file of url. err is the error of the request of the file.
+func (r *Remote) loadCache(url string, err error) ([]byte, error) {
+	if r.options.CacheDir == "" {
+		return nil, err
+	}
+
+	body, cacheErr := os.ReadFile(r.cacheFile(url))
+	if cacheErr != nil {
+		return nil, errors.Join(err, fmt.Errorf("failed to read cached configuration file: %w", cacheErr))
+	}
+
+	r.logger.Warn("Failed to load configuration file from URL, using the last known good configuration file",
+		slog.String("url", url),
+		slog.Any("err", err),
+	)
+
+	return body, nil
+}
+
+// cacheFile returns the name of the cached configuration file of url.
+func (r *Remote) cacheFile(url string) string {
+	sum := sha256.Sum256([]byte(url))
+
+	return filepath.Join(r.options.CacheDir, hex.EncodeToString(sum[:8])+".yaml")
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteLoad(t *testing.T) {
+	t.Parallel()
+
+	var (
+		requests    atomic.Int32
+		notModified atomic.Int32
+		unavailable atomic.Bool
+		content     atomic.Value
+	)
+
+	content.Store("log.level: debug\n")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		if unavailable.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		etag := fmt.Sprintf(`"%x"`, sha256.Sum256([]byte(content.Load().(string)))) //nolint:forcetypeassert
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(content.Load().(string))) //nolint:forcetypeassert
+	}))
+	t.Cleanup(server.Close)
+
+	dir := writeFiles(t, map[string]string{"token": "token\n"})
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	remote, err := NewRemote(logger, RemoteOptions{
+		BearerTokenFile: filepath.Join(dir, "token"),
+		CacheDir:        filepath.Join(dir, "cache"),
+	})
+	require.NoError(t, err)
+
+	resolver, err := NewResolver(server.URL, logger, remote)
+	require.NoError(t, err)
+	require.NoError(t, resolver.SaveCache())
+
+	// The unchanged file is not transferred again.
+	body, err := remote.Get(context.Background(), server.URL)
+	require.NoError(t, err)
+	require.Equal(t, "log.level: debug\n", string(body))
+	require.Equal(t, int32(1), notModified.Load())
+
+	checksum, err := fileChecksum(server.URL, remote)
+	require.NoError(t, err)
+
+	content.Store("log.level: info\n")
+
+	changedChecksum, err := fileChecksum(server.URL, remote)
+	require.NoError(t, err)
+	require.NotEqual(t, checksum, changedChecksum)
+
+	// The last known good file is used, if the server is unavailable.
+	unavailable.Store(true)
+
+	cached, err := NewRemote(logger, RemoteOptions{
+		BearerTokenFile: filepath.Join(dir, "token"),
+		CacheDir:        filepath.Join(dir, "cache"),
+	})
+	require.NoError(t, err)
+
+	body, err = cached.Load(context.Background(), server.URL)
+	require.NoError(t, err)
+	require.Equal(t, "log.level: debug\n", string(body))
+
+	// Unauthorized requests are not retried.
+	unauthorized, err := NewRemote(logger, RemoteOptions{RetryTimeout: time.Minute})
+	require.NoError(t, err)
+
+	count := requests.Load()
+
+	_, err = unauthorized.Load(context.Background(), server.URL)
+	require.ErrorContains(t, err, "Unauthorized")
+	require.Equal(t, count+1, requests.Load())
+}
+
+func TestRemoteLoadRetry(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("log.level: debug\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	remote, err := NewRemote(slog.New(slog.NewTextHandler(io.Discard, nil)), RemoteOptions{RetryTimeout: time.Minute})
+	require.NoError(t, err)
+
+	body, err := remote.Load(context.Background(), server.URL)
+	require.NoError(t, err)
+	require.Equal(t, "log.level: debug\n", string(body))
+	require.Equal(t, int32(2), requests.Load())
+}
+
+func TestRemoteInclude(t *testing.T) {
+	t.Parallel()
+
+	dir := writeFiles(t, map[string]string{"local.yaml": "log.level: ${file:secret}\n", "secret": "s3cr3t\n"})
+
+	var include atomic.Value
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/config.yaml":
+			_, _ = fmt.Fprintf(w, "include: %q\n", include.Load())
+		case "/debug.yaml":
+			_, _ = w.Write([]byte("log.level: debug\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	remote, err := NewRemote(logger, RemoteOptions{})
+	require.NoError(t, err)
+
+	// Relative includes of a URL are URLs.
+	include.Store("debug.yaml")
+
+	resolver, err := NewResolver(server.URL+"/config.yaml", logger, remote)
+	require.NoError(t, err)
+	require.Equal(t, []string{server.URL + "/config.yaml", server.URL + "/debug.yaml"}, resolver.Files())
+
+	// Local files are expanded, so a file of a URL must not include them.
+	include.Store(filepath.Join(dir, "local.yaml"))
+
+	_, err = NewResolver(server.URL+"/config.yaml", logger, remote)
+	require.ErrorContains(t, err, "a configuration file loaded from a URL can only include URLs")
+}
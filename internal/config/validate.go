@@ -0,0 +1,248 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Schema describes the keys of the configuration file in addition to the flags.
+type Schema struct {
+	// Sections maps the top-level keys, which are decoded by Unmarshal, to a pointer to a value of the decoded type.
+	Sections map[string]interface{}
+	// Values validates the values of flags by the name of the flag.
+	// Lists and comma-separated values are validated value by value.
+	Values map[string]func(value string) error
+}
+
+// PositionError is an error at a position of a configuration file.
+type PositionError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func newPositionError(node *yaml.Node, err error) *PositionError {
+	return &PositionError{Line: node.Line, Column: node.Column, Err: err}
+}
+
+func (e *PositionError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Err)
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Err)
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+// validator holds the state of Resolver.Validate.
+type validator struct {
+	origins map[*yaml.Node]string
+	flags   map[string]*kingpin.FlagModel
+	models  []*kingpin.FlagModel
+	schema  Schema
+	errs    []error
+}
+
+// Validate reports all keys of the configuration files, which are neither flags of app nor keys of the sections of schema,
+// and invalid values, like invalid regular expressions. The values of the sections are decoded to report invalid types.
+// The returned error joins all errors, every error is a *PositionError with the file, line and column.
+func (c *Resolver) Validate(app *kingpin.Application, schema Schema) error {
+	v := &validator{
+		origins: c.origins,
+		flags:   make(map[string]*kingpin.FlagModel),
+		schema:  schema,
+	}
+
+	model := app.Model()
+	v.addFlags(model.Flags)
+
+	for _, command := range model.Commands {
+		v.addFlags(command.Flags)
+	}
+
+	for i := 0; i < len(c.root.Content); i += 2 {
+		key, value := c.root.Content[i], c.root.Content[i+1]
+		errs := len(v.errs)
+
+		v.walkKey("", key, value, nil)
+
+		// Invalid values of sections, which are not reported by walk, like invalid types, are reported by decoding them.
+		section, ok := schema.Sections[key.Value]
+		if !ok || len(v.errs) > errs {
+			continue
+		}
+
+		if err := Decode(value, reflect.New(reflect.TypeOf(section).Elem()).Interface()); err != nil {
+			v.addError(key, fmt.Errorf("invalid value of %s: %w", key.Value, err))
+		}
+	}
+
+	return errors.Join(v.errs...)
+}
+
+// addError adds err at the position of node.
+func (v *validator) addError(node *yaml.Node, err error) {
+	positionError := newPositionError(node, err)
+	positionError.File = v.origins[node]
+
+	v.errs = append(v.errs, positionError)
+}
+
+func (v *validator) addFlags(flags []*kingpin.FlagModel) {
+	for _, f := range flags {
+		v.flags[f.Name] = f
+	}
+
+	v.models = append(v.models, flags...)
+}
+
+// walk validates node, which is the value of the key name. typ is the type, the node is decoded into, or nil,
+// if the node is not part of a section.
+func (v *validator) walk(name string, node *yaml.Node, typ reflect.Type) {
+	for typ != nil && typ.Kind() == reflect.Pointer && typ != regexpType {
+		typ = typ.Elem()
+	}
+
+	if f, ok := v.flags[name]; ok && typ == nil {
+		v.validateFlag(f, node)
+
+		return
+	}
+
+	if typ == regexpType && node.Kind == yaml.ScalarNode {
+		if _, err := regexp.Compile("^(?:" + node.Value + ")$"); err != nil {
+			v.addError(node, fmt.Errorf("invalid regular expression: %w", err))
+		}
+
+		return
+	}
+
+	// Types with their own decoding are validated by decoding them. Interfaces accept any value.
+	if typ != nil && (typ == regexpType || typ.Kind() == reflect.Interface || decodesItself(typ)) {
+		return
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content); i += 2 {
+			v.walkKey(name, node.Content[i], node.Content[i+1], typ)
+		}
+	case yaml.SequenceNode:
+		if typ == nil {
+			v.addError(node, fmt.Errorf("the value of %s must be a mapping", name))
+
+			break
+		}
+
+		if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array {
+			break
+		}
+
+		for _, item := range node.Content {
+			v.walk(name, item, typ.Elem())
+		}
+	case yaml.ScalarNode:
+		// The values of sections are validated by decoding them.
+		if typ == nil && node.ShortTag() != "!!null" {
+			v.addError(node, fmt.Errorf("the value of %s must be a mapping", name))
+		}
+	case yaml.DocumentNode, yaml.AliasNode:
+	}
+}
+
+// walkKey validates key and its value below the key parent of the type typ.
+func (v *validator) walkKey(parent string, key, value *yaml.Node, typ reflect.Type) {
+	name := join(parent, key.Value)
+	valueType := v.typeOf(parent, typ, key.Value)
+
+	if _, ok := v.flags[name]; !ok && valueType == nil && !hasPrefix(v.models, name) {
+		v.addError(key, fmt.Errorf("unknown key %s", name))
+
+		return
+	}
+
+	v.walk(name, value, valueType)
+}
+
+// typeOf returns the type of the value of key below the value of the key parent of the type typ
+// or nil, if the key is not part of a section.
+func (v *validator) typeOf(parent string, typ reflect.Type, key string) reflect.Type {
+	if parent == "" {
+		if section, ok := v.schema.Sections[key]; ok {
+			return reflect.TypeOf(section).Elem()
+		}
+
+		return nil
+	}
+
+	if typ == nil {
+		return nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		field, _, ok := fieldByKey(reflect.New(typ).Elem(), key)
+		if !ok {
+			return nil
+		}
+
+		return field.Type()
+	case reflect.Map:
+		return typ.Elem()
+	default:
+		return nil
+	}
+}
+
+// validateFlag validates node, which is the value of the flag f.
+func (v *validator) validateFlag(f *kingpin.FlagModel, node *yaml.Node) {
+	var values []*yaml.Node
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		values = []*yaml.Node{node}
+	case yaml.SequenceNode:
+		if _, ok := scalarValues(node.Content); !ok {
+			v.addError(node, fmt.Errorf("the value of %s must be a list of scalars", f.Name))
+
+			return
+		}
+
+		values = node.Content
+	case yaml.MappingNode:
+		if !isCumulativeValue(f.Value) {
+			v.addError(node, fmt.Errorf("the value of %s must not be a mapping", f.Name))
+		}
+
+		return
+	case yaml.DocumentNode, yaml.AliasNode:
+		return
+	}
+
+	validate, ok := v.schema.Values[f.Name]
+	if !ok {
+		return
+	}
+
+	for _, value := range values {
+		for _, item := range strings.Split(value.Value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+
+			if err := validate(item); err != nil {
+				v.addError(value, fmt.Errorf("invalid value of %s: %w", f.Name, err))
+			}
+		}
+	}
+}
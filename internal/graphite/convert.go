@@ -0,0 +1,244 @@
+package graphite
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Kind distinguishes monotonic counters from gauges. StatsD sends counters as increments.
+type Kind int
+
+const (
+	KindGauge Kind = iota
+	KindCounter
+)
+
+// Sample is a single value with its Graphite path.
+type Sample struct {
+	Path      string
+	Value     float64
+	Kind      Kind
+	Timestamp time.Time
+}
+
+// Converter converts metric families to Graphite samples.
+type Converter struct {
+	prefix    string
+	templates []*Template
+	fallback  *Template
+}
+
+// NewConverter returns a Converter. The first matching template determines the path of a metric. Metrics without
+// a matching template use DefaultTemplate. prefix is prepended to every path.
+func NewConverter(prefix string, templates []TemplateConfig) (*Converter, error) {
+	c := &Converter{
+		prefix:    prefix,
+		templates: make([]*Template, 0, len(templates)),
+	}
+
+	for _, cfg := range templates {
+		t, err := NewTemplate(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		c.templates = append(c.templates, t)
+	}
+
+	var err error
+
+	c.fallback, err = NewTemplate(TemplateConfig{Template: DefaultTemplate})
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Convert returns the samples of the metric families. Summaries and histograms are split into the series of the
+// classic Prometheus representation, e.g. <name>_sum, <name>_count and <name>_bucket with the le label.
+// Metrics without a timestamp get the timestamp now. Samples with the values NaN or ±Inf are skipped.
+func (c *Converter) Convert(families []*dto.MetricFamily, now time.Time) []Sample {
+	samples := make([]Sample, 0, len(families))
+
+	for _, family := range families {
+		name := family.GetName()
+
+		for _, metric := range family.GetMetric() {
+			timestamp := now
+			if metric.TimestampMs != nil {
+				timestamp = time.UnixMilli(metric.GetTimestampMs())
+			}
+
+			labels := make(map[string]string, len(metric.GetLabel())+1)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			add := func(name string, value float64, kind Kind, extraLabel ...string) {
+				if math.IsNaN(value) || math.IsInf(value, 0) {
+					return
+				}
+
+				sampleLabels := labels
+				if len(extraLabel) == 2 {
+					sampleLabels = make(map[string]string, len(labels)+1)
+					for k, v := range labels {
+						sampleLabels[k] = v
+					}
+
+					sampleLabels[extraLabel[0]] = extraLabel[1]
+				}
+
+				samples = append(samples, Sample{
+					Path:      c.path(name, sampleLabels),
+					Value:     value,
+					Kind:      kind,
+					Timestamp: timestamp,
+				})
+			}
+
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, metric.GetCounter().GetValue(), KindCounter)
+			case dto.MetricType_GAUGE:
+				add(name, metric.GetGauge().GetValue(), KindGauge)
+			case dto.MetricType_UNTYPED:
+				add(name, metric.GetUntyped().GetValue(), KindGauge)
+			case dto.MetricType_SUMMARY:
+				summary := metric.GetSummary()
+
+				for _, quantile := range summary.GetQuantile() {
+					add(name, quantile.GetValue(), KindGauge, "quantile", formatFloat(quantile.GetQuantile()))
+				}
+
+				add(name+"_sum", summary.GetSampleSum(), KindCounter)
+				add(name+"_count", float64(summary.GetSampleCount()), KindCounter)
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				histogram := metric.GetHistogram()
+				hasInf := false
+
+				for _, bucket := range histogram.GetBucket() {
+					if math.IsInf(bucket.GetUpperBound(), +1) {
+						hasInf = true
+					}
+
+					add(name+"_bucket", float64(bucket.GetCumulativeCount()), KindCounter, "le", formatFloat(bucket.GetUpperBound()))
+				}
+
+				if !hasInf {
+					add(name+"_bucket", float64(histogram.GetSampleCount()), KindCounter, "le", "+Inf")
+				}
+
+				add(name+"_sum", histogram.GetSampleSum(), KindCounter)
+				add(name+"_count", float64(histogram.GetSampleCount()), KindCounter)
+			}
+		}
+	}
+
+	return samples
+}
+
+func (c *Converter) path(name string, labels map[string]string) string {
+	template := c.fallback
+
+	for _, t := range c.templates {
+		if t.Matches(name) {
+			template = t
+
+			break
+		}
+	}
+
+	p := template.Path(name, labels)
+	if c.prefix == "" {
+		return p
+	}
+
+	return c.prefix + "." + p
+}
+
+// AppendPlaintext appends the samples to b in the Graphite plaintext protocol and returns the extended buffer.
+//
+// https://graphite.readthedocs.io/en/latest/feeding-carbon.html#the-plaintext-protocol
+func AppendPlaintext(b []byte, samples []Sample) []byte {
+	for _, sample := range samples {
+		b = append(b, sample.Path...)
+		b = append(b, ' ')
+		b = strconv.AppendFloat(b, sample.Value, 'f', -1, 64)
+		b = append(b, ' ')
+		b = strconv.AppendInt(b, sample.Timestamp.Unix(), 10)
+		b = append(b, '\n')
+	}
+
+	return b
+}
+
+// StatsDEncoder converts samples into StatsD lines. Gauges become StatsD gauges. Counters become StatsD counters
+// with the increment since the previous conversion, so the encoder keeps the last value of every counter.
+// An encoder is not safe for concurrent use.
+type StatsDEncoder struct {
+	previous map[string]float64
+}
+
+func NewStatsDEncoder() *StatsDEncoder {
+	return &StatsDEncoder{previous: make(map[string]float64)}
+}
+
+// Lines returns a StatsD line per sample. The first conversion of a counter only records its value.
+// A counter, which decreased, was reset and the increment is its current value.
+//
+// https://github.com/statsd/statsd/blob/master/docs/metric_types.md
+func (e *StatsDEncoder) Lines(samples []Sample) []string {
+	lines := make([]string, 0, len(samples))
+	seen := make(map[string]struct{}, len(e.previous))
+
+	for _, sample := range samples {
+		switch sample.Kind {
+		case KindCounter:
+			seen[sample.Path] = struct{}{}
+
+			previous, ok := e.previous[sample.Path]
+			e.previous[sample.Path] = sample.Value
+
+			if !ok {
+				continue
+			}
+
+			increment := sample.Value - previous
+			if increment < 0 {
+				increment = sample.Value
+			}
+
+			lines = append(lines, sample.Path+":"+strconv.FormatFloat(increment, 'f', -1, 64)+"|c")
+		case KindGauge:
+			// A gauge value with a sign is an adjustment of the current value in StatsD. A negative value has to be
+			// set by a reset to 0 first.
+			if sample.Value < 0 {
+				lines = append(lines, sample.Path+":0|g")
+			}
+
+			lines = append(lines, sample.Path+":"+strconv.FormatFloat(sample.Value, 'f', -1, 64)+"|g")
+		}
+	}
+
+	// Forget the counters, which disappeared, e.g. of a stopped process.
+	for path := range e.previous {
+		if _, ok := seen[path]; !ok {
+			delete(e.previous, path)
+		}
+	}
+
+	return lines
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, +1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
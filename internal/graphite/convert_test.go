@@ -0,0 +1,81 @@
+package graphite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/testutils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+var cpuTimeDesc = prometheus.NewDesc("windows_cpu_time_total", "", []string{"core", "mode"}, nil)
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+
+	converter, err := NewConverter("servers.web01", []TemplateConfig{
+		{Match: "windows_cpu_*", Template: "cpu.{core}.{mode}"},
+	})
+	require.NoError(t, err)
+
+	families := testutils.Gather(t,
+		prometheus.MustNewConstMetric(cpuTimeDesc, prometheus.CounterValue, 12.5, "0", "idle"),
+		prometheus.MustNewConstMetric(
+			prometheus.NewDesc("windows_memory_available_bytes", "", nil, nil),
+			prometheus.GaugeValue, 1024,
+		),
+		prometheus.MustNewConstHistogram(
+			prometheus.NewDesc("test_seconds", "", nil, nil),
+			3, 1.5, map[float64]uint64{0.5: 2},
+		),
+	)
+
+	now := time.Unix(1700000000, 0)
+
+	require.Equal(t, `servers.web01.test_seconds_bucket.le.0_5 2 1700000000
+servers.web01.test_seconds_bucket.le._Inf 3 1700000000
+servers.web01.test_seconds_sum 1.5 1700000000
+servers.web01.test_seconds_count 3 1700000000
+servers.web01.cpu.0.idle 12.5 1700000000
+servers.web01.windows_memory_available_bytes 1024 1700000000
+`, string(AppendPlaintext(nil, converter.Convert(families, now))))
+}
+
+func TestStatsDEncoder(t *testing.T) {
+	t.Parallel()
+
+	converter, err := NewConverter("", []TemplateConfig{{Match: "windows_cpu_*", Template: "cpu.{core}.{mode}"}})
+	require.NoError(t, err)
+
+	temperatureDesc := prometheus.NewDesc("temperature_celsius", "", nil, nil)
+	encoder := NewStatsDEncoder()
+
+	// The first conversion records the counters only.
+	lines := encoder.Lines(converter.Convert(testutils.Gather(t,
+		prometheus.MustNewConstMetric(cpuTimeDesc, prometheus.CounterValue, 10, "0", "idle"),
+		prometheus.MustNewConstMetric(temperatureDesc, prometheus.GaugeValue, 21.5),
+	), time.Now()))
+	require.Equal(t, []string{"temperature_celsius:21.5|g"}, lines)
+
+	lines = encoder.Lines(converter.Convert(testutils.Gather(t,
+		prometheus.MustNewConstMetric(cpuTimeDesc, prometheus.CounterValue, 15.5, "0", "idle"),
+		prometheus.MustNewConstMetric(temperatureDesc, prometheus.GaugeValue, -3),
+	), time.Now()))
+	require.Equal(t, []string{"temperature_celsius:0|g", "temperature_celsius:-3|g", "cpu.0.idle:5.5|c"}, lines)
+
+	// A reset counter increments by its current value.
+	lines = encoder.Lines(converter.Convert(testutils.Gather(t,
+		prometheus.MustNewConstMetric(cpuTimeDesc, prometheus.CounterValue, 2, "0", "idle"),
+	), time.Now()))
+	require.Equal(t, []string{"cpu.0.idle:2|c"}, lines)
+
+	// Counters, which disappeared, start over.
+	lines = encoder.Lines(nil)
+	require.Empty(t, lines)
+
+	lines = encoder.Lines(converter.Convert(testutils.Gather(t,
+		prometheus.MustNewConstMetric(cpuTimeDesc, prometheus.CounterValue, 4, "0", "idle"),
+	), time.Now()))
+	require.Empty(t, lines)
+}
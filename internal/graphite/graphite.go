@@ -0,0 +1,218 @@
+// Package graphite sends metrics to Graphite with the plaintext protocol over TCP or to StatsD over UDP.
+// The conversion of the metrics is independent of the network layer.
+package graphite
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/sink"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ProtocolTCP    = "tcp"
+	ProtocolStatsD = "statsd"
+)
+
+const (
+	defaultInterval = time.Minute
+	defaultTimeout  = 10 * time.Second
+
+	// maxPacketSize keeps StatsD packets below the usual MTU of 1500 bytes.
+	maxPacketSize = 1432
+)
+
+// Config is the configuration of the Graphite sink.
+type Config struct {
+	// Address of the Carbon plaintext receiver or of the StatsD server, e.g. graphite:2003.
+	Address string `yaml:"address"`
+	// Protocol is either tcp for the Graphite plaintext protocol or statsd for StatsD over UDP. Defaults to tcp.
+	Protocol string `yaml:"protocol"`
+	// Prefix is prepended to every path.
+	Prefix string `yaml:"prefix"`
+	// Templates determine the paths of the metrics. The first matching template applies.
+	Templates []TemplateConfig `yaml:"templates"`
+	// Interval between two sends. Defaults to 1m.
+	Interval time.Duration `yaml:"interval"`
+	// Timeout of a single send. Defaults to 10s.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// UnmarshalYAML applies the defaults and validates the configuration.
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	type plain Config
+
+	*c = Config{
+		Protocol: ProtocolTCP,
+	}
+
+	if err := value.Decode((*plain)(c)); err != nil {
+		return err
+	}
+
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return fmt.Errorf("invalid graphite address %q: %w", c.Address, err)
+	}
+
+	if c.Protocol != ProtocolTCP && c.Protocol != ProtocolStatsD {
+		return fmt.Errorf("unknown graphite protocol %q, must be %s or %s", c.Protocol, ProtocolTCP, ProtocolStatsD)
+	}
+
+	for _, template := range c.Templates {
+		if _, err := NewTemplate(template); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Enabled returns true, if an address is configured.
+func (c Config) Enabled() bool {
+	return c.Address != ""
+}
+
+// Interface guard.
+var _ prometheus.Collector = (*Sink)(nil)
+
+// Sink periodically gathers metrics and sends them to Graphite or StatsD.
+type Sink struct {
+	logger    *slog.Logger
+	config    Config
+	converter *Converter
+	statsd    *StatsDEncoder
+
+	stats *sink.Stats
+}
+
+// New returns a Sink for the configuration. The Sink has to be started by Run.
+func New(logger *slog.Logger, cfg Config) (*Sink, error) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	converter, err := NewConverter(cfg.Prefix, cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sink{
+		logger:    logger,
+		config:    cfg,
+		converter: converter,
+		statsd:    NewStatsDEncoder(),
+		stats:     sink.NewStats("graphite", "batches", "Graphite or StatsD", nil),
+	}, nil
+}
+
+// Run sends the metrics of gatherer on every interval until ctx is done.
+func (s *Sink) Run(ctx context.Context, gatherer prometheus.Gatherer) {
+	sink.Run(ctx, s.config.Interval, func(ctx context.Context) {
+		s.Send(ctx, gatherer)
+	})
+}
+
+// Send gathers the metrics once and sends them. Send must not be called concurrently, as StatsD counters
+// are sent as increments since the previous call.
+func (s *Sink) Send(ctx context.Context, gatherer prometheus.Gatherer) {
+	var err error
+
+	families := sink.Gather(s.logger, gatherer)
+
+	samples := s.converter.Convert(families, time.Now())
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	if s.config.Protocol == ProtocolStatsD {
+		err = s.sendStatsD(ctx, s.statsd.Lines(samples))
+	} else {
+		err = s.sendPlaintext(ctx, AppendPlaintext(nil, samples))
+	}
+
+	if err != nil {
+		s.stats.Failure()
+
+		s.logger.Warn(fmt.Sprintf("failed to send metrics to %s", s.config.Address),
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	s.stats.Success()
+}
+
+func (s *Sink) sendPlaintext(ctx context.Context, data []byte) error {
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", s.config.Address)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	_, err = conn.Write(data)
+
+	return err
+}
+
+// sendStatsD sends the lines in packets, which don't exceed maxPacketSize, unless a single line is larger.
+func (s *Sink) sendStatsD(ctx context.Context, lines []string) error {
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "udp", s.config.Address)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	packet := make([]byte, 0, maxPacketSize)
+
+	for _, line := range lines {
+		if len(packet) > 0 && len(packet)+len(line)+1 > maxPacketSize {
+			if _, err = conn.Write(packet); err != nil {
+				return err
+			}
+
+			packet = packet[:0]
+		}
+
+		if len(packet) > 0 {
+			packet = append(packet, '\n')
+		}
+
+		packet = append(packet, line...)
+	}
+
+	if len(packet) > 0 {
+		_, err = conn.Write(packet)
+	}
+
+	return err
+}
+
+func (s *Sink) Describe(ch chan<- *prometheus.Desc) {
+	s.stats.Describe(ch)
+}
+
+func (s *Sink) Collect(ch chan<- prometheus.Metric) {
+	s.stats.Collect(ch)
+}
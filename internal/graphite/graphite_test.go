@@ -0,0 +1,121 @@
+package graphite
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func newTestSink(t *testing.T, config string) *Sink {
+	t.Helper()
+
+	var cfg Config
+
+	require.NoError(t, yaml.Unmarshal([]byte(config), &cfg))
+
+	sink, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+	require.NoError(t, err)
+
+	return sink
+}
+
+func newTestGatherer(value float64) prometheus.Gatherer {
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test", Help: "help"})
+	gauge.Set(value)
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(gauge)
+
+	return reg
+}
+
+func TestSinkTCP(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	received := make(chan string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		data, _ := io.ReadAll(conn)
+		received <- string(data)
+	}()
+
+	sink := newTestSink(t, "address: "+listener.Addr().String()+"\nprefix: host")
+	sink.Send(context.Background(), newTestGatherer(42))
+
+	select {
+	case data := <-received:
+		require.True(t, strings.HasPrefix(data, "host.test 42 "), data)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no metrics received")
+	}
+
+	require.Equal(t, uint64(1), sink.stats.Sent())
+}
+
+func TestSinkStatsD(t *testing.T) {
+	t.Parallel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	sink := newTestSink(t, "address: "+conn.LocalAddr().String()+"\nprotocol: statsd")
+	sink.Send(context.Background(), newTestGatherer(42))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	buf := make([]byte, maxPacketSize)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	require.Equal(t, "test:42|g", string(buf[:n]))
+}
+
+func TestSinkFailure(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	sink := newTestSink(t, "address: "+address)
+	sink.Send(context.Background(), newTestGatherer(42))
+
+	require.Equal(t, uint64(1), sink.stats.Failed())
+}
+
+func TestConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []string{
+		`address: graphite`,
+		"address: graphite:2003\nprotocol: udp",
+		"address: graphite:2003\ntemplates:\n  - template: \"{core\"",
+	} {
+		var cfg Config
+
+		require.Error(t, yaml.Unmarshal([]byte(config), &cfg), config)
+	}
+}
@@ -0,0 +1,132 @@
+package graphite
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// NameLabel is the placeholder for the metric name.
+	NameLabel = "__name__"
+	// remainingLabels is the placeholder for the labels, which aren't used elsewhere in the template.
+	remainingLabels = "*"
+
+	// DefaultTemplate is used for metrics, which don't match any template.
+	DefaultTemplate = "{__name__}.{*}"
+)
+
+var (
+	placeholderRegex = regexp.MustCompile(`\{([^{}]*)\}`)
+	invalidCharRegex = regexp.MustCompile(`[^a-zA-Z0-9_:\-]`)
+)
+
+// TemplateConfig configures how the labels of the metrics become the segments of the Graphite path.
+type TemplateConfig struct {
+	// Match is a glob pattern for the metric names, e.g. windows_cpu_*. Empty matches all metrics.
+	Match string `yaml:"match"`
+	// Template is a dotted path with placeholders. {__name__} is replaced by the metric name, {<label>} by the value
+	// of the label and {*} by the remaining labels as <label>.<value> segments.
+	Template string `yaml:"template"`
+}
+
+// Template converts the metric name and the labels to a Graphite path.
+type Template struct {
+	match    string
+	template string
+	// used holds the labels referenced by placeholders.
+	used map[string]struct{}
+}
+
+// NewTemplate parses and validates the template configuration.
+func NewTemplate(cfg TemplateConfig) (*Template, error) {
+	if cfg.Match != "" {
+		if _, err := path.Match(cfg.Match, ""); err != nil {
+			return nil, fmt.Errorf("invalid match pattern %q: %w", cfg.Match, err)
+		}
+	}
+
+	if cfg.Template == "" {
+		return nil, fmt.Errorf("template for %q must not be empty", cfg.Match)
+	}
+
+	if strings.ContainsAny(placeholderRegex.ReplaceAllString(cfg.Template, ""), "{}") {
+		return nil, fmt.Errorf("invalid template %q: unbalanced braces", cfg.Template)
+	}
+
+	t := &Template{
+		match:    cfg.Match,
+		template: cfg.Template,
+		used:     make(map[string]struct{}),
+	}
+
+	for _, match := range placeholderRegex.FindAllStringSubmatch(cfg.Template, -1) {
+		if match[1] == "" {
+			return nil, fmt.Errorf("invalid template %q: empty placeholder", cfg.Template)
+		}
+
+		t.used[match[1]] = struct{}{}
+	}
+
+	return t, nil
+}
+
+// Matches returns true, if the template applies to the metric name.
+func (t *Template) Matches(name string) bool {
+	if t.match == "" {
+		return true
+	}
+
+	ok, _ := path.Match(t.match, name)
+
+	return ok
+}
+
+// Path returns the Graphite path for the metric name and labels. Placeholders of missing labels are removed
+// together with their segment, if the segment becomes empty. Characters, which aren't valid in a Graphite path,
+// are replaced by an underscore.
+func (t *Template) Path(name string, labels map[string]string) string {
+	result := placeholderRegex.ReplaceAllStringFunc(t.template, func(placeholder string) string {
+		switch label := placeholder[1 : len(placeholder)-1]; label {
+		case NameLabel:
+			return sanitize(name)
+		case remainingLabels:
+			return t.remainingLabels(labels)
+		default:
+			return sanitize(labels[label])
+		}
+	})
+
+	segments := slices.DeleteFunc(strings.Split(result, "."), func(segment string) bool {
+		return segment == ""
+	})
+
+	return strings.Join(segments, ".")
+}
+
+func (t *Template) remainingLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+
+	for name, value := range labels {
+		if _, ok := t.used[name]; ok || value == "" {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	segments := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		segments = append(segments, sanitize(name), sanitize(labels[name]))
+	}
+
+	return strings.Join(segments, ".")
+}
+
+func sanitize(s string) string {
+	return invalidCharRegex.ReplaceAllString(s, "_")
+}
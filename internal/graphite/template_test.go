@@ -0,0 +1,88 @@
+package graphite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplatePath(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		template string
+		labels   map[string]string
+		expected string
+	}{
+		{
+			name:     "default",
+			template: DefaultTemplate,
+			labels:   map[string]string{"mode": "idle", "core": "0,1"},
+			expected: "windows_cpu_time_total.core.0_1.mode.idle",
+		},
+		{
+			name:     "labels as segments",
+			template: "cpu.{core}.{mode}.{__name__}",
+			labels:   map[string]string{"mode": "idle", "core": "0,1"},
+			expected: "cpu.0_1.idle.windows_cpu_time_total",
+		},
+		{
+			name:     "remaining labels",
+			template: "cpu.{core}.{*}",
+			labels:   map[string]string{"mode": "idle", "core": "0", "state": "running"},
+			expected: "cpu.0.mode.idle.state.running",
+		},
+		{
+			name:     "missing label",
+			template: "cpu.{socket}.{core}.time",
+			labels:   map[string]string{"core": "0"},
+			expected: "cpu.0.time",
+		},
+		{
+			name:     "placeholder inside a segment",
+			template: "cpu.core_{core}.time",
+			labels:   map[string]string{"core": "0"},
+			expected: "cpu.core_0.time",
+		},
+		{
+			name:     "invalid characters",
+			template: "disk.{volume}",
+			labels:   map[string]string{"volume": "HarddiskVolume 1.2"},
+			expected: "disk.HarddiskVolume_1_2",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			template, err := NewTemplate(TemplateConfig{Template: tc.template})
+			require.NoError(t, err)
+
+			require.Equal(t, tc.expected, template.Path("windows_cpu_time_total", tc.labels))
+		})
+	}
+}
+
+func TestTemplateMatches(t *testing.T) {
+	t.Parallel()
+
+	template, err := NewTemplate(TemplateConfig{Match: "windows_cpu_*", Template: "cpu"})
+	require.NoError(t, err)
+
+	require.True(t, template.Matches("windows_cpu_time_total"))
+	require.False(t, template.Matches("windows_memory_available_bytes"))
+}
+
+func TestNewTemplateInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, cfg := range []TemplateConfig{
+		{Template: ""},
+		{Template: "cpu.{core"},
+		{Template: "cpu.{}"},
+		{Match: "[", Template: "cpu"},
+	} {
+		_, err := NewTemplate(cfg)
+		require.Error(t, err, cfg)
+	}
+}
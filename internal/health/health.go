@@ -0,0 +1,91 @@
+// Package health derives the readiness of windows_exporter from the recorded status of the collectors.
+package health
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/prometheus-community/windows_exporter/internal/breaker"
+	"github.com/prometheus-community/windows_exporter/internal/collectorstatus"
+)
+
+// DefaultFailureThreshold is the number of consecutive failed runs, after which a collector is considered failing.
+const DefaultFailureThreshold = 3
+
+type Status string
+
+const (
+	// Healthy means that no collector is failing.
+	Healthy Status = "healthy"
+	// Degraded means that collectors, which are not required, are failing.
+	Degraded Status = "degraded"
+	// Unhealthy means that a required collector is failing or not enabled.
+	Unhealthy Status = "unhealthy"
+)
+
+// Rules configure the evaluation of the readiness.
+type Rules struct {
+	// RequiredCollectors are the collectors, which make windows_exporter unhealthy, if they are failing.
+	// Other failing collectors make windows_exporter degraded.
+	RequiredCollectors []string
+	// FailureThreshold is the number of consecutive failed runs, after which a collector is considered failing.
+	// Defaults to DefaultFailureThreshold.
+	FailureThreshold int
+}
+
+// Report is the readiness of windows_exporter with the reasons for a status other than Healthy.
+type Report struct {
+	Status  Status   `json:"status"`
+	Reasons []string `json:"reasons"`
+}
+
+// Evaluate evaluates the rules against the status of the collectors. A collector is failing, if its build failed,
+// its circuit breaker is open or its last runs failed or timed out.
+func Evaluate(statuses []collectorstatus.Status, rules Rules) Report {
+	threshold := rules.FailureThreshold
+	if threshold <= 0 {
+		threshold = DefaultFailureThreshold
+	}
+
+	report := Report{Status: Healthy, Reasons: []string{}}
+
+	for _, name := range rules.RequiredCollectors {
+		if !slices.ContainsFunc(statuses, func(s collectorstatus.Status) bool { return s.Name == name }) {
+			report.add(Unhealthy, fmt.Sprintf("required collector %s is not enabled", name))
+		}
+	}
+
+	for _, s := range statuses {
+		var reason string
+
+		switch {
+		case !s.Available:
+			reason = fmt.Sprintf("collector %s failed to build: %s", s.Name, s.BuildError)
+		case s.CircuitBreaker == breaker.Open.String():
+			reason = fmt.Sprintf("collector %s is skipped by its open circuit breaker", s.Name)
+		case s.ConsecutiveFailures >= threshold:
+			reason = fmt.Sprintf("collector %s failed its last %d runs", s.Name, s.ConsecutiveFailures)
+			if s.LastRun != nil && s.LastRun.Error != "" {
+				reason += ": " + s.LastRun.Error
+			}
+		default:
+			continue
+		}
+
+		if slices.Contains(rules.RequiredCollectors, s.Name) {
+			report.add(Unhealthy, reason)
+		} else {
+			report.add(Degraded, reason)
+		}
+	}
+
+	return report
+}
+
+func (r *Report) add(status Status, reason string) {
+	if status == Unhealthy || r.Status == Healthy {
+		r.Status = status
+	}
+
+	r.Reasons = append(r.Reasons, reason)
+}
@@ -0,0 +1,72 @@
+package health
+
+import (
+	"testing"
+
+	"github.com/prometheus-community/windows_exporter/internal/collectorstatus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	failing := collectorstatus.Status{
+		Name:                "iis",
+		Available:           true,
+		ConsecutiveFailures: 3,
+		LastRun:             &collectorstatus.Run{Outcome: collectorstatus.Failure, Error: "access denied"},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		statuses []collectorstatus.Status
+		rules    Rules
+		expected Report
+	}{
+		{
+			name: "healthy",
+			statuses: []collectorstatus.Status{
+				{Name: "cpu", Available: true, ConsecutiveFailures: 2},
+			},
+			rules:    Rules{RequiredCollectors: []string{"cpu"}},
+			expected: Report{Status: Healthy, Reasons: []string{}},
+		},
+		{
+			name:     "degraded",
+			statuses: []collectorstatus.Status{{Name: "cpu", Available: true}, failing},
+			rules:    Rules{RequiredCollectors: []string{"cpu"}},
+			expected: Report{Status: Degraded, Reasons: []string{"collector iis failed its last 3 runs: access denied"}},
+		},
+		{
+			name:     "required collector failing",
+			statuses: []collectorstatus.Status{failing},
+			rules:    Rules{RequiredCollectors: []string{"iis"}},
+			expected: Report{Status: Unhealthy, Reasons: []string{"collector iis failed its last 3 runs: access denied"}},
+		},
+		{
+			name:     "custom threshold",
+			statuses: []collectorstatus.Status{failing},
+			rules:    Rules{FailureThreshold: 5},
+			expected: Report{Status: Healthy, Reasons: []string{}},
+		},
+		{
+			name: "build failed and missing",
+			statuses: []collectorstatus.Status{
+				{Name: "mssql", BuildError: "no instance found"},
+				{Name: "cpu", Available: true, CircuitBreaker: "open"},
+			},
+			rules: Rules{RequiredCollectors: []string{"os", "mssql"}},
+			expected: Report{Status: Unhealthy, Reasons: []string{
+				"required collector os is not enabled",
+				"collector mssql failed to build: no instance found",
+				"collector cpu is skipped by its open circuit breaker",
+			}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.expected, Evaluate(tc.statuses, tc.rules))
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package httphandler
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// errTooManyRequests is returned by a coalesced gather, if the concurrency limit doesn't allow another collection run.
+var errTooManyRequests = errors.New("too many concurrent requests")
+
+// coalescer shares a single gather run between scrapes of the same set of collectors.
+// A scrape joins the run of a previous scrape, if the run is still in flight or
+// has been started within the coalesce window.
+type coalescer struct {
+	window time.Duration
+	// limit limits the number of concurrent gather runs. Scrapes, which join a run, don't count against the limit.
+	// nil disables the limit.
+	limit chan struct{}
+
+	mu sync.Mutex
+	// flights holds the runs by key, which can be joined. A run is removed, once its window expired.
+	flights map[string]*flight
+}
+
+type flight struct {
+	started time.Time
+	done    chan struct{}
+
+	metricFamilies []*dto.MetricFamily
+	err            error
+}
+
+func newCoalescer(window time.Duration, limit chan struct{}) *coalescer {
+	return &coalescer{
+		window:  window,
+		limit:   limit,
+		flights: make(map[string]*flight),
+	}
+}
+
+// coalesceKey returns an identifier for the set of requested collectors and the scrape timeout.
+// Scrapes with different timeouts don't share a run, since a run is cancelled after its timeout.
+func coalesceKey(requestedCollectors []string, scrapeTimeout time.Duration) string {
+	requestedCollectors = slices.Clone(requestedCollectors)
+	slices.Sort(requestedCollectors)
+
+	return strings.Join(slices.Compact(requestedCollectors), ",") + "/" + scrapeTimeout.String()
+}
+
+// gather joins the run of key or starts a new run of gatherer. errTooManyRequests is returned,
+// if a new run exceeds the concurrency limit.
+func (c *coalescer) gather(key string, gatherer prometheus.Gatherer) ([]*dto.MetricFamily, error) {
+	c.mu.Lock()
+
+	f, ok := c.flights[key]
+	if ok && (f.inFlight() || time.Since(f.started) <= c.window) {
+		c.mu.Unlock()
+
+		<-f.done
+
+		return f.metricFamilies, f.err
+	}
+
+	if c.limit != nil {
+		select {
+		case c.limit <- struct{}{}:
+			defer func() { <-c.limit }()
+		default:
+			c.mu.Unlock()
+
+			return nil, errTooManyRequests
+		}
+	}
+
+	f = &flight{
+		started: time.Now(),
+		done:    make(chan struct{}),
+	}
+
+	c.flights[key] = f
+
+	c.mu.Unlock()
+
+	defer func() {
+		close(f.done)
+		c.expire(key, f)
+	}()
+
+	f.metricFamilies, f.err = gatherer.Gather()
+
+	return f.metricFamilies, f.err
+}
+
+// expire removes the finished flight f of key, once its window expired. Otherwise, every combination of
+// collectors and timeouts ever requested would keep its metrics in memory.
+func (c *coalescer) expire(key string, f *flight) {
+	remove := func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		// A new flight of the key may have replaced f already.
+		if c.flights[key] == f {
+			delete(c.flights, key)
+		}
+	}
+
+	if remaining := time.Until(f.started.Add(c.window)); remaining > 0 {
+		time.AfterFunc(remaining, remove)
+
+		return
+	}
+
+	remove()
+}
+
+func (f *flight) inFlight() bool {
+	select {
+	case <-f.done:
+		return false
+	default:
+		return true
+	}
+}
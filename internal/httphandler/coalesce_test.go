@@ -0,0 +1,178 @@
+package httphandler
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingGatherer counts its runs. A run blocks until release is closed.
+type blockingGatherer struct {
+	runs    atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingGatherer() *blockingGatherer {
+	return &blockingGatherer{
+		started: make(chan struct{}, 10),
+		release: make(chan struct{}),
+	}
+}
+
+func (g *blockingGatherer) Gather() ([]*dto.MetricFamily, error) {
+	g.runs.Add(1)
+	g.started <- struct{}{}
+
+	<-g.release
+
+	return []*dto.MetricFamily{{}}, nil
+}
+
+func (c *coalescer) flightCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.flights)
+}
+
+func TestCoalescerShare(t *testing.T) {
+	t.Parallel()
+
+	c := newCoalescer(time.Minute, nil)
+	gatherer := newBlockingGatherer()
+	key := coalesceKey([]string{"os", "cpu"}, 10*time.Second)
+
+	var wg sync.WaitGroup
+
+	results := make([][]*dto.MetricFamily, 3)
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		results[0], _ = c.gather(key, gatherer)
+	}()
+
+	<-gatherer.started
+
+	// Scrapes of the same collectors in a different order join the run in flight.
+	for i := 1; i < len(results); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i], _ = c.gather(coalesceKey([]string{"cpu", "os", "cpu"}, 10*time.Second), gatherer)
+		}()
+	}
+
+	close(gatherer.release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), gatherer.runs.Load())
+	require.Same(t, results[0][0], results[1][0])
+	require.Same(t, results[0][0], results[2][0])
+
+	// A finished run is shared within the window.
+	_, err := c.gather(key, gatherer)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), gatherer.runs.Load())
+
+	// Other collectors and other scrape timeouts start their own run.
+	_, err = c.gather(coalesceKey([]string{"os"}, 10*time.Second), gatherer)
+	require.NoError(t, err)
+
+	_, err = c.gather(coalesceKey([]string{"os", "cpu"}, 5*time.Second), gatherer)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), gatherer.runs.Load())
+}
+
+func TestCoalescerExpiry(t *testing.T) {
+	t.Parallel()
+
+	var runs atomic.Int32
+
+	gatherer := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		runs.Add(1)
+
+		return nil, nil
+	})
+
+	c := newCoalescer(50*time.Millisecond, nil)
+	key := coalesceKey(nil, 10*time.Second)
+
+	_, err := c.gather(key, gatherer)
+	require.NoError(t, err)
+	require.Equal(t, 1, c.flightCount())
+
+	// The run is removed, once its window expired, and the next scrape starts a new run.
+	require.Eventually(t, func() bool { return c.flightCount() == 0 }, time.Second, time.Millisecond)
+
+	_, err = c.gather(key, gatherer)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), runs.Load())
+
+	require.Eventually(t, func() bool { return c.flightCount() == 0 }, time.Second, time.Millisecond)
+}
+
+func TestCoalescerCleanupWithoutWindow(t *testing.T) {
+	t.Parallel()
+
+	c := newCoalescer(0, nil)
+
+	for _, collectors := range [][]string{{"cpu"}, {"os"}, {"cpu", "os"}} {
+		_, err := c.gather(coalesceKey(collectors, 10*time.Second), prometheus.NewRegistry())
+		require.NoError(t, err)
+	}
+
+	require.Zero(t, c.flightCount())
+}
+
+func TestCoalescerLimit(t *testing.T) {
+	t.Parallel()
+
+	c := newCoalescer(time.Minute, make(chan struct{}, 1))
+	gatherer := newBlockingGatherer()
+	key := coalesceKey([]string{"cpu"}, 10*time.Second)
+
+	var wg sync.WaitGroup
+
+	errs := make([]error, 2)
+
+	for i := range errs {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, errs[i] = c.gather(key, gatherer)
+		}()
+
+		if i == 0 {
+			<-gatherer.started
+		}
+	}
+
+	// A scrape of other collectors needs its own run, which exceeds the limit.
+	_, err := c.gather(coalesceKey([]string{"os"}, 10*time.Second), gatherer)
+	require.ErrorIs(t, err, errTooManyRequests)
+
+	close(gatherer.release)
+	wg.Wait()
+
+	// The scrape, which joined the run, is not limited.
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.Equal(t, int32(1), gatherer.runs.Load())
+
+	// The slot is released after the run.
+	_, err = c.gather(coalesceKey([]string{"os"}, 10*time.Second), gatherer)
+	require.NoError(t, err)
+}
@@ -0,0 +1,95 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/collectorstatus"
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+)
+
+//nolint:lll
+var collectorsTemplate = template.Must(template.New("collectors").Funcs(template.FuncMap{
+	"seconds": func(s float64) string { return fmt.Sprintf("%.3fs", s) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>windows_exporter collectors</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+.success { color: #080; }
+.failure, .timeout, .unavailable { color: #c00; }
+</style>
+</head>
+<body>
+<h1>windows_exporter collectors</h1>
+<p><a href="?format=json">JSON</a></p>
+<table>
+<tr><th>Collector</th><th>Status</th><th>Last run</th><th>Duration</th><th>Metrics</th><th>Runs</th><th>Failures</th><th>Circuit breaker</th><th>Last error</th><th>Failure history</th></tr>
+{{- range . }}
+<tr>
+<td>{{ .Name }}</td>
+{{- if not .Available }}
+<td class="unavailable">build failed</td><td></td><td></td><td></td><td></td><td></td><td>{{ .CircuitBreaker }}</td><td>{{ .BuildError }}</td><td></td>
+{{- else if not .LastRun }}
+<td>not run yet</td><td></td><td></td><td></td><td>0</td><td>0</td><td>{{ .CircuitBreaker }}</td><td></td><td></td>
+{{- else }}
+<td class="{{ .LastRun.Outcome }}">{{ .LastRun.Outcome }}</td>
+<td>{{ .LastRun.Time.Format "2006-01-02 15:04:05 MST" }}</td>
+<td>{{ seconds .LastRun.DurationSeconds }}</td>
+<td>{{ .LastRun.Metrics }}</td>
+<td>{{ .Runs }}</td>
+<td>{{ .Failures }}{{ if .ConsecutiveFailures }} ({{ .ConsecutiveFailures }} consecutive){{ end }}</td>
+<td>{{ .CircuitBreaker }}</td>
+<td>{{ .LastRun.Error }}</td>
+<td>{{ range .FailureHistory }}{{ .Time.Format "2006-01-02 15:04:05" }} {{ .Outcome }}: {{ .Error }}<br>{{ end }}</td>
+{{- end }}
+</tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
+
+type CollectorsHandler struct {
+	collectors *collector.MetricCollectors
+}
+
+// Interface guard.
+var _ http.Handler = (*CollectorsHandler)(nil)
+
+// NewCollectorsHandler returns a handler, which lists the enabled collectors with the outcome of their last run,
+// their last error and their recent failures. The list is rendered as HTML, unless JSON is requested by the
+// format=json query parameter or the Accept header.
+func NewCollectorsHandler(collectors *collector.MetricCollectors) CollectorsHandler {
+	return CollectorsHandler{collectors: collectors}
+}
+
+func (h CollectorsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	statuses := h.collectors.Status()
+
+	if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+
+		err := json.NewEncoder(w).Encode(struct {
+			Collectors []collectorstatus.Status `json:"collectors"`
+		}{statuses})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("error encoding JSON: %s", err), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := collectorsTemplate.Execute(w, statuses); err != nil {
+		http.Error(w, fmt.Sprintf("error rendering template: %s", err), http.StatusInternalServerError)
+	}
+}
@@ -1,9 +1,15 @@
 package httphandler
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
+
+	"github.com/prometheus-community/windows_exporter/internal/health"
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
 )
 
+// HealthHandler reports the liveness of windows_exporter. It succeeds as long as the HTTP server is serving.
 type HealthHandler struct{}
 
 // Interface guard.
@@ -17,3 +23,33 @@ func (h HealthHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	_, _ = w.Write([]byte(`{"status":"ok"}`))
 }
+
+// ReadinessHandler reports the readiness of windows_exporter, which is derived from the status of the collectors.
+type ReadinessHandler struct {
+	collectors *collector.MetricCollectors
+	rules      health.Rules
+}
+
+// Interface guard.
+var _ http.Handler = (*ReadinessHandler)(nil)
+
+// NewReadinessHandler returns a handler, which evaluates the rules against the status of the collectors.
+// The handler responds with 503 Service Unavailable, if windows_exporter is unhealthy. A degraded
+// windows_exporter is still ready, as the remaining collectors are serving metrics.
+func NewReadinessHandler(collectors *collector.MetricCollectors, rules health.Rules) ReadinessHandler {
+	return ReadinessHandler{collectors: collectors, rules: rules}
+}
+
+func (h ReadinessHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	report := health.Evaluate(h.collectors.Status(), h.rules)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if report.Status == health.Unhealthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		http.Error(w, fmt.Sprintf("error encoding JSON: %s", err), http.StatusInternalServerError)
+	}
+}
@@ -1,18 +1,23 @@
 package httphandler
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus-community/windows_exporter/internal/sink"
 	"github.com/prometheus-community/windows_exporter/pkg/collector"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/collectors/version"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
 )
 
 // Interface guard.
@@ -25,16 +30,32 @@ type MetricsHTTPHandler struct {
 	// exporterMetricsRegistry is a separate registry for the metrics about
 	// the exporter itself.
 	exporterMetricsRegistry *prometheus.Registry
+	// exporterMetricsRegisterer attaches the const labels to the metrics registered at exporterMetricsRegistry.
+	exporterMetricsRegisterer prometheus.Registerer
 
 	logger        *slog.Logger
 	options       Options
 	concurrencyCh chan struct{}
+	coalescer     *coalescer
+
+	// collectorNamesMu guards options.CollectorNames against SetCollectorNames.
+	collectorNamesMu sync.RWMutex
 }
 
 type Options struct {
 	DisableExporterMetrics bool
 	TimeoutMargin          float64
 	MaxRequests            int
+	// CoalesceWindow is the duration in which scrapes of the same collectors share a single collection run.
+	// 0 disables the coalescing of scrapes.
+	CoalesceWindow time.Duration
+	// Collectors are additional collectors, which are exposed alongside the collectors of windows_exporter.
+	Collectors []prometheus.Collector
+	// ConstLabels are attached to every exposed metric, including the metrics about the exporter itself.
+	ConstLabels prometheus.Labels
+	// CollectorNames restricts the handler to a subset of the enabled collectors. The collect[] query parameter
+	// can narrow the subset further. nil serves all enabled collectors.
+	CollectorNames []string
 }
 
 func New(logger *slog.Logger, metricCollectors *collector.MetricCollectors, options *Options) *MetricsHTTPHandler {
@@ -53,18 +74,34 @@ func New(logger *slog.Logger, metricCollectors *collector.MetricCollectors, opti
 		concurrencyCh:    make(chan struct{}, options.MaxRequests),
 	}
 
+	if options.CoalesceWindow > 0 {
+		// The concurrency limit applies to the collection runs, so scrapes joining a run are not rejected.
+		var limit chan struct{}
+		if options.MaxRequests > 0 {
+			limit = handler.concurrencyCh
+		}
+
+		handler.coalescer = newCoalescer(options.CoalesceWindow, limit)
+	}
+
 	if !options.DisableExporterMetrics {
 		handler.exporterMetricsRegistry = prometheus.NewRegistry()
-		handler.exporterMetricsRegistry.MustRegister(
-			collectors.NewBuildInfoCollector(),
-			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
-			collectors.NewGoCollector(),
-		)
+		handler.exporterMetricsRegisterer = prometheus.WrapRegistererWith(options.ConstLabels, handler.exporterMetricsRegistry)
+		handler.exporterMetricsRegisterer.MustRegister(exporterCollectors()...)
 	}
 
 	return handler
 }
 
+// exporterCollectors returns the collectors for the metrics about the exporter itself.
+func exporterCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		collectors.NewBuildInfoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewGoCollector(),
+	}
+}
+
 func (c *MetricsHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	logger := c.logger.With(
 		slog.Any("remote", r.RemoteAddr),
@@ -110,65 +147,224 @@ func (c *MetricsHTTPHandler) getScrapeTimeout(logger *slog.Logger, r *http.Reque
 }
 
 func (c *MetricsHTTPHandler) handlerFactory(logger *slog.Logger, scrapeTimeout time.Duration, requestedCollectors []string) (http.Handler, error) {
-	reg := prometheus.NewRegistry()
+	requestedCollectors, err := c.selectCollectors(requestedCollectors)
+	if err != nil {
+		return nil, err
+	}
 
-	var metricCollectors *collector.MetricCollectors
-	if len(requestedCollectors) == 0 {
-		metricCollectors = c.metricCollectors
-	} else {
-		filteredCollectors := make(collector.Map)
+	reg, err := c.newRegistry(scrapeTimeout, requestedCollectors, c.options.ConstLabels)
+	if err != nil {
+		return nil, err
+	}
 
-		for _, name := range requestedCollectors {
-			metricCollector, ok := c.metricCollectors.Collectors[name]
-			if !ok {
-				return nil, fmt.Errorf("couldn't find collector %s", name)
-			}
+	if c.coalescer == nil {
+		return c.withConcurrencyLimit(c.newPromHandler(logger, reg).ServeHTTP), nil
+	}
 
-			filteredCollectors[name] = metricCollector
-		}
+	key := coalesceKey(requestedCollectors, scrapeTimeout)
 
-		metricCollectors = &collector.MetricCollectors{
-			Collectors:       filteredCollectors,
-			MISession:        c.metricCollectors.MISession,
-			PerfCounterQuery: c.metricCollectors.PerfCounterQuery,
-		}
-	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		metricFamilies, err := c.coalescer.gather(key, reg)
+		if errors.Is(err, errTooManyRequests) {
+			writeTooManyRequests(w)
 
-	reg.MustRegister(version.NewCollector("windows_exporter"))
+			return
+		}
 
-	if err := reg.Register(metricCollectors.NewPrometheusCollector(scrapeTimeout, c.logger)); err != nil {
-		return nil, fmt.Errorf("couldn't register Prometheus collector: %w", err)
-	}
+		c.newPromHandler(logger, prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return metricFamilies, err
+		})).ServeHTTP(w, r)
+	}), nil
+}
 
-	var handler http.Handler
-	if c.exporterMetricsRegistry != nil {
-		handler = promhttp.HandlerFor(
-			prometheus.Gatherers{c.exporterMetricsRegistry, reg},
+// newPromHandler returns the handler, which exposes the metrics of gatherer and the metrics about the exporter itself.
+func (c *MetricsHTTPHandler) newPromHandler(logger *slog.Logger, gatherer prometheus.Gatherer) http.Handler {
+	if c.exporterMetricsRegistry == nil {
+		return promhttp.HandlerFor(
+			gatherer,
 			promhttp.HandlerOpts{
 				ErrorLog:            slog.NewLogLogger(logger.Handler(), slog.LevelError),
 				ErrorHandling:       promhttp.ContinueOnError,
 				MaxRequestsInFlight: c.options.MaxRequests,
-				Registry:            c.exporterMetricsRegistry,
 			},
 		)
+	}
 
-		// Note that we have to use h.exporterMetricsRegistry here to
-		// use the same promhttp metrics for all expositions.
-		handler = promhttp.InstrumentMetricHandler(
-			c.exporterMetricsRegistry, handler,
+	handler := promhttp.HandlerFor(
+		prometheus.Gatherers{c.exporterMetricsRegistry, gatherer},
+		promhttp.HandlerOpts{
+			ErrorLog:            slog.NewLogLogger(logger.Handler(), slog.LevelError),
+			ErrorHandling:       promhttp.ContinueOnError,
+			MaxRequestsInFlight: c.options.MaxRequests,
+			Registry:            c.exporterMetricsRegisterer,
+		},
+	)
+
+	// Note that we have to use h.exporterMetricsRegistry here to
+	// use the same promhttp metrics for all expositions.
+	return promhttp.InstrumentMetricHandler(
+		c.exporterMetricsRegisterer, handler,
+	)
+}
+
+// Gatherer returns a prometheus.Gatherer, which collects the same metrics as a request of the metrics endpoint,
+// including the metrics about the exporter itself. It is used by the push modes.
+// A scrapeTimeout of 0 uses the default scrape timeout.
+func (c *MetricsHTTPHandler) Gatherer(scrapeTimeout time.Duration) prometheus.Gatherer {
+	if scrapeTimeout <= 0 {
+		scrapeTimeout = time.Duration((defaultScrapeTimeout - c.options.TimeoutMargin) * float64(time.Second))
+	}
+
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		gatherer, err := c.newGatherer(scrapeTimeout, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return gatherer.Gather()
+	})
+}
+
+// gather collects the metrics for a request of an endpoint, which renders the metrics in another format than
+// the Prometheus exposition formats. Like a request of the metrics endpoint, concurrent requests are coalesced.
+// If the request is invalid or rejected, an error response is written and false is returned.
+func (c *MetricsHTTPHandler) gather(w http.ResponseWriter, r *http.Request) ([]*dto.MetricFamily, bool) {
+	logger := c.logger.With(
+		slog.Any("remote", r.RemoteAddr),
+		slog.Any("correlation_id", uuid.New().String()),
+	)
+
+	scrapeTimeout := c.getScrapeTimeout(logger, r)
+
+	var reg *prometheus.Registry
+
+	requestedCollectors, err := c.selectCollectors(r.URL.Query()["collect[]"])
+	if err == nil {
+		reg, err = c.newRegistry(scrapeTimeout, requestedCollectors, c.options.ConstLabels)
+	}
+
+	if err != nil {
+		logger.Warn("Couldn't create filtered metrics handler",
+			slog.Any("err", err),
 		)
+
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(fmt.Sprintf("Couldn't create filtered metrics handler: %s", err)))
+
+		return nil, false
+	}
+
+	var gatherer prometheus.Gatherer = reg
+
+	if c.coalescer != nil {
+		metricFamilies, err := c.coalescer.gather(coalesceKey(requestedCollectors, scrapeTimeout), reg)
+		if errors.Is(err, errTooManyRequests) {
+			writeTooManyRequests(w)
+
+			return nil, false
+		}
+
+		gatherer = prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return metricFamilies, err
+		})
+	}
+
+	if c.exporterMetricsRegistry != nil {
+		gatherer = prometheus.Gatherers{c.exporterMetricsRegistry, gatherer}
+	}
+
+	return sink.Gather(logger, gatherer), true
+}
+
+// withRequestLimit limits the concurrent requests of an endpoint like the metrics endpoint.
+// If requests are coalesced, the limit applies to the collection runs instead.
+func (c *MetricsHTTPHandler) withRequestLimit(next http.HandlerFunc) http.HandlerFunc {
+	if c.coalescer != nil {
+		return next
+	}
+
+	return c.withConcurrencyLimit(next)
+}
+
+// newGatherer returns a gatherer for a single scrape of the requested collectors and the metrics about the exporter itself.
+func (c *MetricsHTTPHandler) newGatherer(scrapeTimeout time.Duration, requestedCollectors []string) (prometheus.Gatherer, error) {
+	requestedCollectors, err := c.selectCollectors(requestedCollectors)
+	if err != nil {
+		return nil, err
+	}
+
+	reg, err := c.newRegistry(scrapeTimeout, requestedCollectors, c.options.ConstLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.exporterMetricsRegistry == nil {
+		return reg, nil
+	}
+
+	return prometheus.Gatherers{c.exporterMetricsRegistry, reg}, nil
+}
+
+// SetCollectorNames replaces the collectors of the handler, see Options.CollectorNames.
+// It is called, after a reload changed the enabled collectors.
+func (c *MetricsHTTPHandler) SetCollectorNames(collectorNames []string) {
+	c.collectorNamesMu.Lock()
+	defer c.collectorNamesMu.Unlock()
+
+	c.options.CollectorNames = collectorNames
+}
+
+// selectCollectors returns the collectors of a scrape. The requested collectors have to be a subset of the
+// collectors of the handler. Without requested collectors, all collectors of the handler are scraped.
+func (c *MetricsHTTPHandler) selectCollectors(requestedCollectors []string) ([]string, error) {
+	c.collectorNamesMu.RLock()
+	collectorNames := c.options.CollectorNames
+	c.collectorNamesMu.RUnlock()
+
+	if collectorNames == nil {
+		return requestedCollectors, nil
+	}
+
+	if len(requestedCollectors) == 0 {
+		return collectorNames, nil
+	}
+
+	for _, name := range requestedCollectors {
+		if !slices.Contains(collectorNames, name) {
+			return nil, fmt.Errorf("collector %s is not served by this endpoint", name)
+		}
+	}
+
+	return requestedCollectors, nil
+}
+
+// newRegistry returns a registry with the collectors of a single scrape. The const labels are attached to all metrics.
+func (c *MetricsHTTPHandler) newRegistry(scrapeTimeout time.Duration, requestedCollectors []string, constLabels prometheus.Labels) (*prometheus.Registry, error) {
+	var (
+		prometheusCollector *collector.Prometheus
+		err                 error
+	)
+
+	if len(requestedCollectors) == 0 {
+		prometheusCollector = c.metricCollectors.NewPrometheusCollector(scrapeTimeout, c.logger)
 	} else {
-		handler = promhttp.HandlerFor(
-			reg,
-			promhttp.HandlerOpts{
-				ErrorLog:            slog.NewLogLogger(logger.Handler(), slog.LevelError),
-				ErrorHandling:       promhttp.ContinueOnError,
-				MaxRequestsInFlight: c.options.MaxRequests,
-			},
-		)
+		prometheusCollector, err = c.metricCollectors.NewPrometheusCollectorFor(requestedCollectors, scrapeTimeout, c.logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	reg := prometheus.NewRegistry()
+	registerer := prometheus.WrapRegistererWith(constLabels, reg)
+
+	registerer.MustRegister(version.NewCollector("windows_exporter"))
+	registerer.MustRegister(c.options.Collectors...)
+
+	if err = registerer.Register(prometheusCollector); err != nil {
+		return nil, fmt.Errorf("couldn't register Prometheus collector: %w", err)
 	}
 
-	return c.withConcurrencyLimit(handler.ServeHTTP), nil
+	return reg, nil
 }
 
 func (c *MetricsHTTPHandler) withConcurrencyLimit(next http.HandlerFunc) http.HandlerFunc {
@@ -181,8 +377,7 @@ func (c *MetricsHTTPHandler) withConcurrencyLimit(next http.HandlerFunc) http.Ha
 		case c.concurrencyCh <- struct{}{}:
 			defer func() { <-c.concurrencyCh }()
 		default:
-			w.WriteHeader(http.StatusServiceUnavailable)
-			_, _ = w.Write([]byte("Too many concurrent requests"))
+			writeTooManyRequests(w)
 
 			return
 		}
@@ -190,3 +385,8 @@ func (c *MetricsHTTPHandler) withConcurrencyLimit(next http.HandlerFunc) http.Ha
 		next(w, r)
 	}
 }
+
+func writeTooManyRequests(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_, _ = w.Write([]byte("Too many concurrent requests"))
+}
@@ -0,0 +1,103 @@
+package httphandler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+// countingCollector sends a single metric named after the collector and counts its collections.
+type countingCollector struct {
+	desc        *prometheus.Desc
+	collections atomic.Int32
+}
+
+func newCountingCollector(name string) *countingCollector {
+	return &countingCollector{desc: prometheus.NewDesc("windows_"+name+"_test", "Test value.", nil, nil)}
+}
+
+func (c *countingCollector) Build(*slog.Logger, *mi.Session) error { return nil }
+
+func (c *countingCollector) Close(*slog.Logger) error { return nil }
+
+func (c *countingCollector) GetName() string { return "test" }
+
+func (c *countingCollector) GetPerfCounter(*slog.Logger) ([]string, error) { return nil, nil }
+
+func (c *countingCollector) Collect(_ context.Context, _ *types.ScrapeContext, _ *slog.Logger, ch chan<- prometheus.Metric) error {
+	c.collections.Add(1)
+
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1)
+
+	return nil
+}
+
+func serve(handler http.Handler, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+	return w
+}
+
+func TestFormatHandlersCollectorNames(t *testing.T) {
+	t.Parallel()
+
+	cpu, memory := newCountingCollector("cpu"), newCountingCollector("memory")
+
+	// The handler of a metrics endpoint, which serves the cpu collector only.
+	c := New(slog.New(slog.NewTextHandler(io.Discard, nil)), collector.New(collector.Map{"cpu": cpu, "memory": memory}), &Options{
+		DisableExporterMetrics: true,
+		MaxRequests:            5,
+		CollectorNames:         []string{"cpu"},
+	})
+
+	for _, handler := range []http.Handler{c.JSONHandler(), c.InfluxHandler()} {
+		w := serve(handler, "/")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), "windows_cpu_test")
+		require.NotContains(t, w.Body.String(), "windows_memory_test")
+
+		w = serve(handler, "/?collect[]=memory")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "collector memory is not served by this endpoint")
+	}
+
+	require.Zero(t, memory.collections.Load())
+}
+
+func TestFormatHandlersCoalesce(t *testing.T) {
+	t.Parallel()
+
+	cpu := newCountingCollector("cpu")
+
+	c := New(slog.New(slog.NewTextHandler(io.Discard, nil)), collector.New(collector.Map{"cpu": cpu}), &Options{
+		MaxRequests:    5,
+		CoalesceWindow: time.Hour,
+	})
+
+	// The requests of the metrics endpoint and the other formats share a collection run within the coalesce window.
+	require.Equal(t, http.StatusOK, serve(c, "/?collect[]=cpu").Code)
+
+	w := serve(c.JSONHandler(), "/?collect[]=cpu")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "windows_cpu_test")
+	// The metrics about the exporter itself are included like on the metrics endpoint.
+	require.Contains(t, w.Body.String(), "go_goroutines")
+
+	w = serve(c.InfluxHandler(), "/?collect[]=cpu")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "windows_cpu_test")
+
+	require.Equal(t, int32(1), cpu.collections.Load())
+}
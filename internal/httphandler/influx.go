@@ -0,0 +1,35 @@
+package httphandler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/influx"
+)
+
+// InfluxHandler returns a handler, which renders the metrics in the InfluxDB line protocol, e.g. for the http input
+// plugin of Telegraf. The precision of the timestamps is set by the precision query parameter and defaults to ns.
+// Like the metrics endpoint, the handler supports the collect[] query parameter and coalesces concurrent requests.
+func (c *MetricsHTTPHandler) InfluxHandler() http.Handler {
+	return c.withRequestLimit(func(w http.ResponseWriter, r *http.Request) {
+		precision := r.URL.Query().Get("precision")
+		if precision == "" {
+			precision = influx.DefaultPrecision
+		}
+
+		if err := influx.ValidatePrecision(precision); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(err.Error()))
+
+			return
+		}
+
+		families, ok := c.gather(w, r)
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write(influx.AppendLines(nil, families, time.Now(), precision))
+	})
+}
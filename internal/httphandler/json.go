@@ -0,0 +1,29 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/prometheus-community/windows_exporter/internal/metricsjson"
+)
+
+// JSONHandler returns a handler, which renders the metrics and the outcome of the collectors as JSON,
+// e.g. for PowerShell scripts. Like the metrics endpoint, the handler supports the collect[] query parameter
+// and coalesces concurrent requests.
+func (c *MetricsHTTPHandler) JSONHandler() http.Handler {
+	return c.withRequestLimit(func(w http.ResponseWriter, r *http.Request) {
+		families, ok := c.gather(w, r)
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(metricsjson.New(families)); err != nil {
+			c.logger.Warn("failed to write JSON response",
+				slog.Any("err", err),
+			)
+		}
+	})
+}
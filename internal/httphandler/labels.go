@@ -0,0 +1,43 @@
+package httphandler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors/version"
+	"github.com/prometheus/common/model"
+)
+
+// ValidateConstLabels validates the names of the const labels of options and checks the descriptors of the collectors
+// about the exporter itself for labels with the same name. The Windows collectors have no descriptors. Their metrics,
+// which already use a label with the name of a const label, are rejected on each scrape by the registerer of newRegistry.
+func ValidateConstLabels(options Options) error {
+	errs := make([]error, 0)
+
+	for name := range options.ConstLabels {
+		if !model.LabelName(name).IsValidLegacy() || strings.HasPrefix(name, model.ReservedLabelPrefix) {
+			errs = append(errs, fmt.Errorf("invalid const label name %q", name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	collectors := append([]prometheus.Collector{version.NewCollector("windows_exporter")}, options.Collectors...)
+	if !options.DisableExporterMetrics {
+		collectors = append(collectors, exporterCollectors()...)
+	}
+
+	registerer := prometheus.WrapRegistererWith(options.ConstLabels, prometheus.NewRegistry())
+
+	for _, collector := range collectors {
+		if err := registerer.Register(collector); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,60 @@
+package httphandler
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+// uncheckedCollector has no descriptors like the Windows collectors.
+type uncheckedCollector struct {
+	prometheus.Collector
+}
+
+func (c uncheckedCollector) Describe(chan<- *prometheus.Desc) {}
+
+func TestValidateConstLabels(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateConstLabels(Options{ConstLabels: prometheus.Labels{"env": "prod"}}))
+
+	err := ValidateConstLabels(Options{ConstLabels: prometheus.Labels{"__env": "prod", "data-center": "fra1"}})
+	require.ErrorContains(t, err, `invalid const label name "__env"`)
+	require.ErrorContains(t, err, `invalid const label name "data-center"`)
+
+	// The descriptors of the collectors about the exporter itself are checked.
+	volume := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_volume", Help: "help"}, []string{"volume"})
+
+	require.Error(t, ValidateConstLabels(Options{
+		ConstLabels:            prometheus.Labels{"volume": "C:"},
+		Collectors:             []prometheus.Collector{volume},
+		DisableExporterMetrics: true,
+	}))
+	require.Error(t, ValidateConstLabels(Options{ConstLabels: prometheus.Labels{"version": "1"}}))
+}
+
+func TestConstLabelsCollision(t *testing.T) {
+	t.Parallel()
+
+	volume := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_volume", Help: "help"}, []string{"volume", "env"})
+	volume.WithLabelValues("C:", "test").Set(1)
+
+	cpu := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_cpu", Help: "help"}, []string{"core"})
+	cpu.WithLabelValues("0").Set(1)
+
+	reg := prometheus.NewRegistry()
+	prometheus.WrapRegistererWith(prometheus.Labels{"env": "prod"}, reg).MustRegister(
+		uncheckedCollector{volume},
+		uncheckedCollector{cpu},
+	)
+
+	// The metrics, which already use the label, are rejected. The other metrics are exposed with the const labels.
+	families, err := reg.Gather()
+	require.ErrorContains(t, err, `duplicate label names in constant and variable labels for metric "test_volume"`)
+	require.Len(t, families, 1)
+	require.Equal(t, "test_cpu", families[0].GetName())
+	require.Len(t, families[0].GetMetric(), 1)
+	require.Equal(t, "env", families[0].GetMetric()[0].GetLabel()[1].GetName())
+	require.Equal(t, "prod", families[0].GetMetric()[0].GetLabel()[1].GetValue())
+}
@@ -0,0 +1,201 @@
+// Package influx renders metrics in the InfluxDB line protocol and writes them to the InfluxDB v2 write API.
+package influx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/sink"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/config"
+	"github.com/prometheus/common/version"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultInterval = time.Minute
+	defaultTimeout  = 10 * time.Second
+)
+
+// Config is the configuration of the InfluxDB push mode.
+type Config struct {
+	// URL of the InfluxDB server, e.g. https://influxdb:8086.
+	URL string `yaml:"url"`
+	// Org is the organization, which owns the bucket.
+	Org string `yaml:"org"`
+	// Bucket receives the metrics.
+	Bucket string `yaml:"bucket"`
+	// Token is the API token with write permission for the bucket.
+	Token config.Secret `yaml:"token"`
+	// TokenFile is a file, which contains the API token. It is read on every request.
+	TokenFile string `yaml:"token_file"`
+	// Precision of the timestamps. One of ns, us, ms or s. Defaults to ns.
+	Precision string `yaml:"precision"`
+	// Interval between two writes. Defaults to 1m.
+	Interval time.Duration `yaml:"interval"`
+	// Timeout of a single request. Defaults to 10s.
+	Timeout time.Duration `yaml:"timeout"`
+	// HTTPClientConfig configures TLS and proxies.
+	HTTPClientConfig config.HTTPClientConfig `yaml:",inline"`
+}
+
+// UnmarshalYAML applies the defaults and validates the configuration.
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	type plain Config
+
+	*c = Config{
+		Precision:        DefaultPrecision,
+		HTTPClientConfig: config.DefaultHTTPClientConfig,
+	}
+
+	if err := value.Decode((*plain)(c)); err != nil {
+		return err
+	}
+
+	if _, err := url.ParseRequestURI(c.URL); err != nil {
+		return fmt.Errorf("invalid influxdb url %q: %w", c.URL, err)
+	}
+
+	if c.Org == "" || c.Bucket == "" {
+		return errors.New("influxdb org and bucket must be set")
+	}
+
+	if c.Token != "" && c.TokenFile != "" {
+		return errors.New("at most one of influxdb token and token_file must be set")
+	}
+
+	if (c.Token != "" || c.TokenFile != "") &&
+		(c.HTTPClientConfig.BasicAuth != nil || c.HTTPClientConfig.Authorization != nil || c.HTTPClientConfig.OAuth2 != nil) {
+		return errors.New("influxdb token can't be combined with basic_auth, authorization or oauth2")
+	}
+
+	if err := ValidatePrecision(c.Precision); err != nil {
+		return err
+	}
+
+	if c.Token != "" || c.TokenFile != "" {
+		c.HTTPClientConfig.Authorization = &config.Authorization{
+			Type:            "Token",
+			Credentials:     c.Token,
+			CredentialsFile: c.TokenFile,
+		}
+	}
+
+	return c.HTTPClientConfig.Validate()
+}
+
+// Enabled returns true, if an InfluxDB server is configured.
+func (c Config) Enabled() bool {
+	return c.URL != ""
+}
+
+// Interface guard.
+var _ prometheus.Collector = (*Pusher)(nil)
+
+// Pusher periodically gathers metrics and writes them to InfluxDB.
+type Pusher struct {
+	logger   *slog.Logger
+	config   Config
+	client   *http.Client
+	writeURL string
+
+	stats *sink.Stats
+}
+
+// New returns a Pusher for the configuration. The Pusher has to be started by Run.
+func New(logger *slog.Logger, cfg Config) (*Pusher, error) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	client, err := config.NewClientFromConfig(cfg.HTTPClientConfig, "influxdb")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	client.Timeout = cfg.Timeout
+
+	query := url.Values{}
+	query.Set("org", cfg.Org)
+	query.Set("bucket", cfg.Bucket)
+	query.Set("precision", cfg.Precision)
+
+	return &Pusher{
+		logger:   logger,
+		config:   cfg,
+		client:   client,
+		writeURL: strings.TrimSuffix(cfg.URL, "/") + "/api/v2/write?" + query.Encode(),
+		stats:    sink.NewStats("influxdb", "requests", "InfluxDB", nil),
+	}, nil
+}
+
+// Run writes the metrics of gatherer on every interval until ctx is done.
+func (p *Pusher) Run(ctx context.Context, gatherer prometheus.Gatherer) {
+	sink.Run(ctx, p.config.Interval, func(ctx context.Context) {
+		p.Push(ctx, gatherer)
+	})
+}
+
+// Push gathers the metrics once and writes them to InfluxDB.
+func (p *Pusher) Push(ctx context.Context, gatherer prometheus.Gatherer) {
+	families := sink.Gather(p.logger, gatherer)
+
+	if err := p.write(ctx, AppendLines(nil, families, time.Now(), p.config.Precision)); err != nil {
+		p.stats.Failure()
+
+		p.logger.Warn("write to influxdb failed",
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	p.stats.Success()
+}
+
+func (p *Pusher) write(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.writeURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("User-Agent", "windows_exporter/"+version.Version)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+
+	return fmt.Errorf("server returned HTTP status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+}
+
+func (p *Pusher) Describe(ch chan<- *prometheus.Desc) {
+	p.stats.Describe(ch)
+}
+
+func (p *Pusher) Collect(ch chan<- prometheus.Metric) {
+	p.stats.Collect(ch)
+}
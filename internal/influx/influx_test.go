@@ -0,0 +1,94 @@
+package influx
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPusher(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		bodies []string
+		status = http.StatusNoContent
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		require.Equal(t, "/api/v2/write", req.URL.Path)
+		require.Equal(t, "my-org", req.URL.Query().Get("org"))
+		require.Equal(t, "windows", req.URL.Query().Get("bucket"))
+		require.Equal(t, "s", req.URL.Query().Get("precision"))
+		require.Equal(t, "Token secret", req.Header.Get("Authorization"))
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		bodies = append(bodies, string(body))
+
+		w.WriteHeader(status)
+	}))
+
+	t.Cleanup(server.Close)
+
+	var cfg Config
+
+	require.NoError(t, yaml.Unmarshal([]byte(`
+url: `+server.URL+`/
+org: my-org
+bucket: windows
+token: secret
+precision: s
+`), &cfg))
+
+	pusher, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+	require.NoError(t, err)
+
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test", Help: "help"})
+	gauge.Set(42)
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(gauge)
+
+	pusher.Push(context.Background(), reg)
+
+	mu.Lock()
+	status = http.StatusUnauthorized
+	mu.Unlock()
+
+	pusher.Push(context.Background(), reg)
+
+	require.Len(t, bodies, 2)
+	require.True(t, strings.HasPrefix(bodies[0], "test gauge=42 "), bodies[0])
+	require.Equal(t, uint64(1), pusher.stats.Sent())
+	require.Equal(t, uint64(1), pusher.stats.Failed())
+}
+
+func TestConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []string{
+		"org: o\nbucket: b",
+		"url: http://localhost:8086\nbucket: b",
+		"url: http://localhost:8086\norg: o\nbucket: b\nprecision: m",
+		"url: http://localhost:8086\norg: o\nbucket: b\ntoken: a\ntoken_file: b",
+		"url: http://localhost:8086\norg: o\nbucket: b\ntoken: a\nbasic_auth:\n  username: u",
+	} {
+		var cfg Config
+
+		require.Error(t, yaml.Unmarshal([]byte(config), &cfg), config)
+	}
+}
@@ -0,0 +1,178 @@
+package influx
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Precisions maps the precisions of the InfluxDB write API to the duration of a timestamp unit.
+var Precisions = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+// DefaultPrecision is the precision of the timestamps, if none is configured.
+const DefaultPrecision = "ns"
+
+// ValidatePrecision returns an error, if precision isn't supported by the InfluxDB write API.
+func ValidatePrecision(precision string) error {
+	if _, ok := Precisions[precision]; !ok {
+		return fmt.Errorf("unknown precision %q, must be one of ns, us, ms or s", precision)
+	}
+
+	return nil
+}
+
+var (
+	measurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `, "\n", `\n`)
+	// keyEscaper escapes tag keys, tag values and field keys. Backslashes are escaped, too, because a
+	// trailing backslash would escape the following separator.
+	keyEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `=`, `\=`, ` `, `\ `, "\n", `\n`)
+)
+
+type field struct {
+	key   string
+	value float64
+}
+
+// AppendLines appends the metric families to b in the InfluxDB line protocol and returns the extended buffer.
+// Each metric becomes a line with the metric name as measurement and the labels as tags. The fields follow
+// the prometheus input plugin of Telegraf:
+//
+//   - counters have the field counter, gauges the field gauge and untyped metrics the field value.
+//   - summaries have the fields sum, count and a field per quantile.
+//   - histograms have the fields sum, count and a field per upper bound of the buckets.
+//
+// Metrics without a timestamp get the timestamp now. The timestamps are truncated to precision, which has
+// to be a key of Precisions. Fields with the values NaN or ±Inf aren't supported by InfluxDB and are skipped.
+func AppendLines(b []byte, families []*dto.MetricFamily, now time.Time, precision string) []byte {
+	unit, ok := Precisions[precision]
+	if !ok {
+		unit = time.Nanosecond
+	}
+
+	for _, family := range families {
+		measurement := measurementEscaper.Replace(family.GetName())
+
+		for _, metric := range family.GetMetric() {
+			timestamp := now
+			if metric.TimestampMs != nil {
+				timestamp = time.UnixMilli(metric.GetTimestampMs())
+			}
+
+			b = appendLine(b, measurement, metric.GetLabel(), metricFields(family.GetType(), metric), timestamp.UnixNano()/int64(unit))
+		}
+	}
+
+	return b
+}
+
+func metricFields(metricType dto.MetricType, metric *dto.Metric) []field {
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		return []field{{key: "counter", value: metric.GetCounter().GetValue()}}
+	case dto.MetricType_GAUGE:
+		return []field{{key: "gauge", value: metric.GetGauge().GetValue()}}
+	case dto.MetricType_UNTYPED:
+		return []field{{key: "value", value: metric.GetUntyped().GetValue()}}
+	case dto.MetricType_SUMMARY:
+		summary := metric.GetSummary()
+		fields := make([]field, 0, len(summary.GetQuantile())+2)
+
+		for _, quantile := range summary.GetQuantile() {
+			fields = append(fields, field{key: formatFloat(quantile.GetQuantile()), value: quantile.GetValue()})
+		}
+
+		return append(fields,
+			field{key: "count", value: float64(summary.GetSampleCount())},
+			field{key: "sum", value: summary.GetSampleSum()},
+		)
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		histogram := metric.GetHistogram()
+		fields := make([]field, 0, len(histogram.GetBucket())+3)
+		hasInf := false
+
+		for _, bucket := range histogram.GetBucket() {
+			if math.IsInf(bucket.GetUpperBound(), +1) {
+				hasInf = true
+			}
+
+			fields = append(fields, field{key: formatFloat(bucket.GetUpperBound()), value: float64(bucket.GetCumulativeCount())})
+		}
+
+		if !hasInf {
+			fields = append(fields, field{key: "+Inf", value: float64(histogram.GetSampleCount())})
+		}
+
+		return append(fields,
+			field{key: "count", value: float64(histogram.GetSampleCount())},
+			field{key: "sum", value: histogram.GetSampleSum()},
+		)
+	default:
+		return nil
+	}
+}
+
+// appendLine appends a single line. Tags are sorted by key, as recommended by InfluxDB. Tags with an empty value
+// aren't allowed by the line protocol and are omitted. Lines without a valid field are skipped.
+func appendLine(b []byte, measurement string, labels []*dto.LabelPair, fields []field, timestamp int64) []byte {
+	fields = slices.DeleteFunc(fields, func(f field) bool {
+		return math.IsNaN(f.value) || math.IsInf(f.value, 0)
+	})
+
+	if len(fields) == 0 {
+		return b
+	}
+
+	b = append(b, measurement...)
+
+	labels = slices.Clone(labels)
+	slices.SortFunc(labels, func(a, b *dto.LabelPair) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+
+	for _, label := range labels {
+		if label.GetValue() == "" {
+			continue
+		}
+
+		b = append(b, ',')
+		b = append(b, keyEscaper.Replace(label.GetName())...)
+		b = append(b, '=')
+		b = append(b, keyEscaper.Replace(label.GetValue())...)
+	}
+
+	for i, f := range fields {
+		if i == 0 {
+			b = append(b, ' ')
+		} else {
+			b = append(b, ',')
+		}
+
+		b = append(b, keyEscaper.Replace(f.key)...)
+		b = append(b, '=')
+		b = strconv.AppendFloat(b, f.value, 'g', -1, 64)
+	}
+
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, timestamp, 10)
+	b = append(b, '\n')
+
+	return b
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, +1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,76 @@
+package influx
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/testutils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendLines(t *testing.T) {
+	t.Parallel()
+
+	families := testutils.Gather(t,
+		prometheus.MustNewConstMetric(
+			prometheus.NewDesc("windows_cpu_time_total", "", []string{"core", "mode"}, nil),
+			prometheus.CounterValue, 1234.5, "0,0", "idle",
+		),
+		prometheus.MustNewConstMetric(
+			prometheus.NewDesc("windows_logical_disk_free_bytes", "", []string{"volume", "label"}, nil),
+			prometheus.GaugeValue, 1e12, `C:\`, "",
+		),
+		prometheus.MustNewConstMetric(
+			prometheus.NewDesc("windows_service_info", "", []string{"display_name"}, nil),
+			prometheus.UntypedValue, 1, "Windows Update",
+		),
+		prometheus.MustNewConstMetric(
+			prometheus.NewDesc("windows_nan", "", nil, nil),
+			prometheus.GaugeValue, math.NaN(),
+		),
+		prometheus.MustNewConstSummary(
+			prometheus.NewDesc("test_summary", "", nil, nil),
+			10, 20, map[float64]float64{0.5: 1.5},
+		),
+		prometheus.MustNewConstHistogram(
+			prometheus.NewDesc("test_histogram", "", nil, nil),
+			6, 12.5, map[float64]uint64{1: 2, 5: 5},
+		),
+	)
+
+	now := time.Unix(1700000000, 123456789)
+
+	require.Equal(t, `test_histogram 1=2,5=5,+Inf=6,count=6,sum=12.5 1700000000123456789
+test_summary 0.5=1.5,count=10,sum=20 1700000000123456789
+windows_cpu_time_total,core=0\,0,mode=idle counter=1234.5 1700000000123456789
+windows_logical_disk_free_bytes,volume=C:\\ gauge=1e+12 1700000000123456789
+windows_service_info,display_name=Windows\ Update value=1 1700000000123456789
+`, string(AppendLines(nil, families, now, DefaultPrecision)))
+}
+
+func TestAppendLinesPrecision(t *testing.T) {
+	t.Parallel()
+
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test", Help: "help"})
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(gauge)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 123456789)
+
+	for precision, expected := range map[string]string{
+		"ns": "test gauge=0 1700000000123456789\n",
+		"us": "test gauge=0 1700000000123456\n",
+		"ms": "test gauge=0 1700000000123\n",
+		"s":  "test gauge=0 1700000000\n",
+	} {
+		require.Equal(t, expected, string(AppendLines(nil, families, now, precision)), precision)
+	}
+
+	require.Error(t, ValidatePrecision("m"))
+}
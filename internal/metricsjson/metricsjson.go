@@ -0,0 +1,251 @@
+// Package metricsjson converts metric families into a JSON document, which can be read by scripts without
+// a parser for the Prometheus text format.
+package metricsjson
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Names of the metrics of the Prometheus collector of windows_exporter, which are summarized per collector.
+const (
+	collectorDurationMetric = "windows_exporter_collector_duration_seconds"
+	collectorSuccessMetric  = "windows_exporter_collector_success"
+	collectorTimeoutMetric  = "windows_exporter_collector_timeout"
+)
+
+// Document is the JSON representation of a scrape.
+type Document struct {
+	// Collectors holds the outcome of the collectors of the scrape, sorted by name.
+	Collectors []Collector `json:"collectors"`
+	// Metrics holds the metric families of the scrape, sorted by name.
+	Metrics []Family `json:"metrics"`
+}
+
+// Collector is the outcome of a single collector.
+type Collector struct {
+	Name            string  `json:"name"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	Success         bool    `json:"success"`
+	Timeout         bool    `json:"timeout"`
+}
+
+// Family is a metric family with its samples.
+type Family struct {
+	Name    string   `json:"name"`
+	Help    string   `json:"help"`
+	Type    string   `json:"type"`
+	Samples []Sample `json:"samples"`
+}
+
+// Sample is a single metric of a family. Value is set for counters, gauges and untyped metrics.
+// Count and Sum are set for summaries and histograms, Quantiles for summaries and Buckets for histograms.
+type Sample struct {
+	Labels      map[string]string `json:"labels"`
+	Value       *Float            `json:"value,omitempty"`
+	Count       *uint64           `json:"count,omitempty"`
+	Sum         *Float            `json:"sum,omitempty"`
+	Quantiles   []Quantile        `json:"quantiles,omitempty"`
+	Buckets     []Bucket          `json:"buckets,omitempty"`
+	TimestampMs *int64            `json:"timestamp_ms,omitempty"`
+}
+
+type Quantile struct {
+	Quantile Float `json:"quantile"`
+	Value    Float `json:"value"`
+}
+
+// Bucket is a histogram bucket. The count is cumulative, like in Prometheus.
+type Bucket struct {
+	UpperBound      Float  `json:"upper_bound"`
+	CumulativeCount uint64 `json:"cumulative_count"`
+}
+
+// Float is a float64, which encodes NaN and ±Inf as the strings "NaN", "+Inf" and "-Inf", as JSON has no
+// representation for them.
+type Float float64
+
+func (f Float) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+
+	switch {
+	case math.IsNaN(v):
+		return []byte(`"NaN"`), nil
+	case math.IsInf(v, +1):
+		return []byte(`"+Inf"`), nil
+	case math.IsInf(v, -1):
+		return []byte(`"-Inf"`), nil
+	default:
+		return strconv.AppendFloat(nil, v, 'g', -1, 64), nil
+	}
+}
+
+func (f *Float) UnmarshalJSON(b []byte) error {
+	switch string(b) {
+	case `"NaN"`:
+		*f = Float(math.NaN())
+	case `"+Inf"`:
+		*f = Float(math.Inf(+1))
+	case `"-Inf"`:
+		*f = Float(math.Inf(-1))
+	default:
+		var v float64
+		if err := json.Unmarshal(b, &v); err != nil {
+			return err
+		}
+
+		*f = Float(v)
+	}
+
+	return nil
+}
+
+// New converts the metric families into a Document. The outcome of the collectors is taken from the
+// windows_exporter_collector_* metrics, which are kept in the metrics as well.
+func New(families []*dto.MetricFamily) Document {
+	doc := Document{
+		Collectors: []Collector{},
+		Metrics:    make([]Family, 0, len(families)),
+	}
+
+	collectors := make(map[string]*Collector)
+
+	for _, family := range families {
+		doc.Metrics = append(doc.Metrics, newFamily(family))
+
+		switch family.GetName() {
+		case collectorDurationMetric, collectorSuccessMetric, collectorTimeoutMetric:
+		default:
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			name := labelValue(metric, "collector")
+			if name == "" {
+				continue
+			}
+
+			collector, ok := collectors[name]
+			if !ok {
+				collector = &Collector{Name: name}
+				collectors[name] = collector
+			}
+
+			value := metric.GetGauge().GetValue()
+
+			switch family.GetName() {
+			case collectorDurationMetric:
+				collector.DurationSeconds = value
+			case collectorSuccessMetric:
+				collector.Success = value == 1
+			case collectorTimeoutMetric:
+				collector.Timeout = value == 1
+			}
+		}
+	}
+
+	for _, collector := range collectors {
+		doc.Collectors = append(doc.Collectors, *collector)
+	}
+
+	sort.Slice(doc.Collectors, func(i, j int) bool {
+		return doc.Collectors[i].Name < doc.Collectors[j].Name
+	})
+
+	sort.Slice(doc.Metrics, func(i, j int) bool {
+		return doc.Metrics[i].Name < doc.Metrics[j].Name
+	})
+
+	return doc
+}
+
+func newFamily(family *dto.MetricFamily) Family {
+	f := Family{
+		Name:    family.GetName(),
+		Help:    family.GetHelp(),
+		Type:    strings.ToLower(family.GetType().String()),
+		Samples: make([]Sample, 0, len(family.GetMetric())),
+	}
+
+	for _, metric := range family.GetMetric() {
+		sample := Sample{
+			Labels:      make(map[string]string, len(metric.GetLabel())),
+			TimestampMs: metric.TimestampMs,
+		}
+
+		for _, label := range metric.GetLabel() {
+			sample.Labels[label.GetName()] = label.GetValue()
+		}
+
+		switch family.GetType() {
+		case dto.MetricType_COUNTER:
+			sample.Value = floatPtr(metric.GetCounter().GetValue())
+		case dto.MetricType_GAUGE:
+			sample.Value = floatPtr(metric.GetGauge().GetValue())
+		case dto.MetricType_UNTYPED:
+			sample.Value = floatPtr(metric.GetUntyped().GetValue())
+		case dto.MetricType_SUMMARY:
+			summary := metric.GetSummary()
+			count := summary.GetSampleCount()
+
+			sample.Count = &count
+			sample.Sum = floatPtr(summary.GetSampleSum())
+			sample.Quantiles = make([]Quantile, 0, len(summary.GetQuantile()))
+
+			for _, quantile := range summary.GetQuantile() {
+				sample.Quantiles = append(sample.Quantiles, Quantile{
+					Quantile: Float(quantile.GetQuantile()),
+					Value:    Float(quantile.GetValue()),
+				})
+			}
+		case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+			histogram := metric.GetHistogram()
+			count := histogram.GetSampleCount()
+
+			sample.Count = &count
+			sample.Sum = floatPtr(histogram.GetSampleSum())
+			sample.Buckets = make([]Bucket, 0, len(histogram.GetBucket())+1)
+			hasInf := false
+
+			for _, bucket := range histogram.GetBucket() {
+				if math.IsInf(bucket.GetUpperBound(), +1) {
+					hasInf = true
+				}
+
+				sample.Buckets = append(sample.Buckets, Bucket{
+					UpperBound:      Float(bucket.GetUpperBound()),
+					CumulativeCount: bucket.GetCumulativeCount(),
+				})
+			}
+
+			if !hasInf {
+				sample.Buckets = append(sample.Buckets, Bucket{UpperBound: Float(math.Inf(+1)), CumulativeCount: count})
+			}
+		}
+
+		f.Samples = append(f.Samples, sample)
+	}
+
+	return f
+}
+
+func labelValue(metric *dto.Metric, name string) string {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+
+	return ""
+}
+
+func floatPtr(v float64) *Float {
+	f := Float(v)
+
+	return &f
+}
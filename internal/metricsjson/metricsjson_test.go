@@ -0,0 +1,89 @@
+package metricsjson
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/prometheus-community/windows_exporter/internal/testutils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	durationDesc := prometheus.NewDesc(collectorDurationMetric, "duration", []string{"collector"}, nil)
+	successDesc := prometheus.NewDesc(collectorSuccessMetric, "success", []string{"collector"}, nil)
+	timeoutDesc := prometheus.NewDesc(collectorTimeoutMetric, "timeout", []string{"collector"}, nil)
+
+	families := testutils.Gather(t,
+		prometheus.MustNewConstMetric(durationDesc, prometheus.GaugeValue, 0.25, "cpu"),
+		prometheus.MustNewConstMetric(successDesc, prometheus.GaugeValue, 1, "cpu"),
+		prometheus.MustNewConstMetric(timeoutDesc, prometheus.GaugeValue, 0, "cpu"),
+		prometheus.MustNewConstMetric(durationDesc, prometheus.GaugeValue, 5, "ad"),
+		prometheus.MustNewConstMetric(successDesc, prometheus.GaugeValue, 0, "ad"),
+		prometheus.MustNewConstMetric(timeoutDesc, prometheus.GaugeValue, 1, "ad"),
+		prometheus.MustNewConstMetric(
+			prometheus.NewDesc("windows_cpu_time_total", "cpu help", []string{"core", "mode"}, nil),
+			prometheus.CounterValue, 12.5, "0,0", "idle",
+		),
+		prometheus.MustNewConstMetric(
+			prometheus.NewDesc("test_nan", "nan help", nil, nil),
+			prometheus.GaugeValue, math.NaN(),
+		),
+		prometheus.MustNewConstSummary(
+			prometheus.NewDesc("test_summary", "summary help", nil, nil),
+			10, 20, map[float64]float64{0.5: 1.5},
+		),
+		prometheus.MustNewConstHistogram(
+			prometheus.NewDesc("test_histogram", "histogram help", nil, nil),
+			6, 12.5, map[float64]uint64{1: 2},
+		),
+	)
+
+	doc := New(families)
+
+	require.Equal(t, []Collector{
+		{Name: "ad", DurationSeconds: 5, Success: false, Timeout: true},
+		{Name: "cpu", DurationSeconds: 0.25, Success: true, Timeout: false},
+	}, doc.Collectors)
+
+	b, err := json.Marshal(doc.Metrics[:4])
+	require.NoError(t, err)
+
+	require.JSONEq(t, `[
+		{"name": "test_histogram", "help": "histogram help", "type": "histogram", "samples": [
+			{"labels": {}, "count": 6, "sum": 12.5, "buckets": [
+				{"upper_bound": 1, "cumulative_count": 2},
+				{"upper_bound": "+Inf", "cumulative_count": 6}
+			]}
+		]},
+		{"name": "test_nan", "help": "nan help", "type": "gauge", "samples": [{"labels": {}, "value": "NaN"}]},
+		{"name": "test_summary", "help": "summary help", "type": "summary", "samples": [
+			{"labels": {}, "count": 10, "sum": 20, "quantiles": [{"quantile": 0.5, "value": 1.5}]}
+		]},
+		{"name": "windows_cpu_time_total", "help": "cpu help", "type": "counter", "samples": [
+			{"labels": {"core": "0,0", "mode": "idle"}, "value": 12.5}
+		]}
+	]`, string(b))
+}
+
+func TestFloatRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, v := range []float64{1.5, 0, math.Inf(+1), math.Inf(-1)} {
+		b, err := json.Marshal(Float(v))
+		require.NoError(t, err)
+
+		var f Float
+
+		require.NoError(t, json.Unmarshal(b, &f))
+		require.Equal(t, v, float64(f)) //nolint:testifylint
+	}
+
+	var f Float
+
+	require.NoError(t, json.Unmarshal([]byte(`"NaN"`), &f))
+	require.True(t, math.IsNaN(float64(f)))
+}
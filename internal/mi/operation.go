@@ -31,6 +31,18 @@ const (
 	OperationFlagsFullRTTI     OperationFlags = 0x0004
 )
 
+// CancellationReason represents the reason for cancelling an operation.
+//
+// https://learn.microsoft.com/en-us/windows/win32/api/mi/ne-mi-mi_cancellationreason
+type CancellationReason uint32
+
+const (
+	CancellationReasonNone        CancellationReason = 0
+	CancellationReasonTimeout     CancellationReason = 1
+	CancellationReasonShutdown    CancellationReason = 2
+	CancellationReasonServiceStop CancellationReason = 3
+)
+
 // Operation represents an operation.
 // https://learn.microsoft.com/en-us/windows/win32/api/mi/ns-mi-mi_operation
 type Operation struct {
@@ -101,12 +113,15 @@ func (o *Operation) Close() error {
 	return nil
 }
 
-func (o *Operation) Cancel() error {
+// Cancel cancels a running operation. The final result of the operation is still delivered.
+//
+// https://learn.microsoft.com/en-us/windows/win32/api/mi/nf-mi-mi_operation_cancel
+func (o *Operation) Cancel(reason CancellationReason) error {
 	if o == nil || o.ft == nil {
 		return ErrNotInitialized
 	}
 
-	r0, _, _ := syscall.SyscallN(o.ft.Close, uintptr(unsafe.Pointer(o)), 0)
+	r0, _, _ := syscall.SyscallN(o.ft.Cancel, uintptr(unsafe.Pointer(o)), uintptr(reason))
 
 	if result := ResultError(r0); !errors.Is(result, MI_RESULT_OK) {
 		return result
@@ -3,6 +3,7 @@
 package mi
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"runtime"
@@ -176,6 +177,17 @@ func (s *Session) QueryInstances(flags OperationFlags, operationOptions *Operati
 func (s *Session) QueryUnmarshal(dst any,
 	flags OperationFlags, operationOptions *OperationOptions,
 	namespaceName Namespace, queryDialect QueryDialect, queryExpression Query,
+) error {
+	return s.QueryUnmarshalContext(context.Background(), dst, flags, operationOptions, namespaceName, queryDialect, queryExpression)
+}
+
+// QueryUnmarshalContext queries for a set of instances based on a query expression.
+// If ctx is done before the query has finished, the operation is cancelled and the error of ctx is returned.
+//
+// https://learn.microsoft.com/en-us/windows/win32/api/mi/nf-mi-mi_session_queryinstances
+func (s *Session) QueryUnmarshalContext(ctx context.Context, dst any,
+	flags OperationFlags, operationOptions *OperationOptions,
+	namespaceName Namespace, queryDialect QueryDialect, queryExpression Query,
 ) error {
 	if s == nil || s.ft == nil {
 		return ErrNotInitialized
@@ -211,10 +223,32 @@ func (s *Session) QueryUnmarshal(dst any,
 	}
 
 	errs := make([]error, 0)
-
-	for err := range errCh {
-		if err != nil {
-			errs = append(errs, err)
+	done := ctx.Done()
+	cancelled := false
+
+	// Wait for the final result. A cancelled operation still delivers its final result.
+loop:
+	for {
+		select {
+		case <-done:
+			// A nil channel blocks forever. The operation is cancelled only once.
+			done = nil
+			cancelled = true
+
+			if err := operation.Cancel(CancellationReasonTimeout); err != nil {
+				errs = append(errs, fmt.Errorf("failed to cancel operation: %w", err))
+			}
+
+			errs = append(errs, ctx.Err())
+		case err, ok := <-errCh:
+			if !ok {
+				break loop
+			}
+
+			// Errors caused by the cancellation are covered by the error of ctx.
+			if err != nil && !cancelled {
+				errs = append(errs, err)
+			}
 		}
 	}
 
@@ -226,7 +260,13 @@ func (s *Session) QueryUnmarshal(dst any,
 
 // Query queries for a set of instances based on a query expression.
 func (s *Session) Query(dst any, namespaceName Namespace, queryExpression Query) error {
-	err := s.QueryUnmarshal(dst, OperationFlagsStandardRTTI, nil, namespaceName, QueryDialectWQL, queryExpression)
+	return s.QueryContext(context.Background(), dst, namespaceName, queryExpression)
+}
+
+// QueryContext queries for a set of instances based on a query expression.
+// If ctx is done before the query has finished, the query is cancelled.
+func (s *Session) QueryContext(ctx context.Context, dst any, namespaceName Namespace, queryExpression Query) error {
+	err := s.QueryUnmarshalContext(ctx, dst, OperationFlagsStandardRTTI, nil, namespaceName, QueryDialectWQL, queryExpression)
 	if err != nil {
 		return fmt.Errorf("WMI query failed: %w", err)
 	}
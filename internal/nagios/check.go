@@ -0,0 +1,367 @@
+// Package nagios evaluates threshold expressions against metrics and formats the result as the output of a
+// Nagios plugin, which is understood by Nagios, Icinga and compatible monitoring systems.
+//
+// https://nagios-plugins.org/doc/guidelines.html#PLUGOUTPUT
+package nagios
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// collectorSuccessMetric is the metric of the Prometheus collector of windows_exporter, which reports failed collectors.
+const collectorSuccessMetric = "windows_exporter_collector_success"
+
+// State is the state of a check. The value is the exit code of the plugin.
+type State int
+
+const (
+	OK State = iota
+	Warning
+	Critical
+	Unknown
+)
+
+func (s State) String() string {
+	switch s {
+	case OK:
+		return "OK"
+	case Warning:
+		return "WARNING"
+	case Critical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// severity orders the states for the overall result of a check. A firing threshold outweighs the failure of
+// another threshold, as the failure may be caused by a missing metric.
+func (s State) severity() int {
+	switch s {
+	case OK:
+		return 0
+	case Unknown:
+		return 1
+	case Warning:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// Threshold is a comparison, which sets the check to State for every series, for which the comparison is true.
+// The left-hand side of the comparison is reported as performance data.
+type Threshold struct {
+	State State
+
+	expr string
+	lhs  string
+	op   string
+	lhsN node
+	rhsN node
+}
+
+// ParseThreshold parses the threshold expression, e.g.
+//
+//	windows_logical_disk_free_bytes{volume="C:"} / windows_logical_disk_size_bytes < 0.1
+func ParseThreshold(state State, expr string) (*Threshold, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold %q: %w", expr, err)
+	}
+
+	p := &parser{tokens: tokens}
+
+	lhs, err := p.parseExpr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold %q: %w", expr, err)
+	}
+
+	opToken := p.peek()
+
+	op, ok := p.accept("<", "<=", ">", ">=", "==", "!=")
+	if !ok {
+		if opToken.kind == tokenEOF {
+			return nil, fmt.Errorf("invalid threshold %q: %w", expr, errNotComparison)
+		}
+
+		return nil, fmt.Errorf("invalid threshold %q: %w", expr, p.unexpected("a comparison operator"))
+	}
+
+	rhs, err := p.parseExpr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold %q: %w", expr, err)
+	}
+
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("invalid threshold %q: %w", expr, p.unexpected("end of expression"))
+	}
+
+	return &Threshold{
+		State: state,
+		expr:  strings.TrimSpace(expr),
+		lhs:   strings.TrimSpace(expr[:opToken.pos]),
+		op:    op,
+		lhsN:  lhs,
+		rhsN:  rhs,
+	}, nil
+}
+
+func (t *Threshold) String() string {
+	return t.expr
+}
+
+// MetricNames returns the names of the metrics used by the threshold.
+func (t *Threshold) MetricNames() []string {
+	names := append(selectorNames(t.lhsN), selectorNames(t.rhsN)...)
+	slices.Sort(names)
+
+	return slices.Compact(names)
+}
+
+// performanceLabel returns the label of the performance data of the series of the left-hand side. It is the name of
+// the first metric of the left-hand side, followed by the label values of the series.
+func (t *Threshold) performanceLabel(s series) string {
+	parts := []string{t.lhs}
+	if names := selectorNames(t.lhsN); len(names) > 0 {
+		parts[0] = names[0]
+	}
+
+	for _, l := range s.labels {
+		parts = append(parts, l.value)
+	}
+
+	return strings.Join(parts, "_")
+}
+
+// performanceRange returns the threshold in the range format of the performance data, if the right-hand side is
+// a scalar. A range raises an alert, if the value is outside the range.
+func (t *Threshold) performanceRange(rhs value) string {
+	if !rhs.isScalar {
+		return ""
+	}
+
+	switch t.op {
+	case "<", "<=":
+		return formatValue(rhs.scalar) + ":"
+	case ">", ">=":
+		return "~:" + formatValue(rhs.scalar)
+	default:
+		return ""
+	}
+}
+
+// Perfdata is the performance data of a series.
+type Perfdata struct {
+	Label    string
+	Value    float64
+	Warning  string
+	Critical string
+}
+
+// String formats the performance data as 'label'=value;warn;crit.
+func (p Perfdata) String() string {
+	label := strings.NewReplacer("'", "_", "=", "_").Replace(p.Label)
+
+	return "'" + label + "'=" + formatValue(p.Value) + ";" + p.Warning + ";" + p.Critical
+}
+
+// Result is the result of a check.
+type Result struct {
+	State State
+	// Messages holds a message per firing threshold and per failure, the most severe first.
+	Messages []Message
+	// Passed is the number of thresholds, which didn't fire.
+	Passed   int
+	Perfdata []Perfdata
+}
+
+// NewUnknown returns the result of a check, which couldn't be run.
+func NewUnknown(err error) Result {
+	return Result{State: Unknown, Messages: []Message{{State: Unknown, Text: err.Error()}}}
+}
+
+// String formats the result as the output of a Nagios plugin. The first line holds the state, a summary and the
+// performance data. If there is more than one message, the following lines list all messages with their state.
+func (r Result) String() string {
+	var b strings.Builder
+
+	b.WriteString("WINDOWS " + r.State.String() + " - ")
+
+	switch len(r.Messages) {
+	case 0:
+		if r.Passed == 1 {
+			b.WriteString("1 threshold passed")
+		} else {
+			b.WriteString(strconv.Itoa(r.Passed) + " thresholds passed")
+		}
+	case 1:
+		b.WriteString(r.Messages[0].Text)
+	default:
+		b.WriteString(r.Messages[0].Text + fmt.Sprintf(" (and %d more)", len(r.Messages)-1))
+	}
+
+	if len(r.Perfdata) > 0 {
+		perfdata := make([]string, 0, len(r.Perfdata))
+		for _, p := range r.Perfdata {
+			perfdata = append(perfdata, p.String())
+		}
+
+		b.WriteString(" | " + strings.Join(perfdata, " "))
+	}
+
+	b.WriteByte('\n')
+
+	if len(r.Messages) > 1 {
+		for _, message := range r.Messages {
+			b.WriteString(message.State.String() + ": " + message.Text + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+// Message describes a firing threshold or a failure.
+type Message struct {
+	State State
+	Text  string
+}
+
+// Evaluate evaluates the thresholds against the metric families. A threshold without any series of its left-hand
+// side and a failed collector result in the state UNKNOWN. If the thresholds for the same left-hand side fire for
+// the same series, only the most severe one is reported.
+func Evaluate(families []*dto.MetricFamily, thresholds []*Threshold) Result {
+	idx := newIndex(families)
+	result := Result{State: OK}
+
+	var messages []Message
+
+	for _, s := range idx[collectorSuccessMetric] {
+		if s.value != 1 {
+			messages = append(messages, Message{State: Unknown, Text: fmt.Sprintf("collector %s failed", s.get("collector"))})
+		}
+	}
+
+	perfdata := make(map[string]int)
+	alerts := make(map[string]int)
+
+	for _, t := range thresholds {
+		lhs, rhs, firing, err := t.evaluate(idx)
+		if err != nil {
+			messages = append(messages, Message{State: Unknown, Text: fmt.Sprintf("%s: %s", t.expr, err)})
+
+			continue
+		}
+
+		if len(lhs) == 0 {
+			messages = append(messages, Message{State: Unknown, Text: "no series found for " + t.lhs})
+
+			continue
+		}
+
+		for _, s := range lhs {
+			key := t.lhs + "\xff" + s.key()
+
+			i, ok := perfdata[key]
+			if !ok {
+				i = len(result.Perfdata)
+				perfdata[key] = i
+
+				result.Perfdata = append(result.Perfdata, Perfdata{Label: t.performanceLabel(s), Value: s.value})
+			}
+
+			switch t.State {
+			case Warning:
+				result.Perfdata[i].Warning = t.performanceRange(rhs)
+			case Critical:
+				result.Perfdata[i].Critical = t.performanceRange(rhs)
+			}
+		}
+
+		if len(firing) == 0 {
+			result.Passed++
+
+			continue
+		}
+
+		for _, s := range firing {
+			text := fmt.Sprintf("%s: %s = %s", t.expr, s, formatValue(s.value))
+			if len(s.labels) == 0 {
+				text = fmt.Sprintf("%s: %s", t.expr, formatValue(s.value))
+			}
+
+			key := t.lhs + "\xff" + s.key()
+
+			if i, ok := alerts[key]; ok {
+				if t.State.severity() > messages[i].State.severity() {
+					messages[i] = Message{State: t.State, Text: text}
+				}
+
+				continue
+			}
+
+			alerts[key] = len(messages)
+			messages = append(messages, Message{State: t.State, Text: text})
+		}
+	}
+
+	slices.SortStableFunc(messages, func(a, b Message) int {
+		return b.State.severity() - a.State.severity()
+	})
+
+	if len(messages) > 0 {
+		result.State = messages[0].State
+	}
+
+	result.Messages = messages
+
+	return result
+}
+
+// evaluate returns the series of the left-hand side, the value of the right-hand side and the series of the
+// left-hand side, for which the comparison is true.
+func (t *Threshold) evaluate(idx index) ([]series, value, []series, error) {
+	lhs, err := t.lhsN.eval(idx)
+	if err != nil {
+		return nil, value{}, nil, err
+	}
+
+	rhs, err := t.rhsN.eval(idx)
+	if err != nil {
+		return nil, value{}, nil, err
+	}
+
+	firing, err := binaryOp(lhs, rhs, func(l, r float64) (float64, bool) {
+		return l, compare(t.op, l, r)
+	})
+	if err != nil {
+		return nil, value{}, nil, err
+	}
+
+	return vectorOf(lhs), rhs, vectorOf(firing), nil
+}
+
+// vectorOf returns the series of v. A scalar becomes a single series without labels.
+func vectorOf(v value) []series {
+	if v.isScalar {
+		return []series{{value: v.scalar}}
+	}
+
+	return v.vector
+}
+
+// formatValue formats a value for the performance data, which doesn't support the exponent notation.
+// NaN and ±Inf are reported as undetermined.
+func formatValue(f float64) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "U"
+	}
+
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,118 @@
+package nagios
+
+import (
+	"testing"
+
+	"github.com/prometheus-community/windows_exporter/internal/testutils"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func testFamilies(t *testing.T, collectorSuccess float64) []*dto.MetricFamily {
+	t.Helper()
+
+	freeDesc := prometheus.NewDesc("windows_logical_disk_free_bytes", "free", []string{"volume"}, nil)
+	sizeDesc := prometheus.NewDesc("windows_logical_disk_size_bytes", "size", []string{"volume"}, nil)
+
+	return testutils.Gather(t,
+		prometheus.MustNewConstMetric(freeDesc, prometheus.GaugeValue, 5, "C:"),
+		prometheus.MustNewConstMetric(sizeDesc, prometheus.GaugeValue, 100, "C:"),
+		prometheus.MustNewConstMetric(freeDesc, prometheus.GaugeValue, 150, "D:"),
+		prometheus.MustNewConstMetric(sizeDesc, prometheus.GaugeValue, 200, "D:"),
+		prometheus.MustNewConstMetric(
+			prometheus.NewDesc(collectorSuccessMetric, "success", []string{"collector"}, nil),
+			prometheus.GaugeValue, collectorSuccess, "logical_disk",
+		),
+	)
+}
+
+func mustParse(t *testing.T, state State, expr string) *Threshold {
+	t.Helper()
+
+	threshold, err := ParseThreshold(state, expr)
+	require.NoError(t, err)
+
+	return threshold
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name       string
+		failed     bool
+		thresholds []*Threshold
+		expected   string
+		state      State
+	}{
+		{
+			name: "ok",
+			thresholds: []*Threshold{
+				mustParse(t, Critical, `windows_logical_disk_free_bytes / windows_logical_disk_size_bytes < 0.01`),
+			},
+			expected: "WINDOWS OK - 1 threshold passed | 'windows_logical_disk_free_bytes_C:'=0.05;;0.01: 'windows_logical_disk_free_bytes_D:'=0.75;;0.01:\n",
+			state:    OK,
+		},
+		{
+			name: "critical",
+			thresholds: []*Threshold{
+				mustParse(t, Warning, `windows_logical_disk_free_bytes / windows_logical_disk_size_bytes < 0.2`),
+				mustParse(t, Critical, `windows_logical_disk_free_bytes / windows_logical_disk_size_bytes < 0.1`),
+			},
+			expected: "WINDOWS CRITICAL - windows_logical_disk_free_bytes / windows_logical_disk_size_bytes < 0.1: {volume=\"C:\"} = 0.05 | 'windows_logical_disk_free_bytes_C:'=0.05;0.2:;0.1: 'windows_logical_disk_free_bytes_D:'=0.75;0.2:;0.1:\n",
+			state:    Critical,
+		},
+		{
+			name: "selector",
+			thresholds: []*Threshold{
+				mustParse(t, Warning, `windows_logical_disk_free_bytes{volume="D:"} > 100`),
+			},
+			expected: "WINDOWS WARNING - windows_logical_disk_free_bytes{volume=\"D:\"} > 100: {volume=\"D:\"} = 150 | 'windows_logical_disk_free_bytes_D:'=150;~:100;\n",
+			state:    Warning,
+		},
+		{
+			name: "no series",
+			thresholds: []*Threshold{
+				mustParse(t, Critical, `windows_logical_disk_free_bytes{volume="E:"} < 1`),
+			},
+			expected: "WINDOWS UNKNOWN - no series found for windows_logical_disk_free_bytes{volume=\"E:\"}\n",
+			state:    Unknown,
+		},
+		{
+			name:   "failed collector",
+			failed: true,
+			thresholds: []*Threshold{
+				mustParse(t, Critical, `windows_logical_disk_free_bytes{volume="C:"} < 1`),
+				mustParse(t, Warning, `windows_logical_disk_free_bytes{volume="C:"} < 10`),
+			},
+			expected: "WINDOWS WARNING - windows_logical_disk_free_bytes{volume=\"C:\"} < 10: {volume=\"C:\"} = 5 (and 1 more) | 'windows_logical_disk_free_bytes_C:'=5;10:;1:\n" +
+				"WARNING: windows_logical_disk_free_bytes{volume=\"C:\"} < 10: {volume=\"C:\"} = 5\n" +
+				"UNKNOWN: collector logical_disk failed\n",
+			state: Warning,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			success := 1.0
+			if tc.failed {
+				success = 0
+			}
+
+			result := Evaluate(testFamilies(t, success), tc.thresholds)
+
+			require.Equal(t, tc.state, result.State)
+			require.Equal(t, tc.expected, result.String())
+		})
+	}
+}
+
+func TestNewUnknown(t *testing.T) {
+	t.Parallel()
+
+	result := NewUnknown(errNotComparison)
+
+	require.Equal(t, Unknown, result.State)
+	require.Equal(t, "WINDOWS UNKNOWN - "+errNotComparison.Error()+"\n", result.String())
+}
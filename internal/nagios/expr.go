@@ -0,0 +1,608 @@
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// The expressions are a small subset of PromQL:
+//
+//	threshold := expr ("<" | "<=" | ">" | ">=" | "==" | "!=") expr
+//	expr      := term (("+" | "-") term)*
+//	term      := unary (("*" | "/") unary)*
+//	unary     := "-" unary | number | selector | "(" expr ")"
+//	selector  := name ["{" matcher ("," matcher)* [","] "}"]
+//	matcher   := label ("=" | "!=" | "=~" | "!~") string
+//
+// Binary operations between two vectors match series with identical labels, like PromQL without on() or
+// ignoring(). The metric name isn't part of the labels.
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators are sorted, so that two-character operators are matched before their prefixes.
+var operators = []string{"==", "!=", "=~", "!~", "<=", ">=", "<", ">", "=", "+", "-", "*", "/", "(", ")", "{", "}", ","}
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+
+	for pos := 0; pos < len(input); {
+		c := input[pos]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+		case isIdentStart(c):
+			start := pos
+			for pos < len(input) && isIdentChar(input[pos]) {
+				pos++
+			}
+
+			tokens = append(tokens, token{kind: tokenIdent, text: input[start:pos], pos: start})
+		case isDigit(c) || (c == '.' && pos+1 < len(input) && isDigit(input[pos+1])):
+			start := pos
+			for pos < len(input) && (isDigit(input[pos]) || input[pos] == '.') {
+				pos++
+			}
+
+			if pos < len(input) && (input[pos] == 'e' || input[pos] == 'E') {
+				pos++
+				if pos < len(input) && (input[pos] == '+' || input[pos] == '-') {
+					pos++
+				}
+
+				for pos < len(input) && isDigit(input[pos]) {
+					pos++
+				}
+			}
+
+			tokens = append(tokens, token{kind: tokenNumber, text: input[start:pos], pos: start})
+		case c == '"' || c == '\'':
+			end := pos + 1
+			for end < len(input) && input[end] != c {
+				if input[end] == '\\' {
+					end++
+				}
+
+				end++
+			}
+
+			if end >= len(input) {
+				return nil, fmt.Errorf("unterminated string at position %d", pos)
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: input[pos : end+1], pos: pos})
+			pos = end + 1
+		default:
+			i := slices.IndexFunc(operators, func(op string) bool {
+				return strings.HasPrefix(input[pos:], op)
+			})
+			if i < 0 {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, pos)
+			}
+
+			tokens = append(tokens, token{kind: tokenOperator, text: operators[i], pos: pos})
+			pos += len(operators[i])
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) accept(ops ...string) (string, bool) {
+	if t := p.peek(); t.kind == tokenOperator && slices.Contains(ops, t.text) {
+		p.pos++
+
+		return t.text, true
+	}
+
+	return "", false
+}
+
+func (p *parser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		return p.unexpected(fmt.Sprintf("%q", op))
+	}
+
+	return nil
+}
+
+func (p *parser) unexpected(expected string) error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of expression, expected %s", expected)
+	}
+
+	return fmt.Errorf("unexpected %q at position %d, expected %s", t.text, t.pos, expected)
+}
+
+func (p *parser) parseExpr() (node, error) {
+	lhs, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return lhs, nil
+		}
+
+		rhs, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+
+		lhs = &binaryNode{op: op, lhs: lhs, rhs: rhs}
+	}
+}
+
+func (p *parser) parseTerm() (node, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.accept("*", "/")
+		if !ok {
+			return lhs, nil
+		}
+
+		rhs, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		lhs = &binaryNode{op: op, lhs: lhs, rhs: rhs}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if _, ok := p.accept("-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &binaryNode{op: "*", lhs: numberNode(-1), rhs: operand}, nil
+	}
+
+	if _, ok := p.accept("("); ok {
+		n, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		if err = p.expect(")"); err != nil {
+			return nil, err
+		}
+
+		return n, nil
+	}
+
+	switch t := p.peek(); t.kind {
+	case tokenNumber:
+		p.next()
+
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+
+		return numberNode(f), nil
+	case tokenIdent:
+		return p.parseSelector()
+	default:
+		return nil, p.unexpected("a number, a metric name or \"(\"")
+	}
+}
+
+func (p *parser) parseSelector() (node, error) {
+	s := &selectorNode{name: p.next().text}
+
+	if _, ok := p.accept("{"); !ok {
+		return s, nil
+	}
+
+	for {
+		if _, ok := p.accept("}"); ok {
+			return s, nil
+		}
+
+		t := p.next()
+		if t.kind != tokenIdent {
+			p.pos--
+
+			return nil, p.unexpected("a label name")
+		}
+
+		op, ok := p.accept("=", "!=", "=~", "!~")
+		if !ok {
+			return nil, p.unexpected("a label matcher")
+		}
+
+		str := p.next()
+		if str.kind != tokenString {
+			p.pos--
+
+			return nil, p.unexpected("a quoted label value")
+		}
+
+		value, err := unquote(str.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label value %s at position %d: %w", str.text, str.pos, err)
+		}
+
+		m := matcher{label: t.text, op: op, value: value}
+
+		if op == "=~" || op == "!~" {
+			if m.re, err = regexp.Compile("^(?:" + value + ")$"); err != nil {
+				return nil, fmt.Errorf("invalid regular expression %q: %w", value, err)
+			}
+		}
+
+		s.matchers = append(s.matchers, m)
+
+		if _, ok = p.accept(","); !ok {
+			if err = p.expect("}"); err != nil {
+				return nil, err
+			}
+
+			return s, nil
+		}
+	}
+}
+
+// unquote removes the quotes of a label value. Single-quoted values are converted to double-quoted values first,
+// so that both support the escape sequences of Go.
+func unquote(s string) (string, error) {
+	if s[0] == '\'' {
+		s = `"` + strings.ReplaceAll(strings.ReplaceAll(s[1:len(s)-1], `\'`, `'`), `"`, `\"`) + `"`
+	}
+
+	return strconv.Unquote(s)
+}
+
+// label is a label pair of a series. The labels of a series are sorted by name.
+type label struct {
+	name, value string
+}
+
+type series struct {
+	labels []label
+	value  float64
+}
+
+// key identifies the label set of the series for the matching of binary operations.
+func (s series) key() string {
+	var b strings.Builder
+
+	for _, l := range s.labels {
+		b.WriteString(l.name)
+		b.WriteByte(0xff)
+		b.WriteString(l.value)
+		b.WriteByte(0xff)
+	}
+
+	return b.String()
+}
+
+// String formats the labels like PromQL, e.g. {volume="C:"}.
+func (s series) String() string {
+	parts := make([]string, 0, len(s.labels))
+	for _, l := range s.labels {
+		parts = append(parts, l.name+"="+strconv.Quote(l.value))
+	}
+
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func (s series) get(name string) string {
+	for _, l := range s.labels {
+		if l.name == name {
+			return l.value
+		}
+	}
+
+	return ""
+}
+
+// value is the result of an expression, either a scalar or a vector.
+type value struct {
+	isScalar bool
+	scalar   float64
+	vector   []series
+}
+
+// index holds the series of the metric families by their name. Summaries and histograms are split into the series
+// of the classic Prometheus representation, e.g. <name>_sum, <name>_count and <name>_bucket.
+type index map[string][]series
+
+func newIndex(families []*dto.MetricFamily) index {
+	idx := make(index, len(families))
+
+	for _, family := range families {
+		name := family.GetName()
+
+		for _, metric := range family.GetMetric() {
+			labels := make([]label, 0, len(metric.GetLabel())+1)
+			for _, l := range metric.GetLabel() {
+				labels = append(labels, label{name: l.GetName(), value: l.GetValue()})
+			}
+
+			add := func(name string, value float64, extra ...label) {
+				s := series{labels: append(slices.Clip(labels), extra...), value: value}
+				slices.SortFunc(s.labels, func(a, b label) int {
+					return strings.Compare(a.name, b.name)
+				})
+
+				idx[name] = append(idx[name], s)
+			}
+
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, metric.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, metric.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(name, metric.GetUntyped().GetValue())
+			case dto.MetricType_SUMMARY:
+				summary := metric.GetSummary()
+
+				for _, quantile := range summary.GetQuantile() {
+					add(name, quantile.GetValue(), label{name: "quantile", value: formatFloat(quantile.GetQuantile())})
+				}
+
+				add(name+"_sum", summary.GetSampleSum())
+				add(name+"_count", float64(summary.GetSampleCount()))
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				histogram := metric.GetHistogram()
+				hasInf := false
+
+				for _, bucket := range histogram.GetBucket() {
+					if math.IsInf(bucket.GetUpperBound(), +1) {
+						hasInf = true
+					}
+
+					add(name+"_bucket", float64(bucket.GetCumulativeCount()), label{name: "le", value: formatFloat(bucket.GetUpperBound())})
+				}
+
+				if !hasInf {
+					add(name+"_bucket", float64(histogram.GetSampleCount()), label{name: "le", value: "+Inf"})
+				}
+
+				add(name+"_sum", histogram.GetSampleSum())
+				add(name+"_count", float64(histogram.GetSampleCount()))
+			}
+		}
+	}
+
+	return idx
+}
+
+type node interface {
+	eval(idx index) (value, error)
+}
+
+type numberNode float64
+
+func (n numberNode) eval(index) (value, error) {
+	return value{isScalar: true, scalar: float64(n)}, nil
+}
+
+type matcher struct {
+	label string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (m matcher) matches(v string) bool {
+	switch m.op {
+	case "=":
+		return v == m.value
+	case "!=":
+		return v != m.value
+	case "=~":
+		return m.re.MatchString(v)
+	default:
+		return !m.re.MatchString(v)
+	}
+}
+
+type selectorNode struct {
+	name     string
+	matchers []matcher
+}
+
+func (n *selectorNode) eval(idx index) (value, error) {
+	result := value{vector: []series{}}
+
+	for _, s := range idx[n.name] {
+		if !slices.ContainsFunc(n.matchers, func(m matcher) bool { return !m.matches(s.get(m.label)) }) {
+			result.vector = append(result.vector, s)
+		}
+	}
+
+	return result, nil
+}
+
+type binaryNode struct {
+	op       string
+	lhs, rhs node
+}
+
+func (n *binaryNode) eval(idx index) (value, error) {
+	lhs, err := n.lhs.eval(idx)
+	if err != nil {
+		return value{}, err
+	}
+
+	rhs, err := n.rhs.eval(idx)
+	if err != nil {
+		return value{}, err
+	}
+
+	return binaryOp(lhs, rhs, func(l, r float64) (float64, bool) {
+		return arithmetic(n.op, l, r), true
+	})
+}
+
+func arithmetic(op string, l, r float64) float64 {
+	switch op {
+	case "+":
+		return l + r
+	case "-":
+		return l - r
+	case "*":
+		return l * r
+	default:
+		return l / r
+	}
+}
+
+// binaryOp applies fn to the operands. Series, for which fn returns false, are dropped. The result of an operation
+// between two vectors keeps the labels of the series of the left-hand side.
+func binaryOp(lhs, rhs value, fn func(l, r float64) (float64, bool)) (value, error) {
+	switch {
+	case lhs.isScalar && rhs.isScalar:
+		v, ok := fn(lhs.scalar, rhs.scalar)
+		if !ok {
+			return value{vector: []series{}}, nil
+		}
+
+		return value{isScalar: true, scalar: v}, nil
+	case rhs.isScalar:
+		return mapVector(lhs.vector, func(s float64) (float64, bool) { return fn(s, rhs.scalar) }), nil
+	case lhs.isScalar:
+		return mapVector(rhs.vector, func(s float64) (float64, bool) { return fn(lhs.scalar, s) }), nil
+	}
+
+	right := make(map[string]float64, len(rhs.vector))
+
+	for _, s := range rhs.vector {
+		key := s.key()
+		if _, ok := right[key]; ok {
+			return value{}, fmt.Errorf("found duplicate series %s on the right-hand side of an operation", s)
+		}
+
+		right[key] = s.value
+	}
+
+	result := value{vector: make([]series, 0, len(lhs.vector))}
+
+	for _, s := range lhs.vector {
+		r, ok := right[s.key()]
+		if !ok {
+			continue
+		}
+
+		if v, ok := fn(s.value, r); ok {
+			result.vector = append(result.vector, series{labels: s.labels, value: v})
+		}
+	}
+
+	return result, nil
+}
+
+func mapVector(vector []series, fn func(float64) (float64, bool)) value {
+	result := value{vector: make([]series, 0, len(vector))}
+
+	for _, s := range vector {
+		if v, ok := fn(s.value); ok {
+			result.vector = append(result.vector, series{labels: s.labels, value: v})
+		}
+	}
+
+	return result
+}
+
+func compare(op string, l, r float64) bool {
+	switch op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	case ">=":
+		return l >= r
+	case "==":
+		return l == r
+	default:
+		return l != r
+	}
+}
+
+// selectorNames returns the metric names of all selectors of the expression.
+func selectorNames(n node) []string {
+	switch n := n.(type) {
+	case *selectorNode:
+		return []string{n.name}
+	case *binaryNode:
+		return append(selectorNames(n.lhs), selectorNames(n.rhs)...)
+	default:
+		return nil
+	}
+}
+
+var errNotComparison = errors.New("expression must be a comparison, e.g. windows_cpu_time_total > 0")
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, +1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
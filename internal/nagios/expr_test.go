@@ -0,0 +1,125 @@
+package nagios
+
+import (
+	"testing"
+
+	"github.com/prometheus-community/windows_exporter/internal/testutils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseThreshold(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		expr  string
+		err   string
+		lhs   string
+		names []string
+	}{
+		{
+			expr:  `windows_logical_disk_free_bytes{volume="C:"} / windows_logical_disk_size_bytes < 0.1`,
+			lhs:   `windows_logical_disk_free_bytes{volume="C:"} / windows_logical_disk_size_bytes`,
+			names: []string{"windows_logical_disk_free_bytes", "windows_logical_disk_size_bytes"},
+		},
+		{
+			expr:  `-(windows_os_time + 1e3) * 2 >= windows_os_time{job!~'a.*', x!="y",}`,
+			lhs:   `-(windows_os_time + 1e3) * 2`,
+			names: []string{"windows_os_time"},
+		},
+		{expr: `windows_cpu_time_total`, err: "expression must be a comparison"},
+		{expr: `windows_cpu_time_total > `, err: "unexpected end of expression"},
+		{expr: `windows_cpu_time_total{mode=idle} > 0`, err: `unexpected "idle" at position 28, expected a quoted label value`},
+		{expr: `windows_cpu_time_total{mode="idle" > 0`, err: `unexpected ">" at position 35, expected "}"`},
+		{expr: `windows_cpu_time_total{mode=~"("} > 0`, err: "invalid regular expression"},
+		{expr: `windows_cpu_time_total{mode="idle} > 0`, err: "unterminated string"},
+		{expr: `windows_cpu_time_total > 0 > 1`, err: "expected end of expression"},
+		{expr: `windows_cpu_time_total ? 0`, err: "unexpected character"},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			t.Parallel()
+
+			threshold, err := ParseThreshold(Critical, tc.expr)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.lhs, threshold.lhs)
+			require.Equal(t, tc.names, threshold.MetricNames())
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	t.Parallel()
+
+	cpuDesc := prometheus.NewDesc("windows_cpu_time_total", "cpu", []string{"core", "mode"}, nil)
+
+	families := testutils.Gather(t,
+		prometheus.MustNewConstMetric(cpuDesc, prometheus.CounterValue, 10, "0,0", "idle"),
+		prometheus.MustNewConstMetric(cpuDesc, prometheus.CounterValue, 30, "0,0", "user"),
+		prometheus.MustNewConstMetric(cpuDesc, prometheus.CounterValue, 20, "0,1", "idle"),
+		prometheus.MustNewConstHistogram(
+			prometheus.NewDesc("test_duration_seconds", "histogram", nil, nil),
+			4, 10, map[float64]uint64{1: 3},
+		),
+	)
+
+	idx := newIndex(families)
+
+	for _, tc := range []struct {
+		expr     string
+		expected []series
+	}{
+		{
+			expr: `windows_cpu_time_total{mode="idle"} * 2 > 30`,
+			expected: []series{
+				{labels: []label{{"core", "0,1"}, {"mode", "idle"}}, value: 40},
+			},
+		},
+		{
+			expr: `windows_cpu_time_total{mode=~"id.*"} / windows_cpu_time_total{core="0,0"} == 1`,
+			expected: []series{
+				{labels: []label{{"core", "0,0"}, {"mode", "idle"}}, value: 1},
+			},
+		},
+		{
+			expr: `windows_cpu_time_total{mode!="idle"} - 5 >= 25`,
+			expected: []series{
+				{labels: []label{{"core", "0,0"}, {"mode", "user"}}, value: 25},
+			},
+		},
+		{
+			expr: `test_duration_seconds_sum / test_duration_seconds_count > 2`,
+			expected: []series{
+				{labels: []label{}, value: 2.5},
+			},
+		},
+		{
+			expr: `test_duration_seconds_bucket{le="+Inf"} == 4`,
+			expected: []series{
+				{labels: []label{{"le", "+Inf"}}, value: 4},
+			},
+		},
+		{
+			expr: `1 + 2 * 3 == 7`,
+			expected: []series{
+				{value: 7},
+			},
+		},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			t.Parallel()
+
+			threshold, err := ParseThreshold(Critical, tc.expr)
+			require.NoError(t, err)
+
+			_, _, firing, err := threshold.evaluate(idx)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, firing)
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/prometheus/common/config"
+	"github.com/prometheus/common/version"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	grpcgzip "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// exportMethod is the gRPC method of the OTLP metrics service.
+const exportMethod = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+
+// client sends encoded ExportMetricsServiceRequest messages to the collector.
+type client interface {
+	// Export sends the request. If the collector rejected a part of the data points, a partialSuccessError is returned.
+	Export(ctx context.Context, request []byte) error
+	Close() error
+}
+
+// httpClient implements the OTLP/HTTP transport with binary protobuf encoding.
+type httpClient struct {
+	url         string
+	headers     map[string]config.Secret
+	compression string
+	client      *http.Client
+}
+
+func newHTTPClient(cfg Config) (*httpClient, error) {
+	client, err := config.NewClientFromConfig(cfg.HTTPClientConfig, "otlp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	client.Timeout = cfg.Timeout
+
+	return &httpClient{
+		url:         cfg.Endpoint,
+		headers:     cfg.Headers,
+		compression: cfg.Compression,
+		client:      client,
+	}, nil
+}
+
+func (c *httpClient) Export(ctx context.Context, request []byte) error {
+	body := request
+
+	if c.compression == CompressionGzip {
+		var buf bytes.Buffer
+
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(request); err != nil {
+			return err
+		}
+
+		if err := gz.Close(); err != nil {
+			return err
+		}
+
+		body = buf.Bytes()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	for name, value := range c.headers {
+		req.Header.Set(name, string(value))
+	}
+
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "windows_exporter/"+version.Version)
+
+	if c.compression == CompressionGzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		if len(respBody) > 256 {
+			respBody = respBody[:256]
+		}
+
+		return fmt.Errorf("server returned HTTP status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	return parsePartialSuccess(respBody)
+}
+
+func (c *httpClient) Close() error {
+	c.client.CloseIdleConnections()
+
+	return nil
+}
+
+// grpcClient implements the OTLP/gRPC transport. The requests are already encoded, so the connection uses
+// a codec which passes the bytes through.
+type grpcClient struct {
+	conn        *grpc.ClientConn
+	headers     map[string]config.Secret
+	compression string
+}
+
+func newGRPCClient(cfg Config) (*grpcClient, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	transportCredentials := insecure.NewCredentials()
+
+	if u.Scheme == "https" {
+		tlsConfig, err := config.NewTLSConfig(&cfg.HTTPClientConfig.TLSConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create TLS config: %w", err)
+		}
+
+		transportCredentials = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(u.Host,
+		grpc.WithTransportCredentials(transportCredentials),
+		grpc.WithUserAgent("windows_exporter/"+version.Version),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+
+	return &grpcClient{
+		conn:        conn,
+		headers:     cfg.Headers,
+		compression: cfg.Compression,
+	}, nil
+}
+
+func (c *grpcClient) Export(ctx context.Context, request []byte) error {
+	for name, value := range c.headers {
+		ctx = metadata.AppendToOutgoingContext(ctx, name, string(value))
+	}
+
+	opts := []grpc.CallOption{grpc.ForceCodec(rawCodec{})}
+	if c.compression == CompressionGzip {
+		opts = append(opts, grpc.UseCompressor(grpcgzip.Name))
+	}
+
+	var response []byte
+
+	if err := c.conn.Invoke(ctx, exportMethod, &request, &response, opts...); err != nil {
+		return err
+	}
+
+	return parsePartialSuccess(response)
+}
+
+func (c *grpcClient) Close() error {
+	return c.conn.Close()
+}
+
+// rawCodec is a gRPC codec for messages, which are already encoded. Messages are passed as *[]byte.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+
+	return *b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+
+	*b = append((*b)[:0], data...)
+
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// partialSuccessError is returned, if the collector accepted the request, but rejected a part of the data points.
+type partialSuccessError struct {
+	rejected int64
+	message  string
+}
+
+func (e partialSuccessError) Error() string {
+	return fmt.Sprintf("collector rejected %d data points: %s", e.rejected, e.message)
+}
+
+// parsePartialSuccess decodes an ExportMetricsServiceResponse message. If the collector rejected data points,
+// a partialSuccessError is returned.
+//
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/collector/metrics/v1/metrics_service.proto
+func parsePartialSuccess(b []byte) error {
+	partialSuccess, err := consumeField(b, 1)
+	if err != nil || partialSuccess == nil {
+		return err
+	}
+
+	var (
+		rejected     int64
+		errorMessage string
+	)
+
+	for len(partialSuccess) > 0 {
+		num, typ, n := protowire.ConsumeTag(partialSuccess)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+
+		partialSuccess = partialSuccess[n:]
+
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(partialSuccess)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+
+			rejected = int64(v)
+			partialSuccess = partialSuccess[n:]
+		case num == 2 && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(partialSuccess)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+
+			errorMessage = v
+			partialSuccess = partialSuccess[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, partialSuccess)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+
+			partialSuccess = partialSuccess[n:]
+		}
+	}
+
+	if rejected == 0 && errorMessage == "" {
+		return nil
+	}
+
+	return partialSuccessError{rejected: rejected, message: errorMessage}
+}
+
+// consumeField returns the value of the last length-delimited field with the number num of the message b.
+func consumeField(b []byte, num protowire.Number) ([]byte, error) {
+	var value []byte
+
+	for len(b) > 0 {
+		fieldNum, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+
+		b = b[n:]
+
+		if fieldNum == num && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+
+			value = v
+			b = b[n:]
+
+			continue
+		}
+
+		n = protowire.ConsumeFieldValue(fieldNum, typ, b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+
+		b = b[n:]
+	}
+
+	return value, nil
+}
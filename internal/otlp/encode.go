@@ -0,0 +1,301 @@
+package otlp
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// aggregationTemporalityCumulative is the AGGREGATION_TEMPORALITY_CUMULATIVE value of the AggregationTemporality enum.
+const aggregationTemporalityCumulative = 2
+
+// Attribute is a key-value pair of the resource or of a data point.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Scope identifies the instrumentation scope of the exported metrics.
+type Scope struct {
+	Name    string
+	Version string
+}
+
+// EncodeExportRequest converts the metric families into an ExportMetricsServiceRequest and returns the protobuf encoding
+// of the request. Counters become monotonic cumulative sums, gauges and untyped metrics become gauges,
+// histograms and summaries become their OTLP counterparts. Data points without a timestamp get the timestamp now.
+// Cumulative data points start at start.
+func EncodeExportRequest(families []*dto.MetricFamily, resource []Attribute, scope Scope, start, now time.Time) []byte {
+	var scopeMetrics []byte
+
+	scopeMetrics = protowire.AppendTag(scopeMetrics, 1, protowire.BytesType)
+	scopeMetrics = protowire.AppendBytes(scopeMetrics, marshalScope(scope))
+
+	for _, family := range families {
+		metric := marshalMetric(family, uint64(start.UnixNano()), uint64(now.UnixNano()))
+		if metric == nil {
+			continue
+		}
+
+		scopeMetrics = protowire.AppendTag(scopeMetrics, 2, protowire.BytesType)
+		scopeMetrics = protowire.AppendBytes(scopeMetrics, metric)
+	}
+
+	var resourceMetrics []byte
+
+	resourceMetrics = protowire.AppendTag(resourceMetrics, 1, protowire.BytesType)
+	resourceMetrics = protowire.AppendBytes(resourceMetrics, marshalResource(resource))
+	resourceMetrics = protowire.AppendTag(resourceMetrics, 2, protowire.BytesType)
+	resourceMetrics = protowire.AppendBytes(resourceMetrics, scopeMetrics)
+
+	var b []byte
+
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, resourceMetrics)
+
+	return b
+}
+
+// marshalResource encodes a Resource message.
+//
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/resource/v1/resource.proto
+func marshalResource(attributes []Attribute) []byte {
+	return appendAttributes(nil, 1, attributes)
+}
+
+// marshalScope encodes an InstrumentationScope message.
+//
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/common/v1/common.proto
+func marshalScope(scope Scope) []byte {
+	var b []byte
+
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, scope.Name)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendString(b, scope.Version)
+
+	return b
+}
+
+// marshalMetric encodes a Metric message. It returns nil for metric types without an OTLP counterpart.
+//
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/metrics/v1/metrics.proto
+func marshalMetric(family *dto.MetricFamily, startNano, nowNano uint64) []byte {
+	var (
+		data      []byte
+		dataField protowire.Number
+	)
+
+	switch family.GetType() {
+	case dto.MetricType_COUNTER:
+		dataField = 7
+
+		for _, metric := range family.GetMetric() {
+			data = protowire.AppendTag(data, 1, protowire.BytesType)
+			data = protowire.AppendBytes(data, marshalNumberDataPoint(metric, metric.GetCounter().GetValue(), startNano, nowNano))
+		}
+
+		data = protowire.AppendTag(data, 2, protowire.VarintType)
+		data = protowire.AppendVarint(data, aggregationTemporalityCumulative)
+		data = protowire.AppendTag(data, 3, protowire.VarintType)
+		data = protowire.AppendVarint(data, protowire.EncodeBool(true))
+	case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+		dataField = 5
+
+		for _, metric := range family.GetMetric() {
+			value := metric.GetGauge().GetValue()
+			if family.GetType() == dto.MetricType_UNTYPED {
+				value = metric.GetUntyped().GetValue()
+			}
+
+			data = protowire.AppendTag(data, 1, protowire.BytesType)
+			data = protowire.AppendBytes(data, marshalNumberDataPoint(metric, value, 0, nowNano))
+		}
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		dataField = 9
+
+		for _, metric := range family.GetMetric() {
+			data = protowire.AppendTag(data, 1, protowire.BytesType)
+			data = protowire.AppendBytes(data, marshalHistogramDataPoint(metric, startNano, nowNano))
+		}
+
+		data = protowire.AppendTag(data, 2, protowire.VarintType)
+		data = protowire.AppendVarint(data, aggregationTemporalityCumulative)
+	case dto.MetricType_SUMMARY:
+		dataField = 11
+
+		for _, metric := range family.GetMetric() {
+			data = protowire.AppendTag(data, 1, protowire.BytesType)
+			data = protowire.AppendBytes(data, marshalSummaryDataPoint(metric, startNano, nowNano))
+		}
+	default:
+		return nil
+	}
+
+	var b []byte
+
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, family.GetName())
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendString(b, family.GetHelp())
+	b = protowire.AppendTag(b, dataField, protowire.BytesType)
+	b = protowire.AppendBytes(b, data)
+
+	return b
+}
+
+// marshalNumberDataPoint encodes a NumberDataPoint message. A startNano of 0 omits the start time.
+func marshalNumberDataPoint(metric *dto.Metric, value float64, startNano, nowNano uint64) []byte {
+	b := appendTimestamps(nil, metric, startNano, nowNano)
+
+	b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(value))
+	b = appendAttributes(b, 7, labelAttributes(metric))
+
+	return b
+}
+
+// marshalHistogramDataPoint encodes a HistogramDataPoint message. The cumulative buckets of Prometheus are converted
+// into the bucket counts of OTLP, which count the observations between two bounds. The +Inf bucket of Prometheus
+// is implied by OTLP.
+func marshalHistogramDataPoint(metric *dto.Metric, startNano, nowNano uint64) []byte {
+	histogram := metric.GetHistogram()
+
+	bounds := make([]float64, 0, len(histogram.GetBucket()))
+	counts := make([]uint64, 0, len(histogram.GetBucket())+1)
+
+	var previous uint64
+
+	for _, bucket := range histogram.GetBucket() {
+		if math.IsInf(bucket.GetUpperBound(), +1) {
+			continue
+		}
+
+		bounds = append(bounds, bucket.GetUpperBound())
+		counts = append(counts, bucket.GetCumulativeCount()-previous)
+		previous = bucket.GetCumulativeCount()
+	}
+
+	counts = append(counts, histogram.GetSampleCount()-previous)
+
+	b := appendTimestamps(nil, metric, startNano, nowNano)
+
+	b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, histogram.GetSampleCount())
+	b = protowire.AppendTag(b, 5, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(histogram.GetSampleSum()))
+
+	var packed []byte
+
+	for _, count := range counts {
+		packed = protowire.AppendFixed64(packed, count)
+	}
+
+	b = protowire.AppendTag(b, 6, protowire.BytesType)
+	b = protowire.AppendBytes(b, packed)
+
+	if len(bounds) > 0 {
+		packed = packed[:0]
+
+		for _, bound := range bounds {
+			packed = protowire.AppendFixed64(packed, math.Float64bits(bound))
+		}
+
+		b = protowire.AppendTag(b, 7, protowire.BytesType)
+		b = protowire.AppendBytes(b, packed)
+	}
+
+	b = appendAttributes(b, 9, labelAttributes(metric))
+
+	return b
+}
+
+// marshalSummaryDataPoint encodes a SummaryDataPoint message.
+func marshalSummaryDataPoint(metric *dto.Metric, startNano, nowNano uint64) []byte {
+	summary := metric.GetSummary()
+
+	b := appendTimestamps(nil, metric, startNano, nowNano)
+
+	b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, summary.GetSampleCount())
+	b = protowire.AppendTag(b, 5, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(summary.GetSampleSum()))
+
+	for _, quantile := range summary.GetQuantile() {
+		var qb []byte
+
+		qb = protowire.AppendTag(qb, 1, protowire.Fixed64Type)
+		qb = protowire.AppendFixed64(qb, math.Float64bits(quantile.GetQuantile()))
+		qb = protowire.AppendTag(qb, 2, protowire.Fixed64Type)
+		qb = protowire.AppendFixed64(qb, math.Float64bits(quantile.GetValue()))
+
+		b = protowire.AppendTag(b, 6, protowire.BytesType)
+		b = protowire.AppendBytes(b, qb)
+	}
+
+	b = appendAttributes(b, 7, labelAttributes(metric))
+
+	return b
+}
+
+// appendTimestamps appends the start_time_unix_nano and time_unix_nano fields, which share the field numbers
+// across all data point messages.
+func appendTimestamps(b []byte, metric *dto.Metric, startNano, nowNano uint64) []byte {
+	timestamp := nowNano
+	if metric.TimestampMs != nil {
+		timestamp = uint64(metric.GetTimestampMs()) * uint64(time.Millisecond)
+	}
+
+	if startNano > 0 {
+		b = protowire.AppendTag(b, 2, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, min(startNano, timestamp))
+	}
+
+	b = protowire.AppendTag(b, 3, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, timestamp)
+
+	return b
+}
+
+func labelAttributes(metric *dto.Metric) []Attribute {
+	attributes := make([]Attribute, 0, len(metric.GetLabel()))
+
+	for _, labelPair := range metric.GetLabel() {
+		attributes = append(attributes, Attribute{Key: labelPair.GetName(), Value: labelPair.GetValue()})
+	}
+
+	return attributes
+}
+
+// appendAttributes appends the attributes as repeated KeyValue messages with string values.
+func appendAttributes(b []byte, field protowire.Number, attributes []Attribute) []byte {
+	for _, attribute := range attributes {
+		var value []byte
+
+		value = protowire.AppendTag(value, 1, protowire.BytesType)
+		value = protowire.AppendString(value, attribute.Value)
+
+		var kv []byte
+
+		kv = protowire.AppendTag(kv, 1, protowire.BytesType)
+		kv = protowire.AppendString(kv, attribute.Key)
+		kv = protowire.AppendTag(kv, 2, protowire.BytesType)
+		kv = protowire.AppendBytes(kv, value)
+
+		b = protowire.AppendTag(b, field, protowire.BytesType)
+		b = protowire.AppendBytes(b, kv)
+	}
+
+	return b
+}
+
+// sortAttributes sorts the attributes by key.
+func sortAttributes(attributes []Attribute) {
+	slices.SortFunc(attributes, func(a, b Attribute) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+}
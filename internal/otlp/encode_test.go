@@ -0,0 +1,213 @@
+package otlp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/testutils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// message is a decoded protobuf message, holding the raw values per field number.
+type message map[protowire.Number][]fieldValue
+
+type fieldValue struct {
+	number uint64
+	bytes  []byte
+}
+
+func decodeMessage(t *testing.T, b []byte) message {
+	t.Helper()
+
+	m := message{}
+
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+
+		var v fieldValue
+
+		switch typ {
+		case protowire.VarintType:
+			v.number, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			v.number, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			v.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			t.Fatalf("unexpected wire type %d", typ)
+		}
+
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+
+		m[num] = append(m[num], v)
+	}
+
+	return m
+}
+
+func (m message) messages(t *testing.T, num protowire.Number) []message {
+	t.Helper()
+
+	messages := make([]message, 0, len(m[num]))
+	for _, v := range m[num] {
+		messages = append(messages, decodeMessage(t, v.bytes))
+	}
+
+	return messages
+}
+
+func (m message) message(t *testing.T, num protowire.Number) message {
+	t.Helper()
+
+	messages := m.messages(t, num)
+	require.Len(t, messages, 1)
+
+	return messages[0]
+}
+
+func (m message) string(num protowire.Number) string {
+	if len(m[num]) == 0 {
+		return ""
+	}
+
+	return string(m[num][0].bytes)
+}
+
+func (m message) uint(num protowire.Number) uint64 {
+	if len(m[num]) == 0 {
+		return 0
+	}
+
+	return m[num][0].number
+}
+
+func (m message) double(num protowire.Number) float64 {
+	return math.Float64frombits(m.uint(num))
+}
+
+// attributes decodes the repeated KeyValue field num of the message.
+func (m message) attributes(t *testing.T, num protowire.Number) map[string]string {
+	t.Helper()
+
+	attributes := make(map[string]string)
+	for _, kv := range m.messages(t, num) {
+		attributes[kv.string(1)] = kv.message(t, 2).string(1)
+	}
+
+	return attributes
+}
+
+// packedFixed64 decodes the packed repeated fixed64 field num of the message.
+func (m message) packedFixed64(t *testing.T, num protowire.Number) []uint64 {
+	t.Helper()
+
+	var values []uint64
+
+	for _, v := range m[num] {
+		b := v.bytes
+		for len(b) > 0 {
+			value, n := protowire.ConsumeFixed64(b)
+			require.GreaterOrEqual(t, n, 0)
+
+			values = append(values, value)
+			b = b[n:]
+		}
+	}
+
+	return values
+}
+
+// decodeMetrics decodes an ExportMetricsServiceRequest and returns the resource and the metrics by name.
+func decodeMetrics(t *testing.T, b []byte) (message, message, map[string]message) {
+	t.Helper()
+
+	resourceMetrics := decodeMessage(t, b).message(t, 1)
+	scopeMetrics := resourceMetrics.message(t, 2)
+
+	metrics := make(map[string]message)
+	for _, metric := range scopeMetrics.messages(t, 2) {
+		metrics[metric.string(1)] = metric
+	}
+
+	return resourceMetrics.message(t, 1), scopeMetrics.message(t, 1), metrics
+}
+
+func TestEncodeExportRequest(t *testing.T) {
+	t.Parallel()
+
+	families := testutils.Gather(t,
+		prometheus.MustNewConstMetric(
+			prometheus.NewDesc("test_total", "counter help", []string{"core"}, nil),
+			prometheus.CounterValue, 42, "0",
+		),
+		prometheus.MustNewConstMetric(prometheus.NewDesc("test_gauge", "gauge help", nil, nil), prometheus.GaugeValue, 1.5),
+		prometheus.MustNewConstMetric(prometheus.NewDesc("test_untyped", "untyped help", nil, nil), prometheus.UntypedValue, 7),
+		prometheus.MustNewConstHistogram(
+			prometheus.NewDesc("test_histogram", "histogram help", nil, nil),
+			6, 12.5, map[float64]uint64{1: 2, 5: 5},
+		),
+		prometheus.MustNewConstSummary(
+			prometheus.NewDesc("test_summary", "summary help", nil, nil),
+			10, 20, map[float64]float64{0.5: 1.5, 0.9: 3},
+		),
+	)
+
+	start := time.Unix(1000, 0)
+	now := time.Unix(2000, 0)
+
+	b := EncodeExportRequest(families, []Attribute{{Key: "host.name", Value: "web01"}}, Scope{Name: "scope", Version: "1.0"}, start, now)
+
+	resource, scope, metrics := decodeMetrics(t, b)
+
+	require.Equal(t, map[string]string{"host.name": "web01"}, resource.attributes(t, 1))
+	require.Equal(t, "scope", scope.string(1))
+	require.Equal(t, "1.0", scope.string(2))
+	require.Len(t, metrics, 5)
+
+	// Counters become monotonic cumulative sums.
+	sum := metrics["test_total"].message(t, 7)
+	require.Equal(t, "counter help", metrics["test_total"].string(2))
+	require.Equal(t, uint64(aggregationTemporalityCumulative), sum.uint(2))
+	require.Equal(t, uint64(1), sum.uint(3))
+
+	dataPoint := sum.message(t, 1)
+	require.InDelta(t, 42.0, dataPoint.double(4), 0)
+	require.Equal(t, uint64(start.UnixNano()), dataPoint.uint(2))
+	require.Equal(t, uint64(now.UnixNano()), dataPoint.uint(3))
+	require.Equal(t, map[string]string{"core": "0"}, dataPoint.attributes(t, 7))
+
+	// Gauges and untyped metrics become gauges without a start time.
+	dataPoint = metrics["test_gauge"].message(t, 5).message(t, 1)
+	require.InDelta(t, 1.5, dataPoint.double(4), 0)
+	require.Empty(t, dataPoint[2])
+
+	dataPoint = metrics["test_untyped"].message(t, 5).message(t, 1)
+	require.InDelta(t, 7.0, dataPoint.double(4), 0)
+
+	// The cumulative buckets are converted into the counts per bucket. The last count covers the +Inf bucket.
+	histogram := metrics["test_histogram"].message(t, 9)
+	require.Equal(t, uint64(aggregationTemporalityCumulative), histogram.uint(2))
+
+	dataPoint = histogram.message(t, 1)
+	require.Equal(t, uint64(6), dataPoint.uint(4))
+	require.InDelta(t, 12.5, dataPoint.double(5), 0)
+	require.Equal(t, []uint64{2, 3, 1}, dataPoint.packedFixed64(t, 6))
+	require.Equal(t, []uint64{math.Float64bits(1), math.Float64bits(5)}, dataPoint.packedFixed64(t, 7))
+
+	dataPoint = metrics["test_summary"].message(t, 11).message(t, 1)
+	require.Equal(t, uint64(10), dataPoint.uint(4))
+	require.InDelta(t, 20.0, dataPoint.double(5), 0)
+
+	quantiles := dataPoint.messages(t, 6)
+	require.Len(t, quantiles, 2)
+	require.InDelta(t, 0.5, quantiles[0].double(1), 0)
+	require.InDelta(t, 1.5, quantiles[0].double(2), 0)
+	require.InDelta(t, 0.9, quantiles[1].double(1), 0)
+	require.InDelta(t, 3.0, quantiles[1].double(2), 0)
+}
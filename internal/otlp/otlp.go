@@ -0,0 +1,220 @@
+// Package otlp exports metrics to an OpenTelemetry collector with the OpenTelemetry protocol (OTLP).
+package otlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/sink"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/config"
+	"github.com/prometheus/common/version"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ProtocolGRPC         = "grpc"
+	ProtocolHTTPProtobuf = "http/protobuf"
+
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+)
+
+const (
+	defaultInterval = time.Minute
+	defaultTimeout  = 10 * time.Second
+
+	serviceName = "windows_exporter"
+	scopeName   = "github.com/prometheus-community/windows_exporter"
+)
+
+// Config is the configuration of the OTLP export.
+type Config struct {
+	// Endpoint is the URL of the collector. For http/protobuf, the URL including the path, e.g.
+	// http://localhost:4318/v1/metrics. For grpc, the scheme selects between a plain (http) and a TLS (https) connection,
+	// e.g. https://localhost:4317.
+	Endpoint string `yaml:"endpoint"`
+	// Protocol is either grpc or http/protobuf. Defaults to http/protobuf.
+	Protocol string `yaml:"protocol"`
+	// Interval between two exports. Defaults to 1m.
+	Interval time.Duration `yaml:"interval"`
+	// Timeout of a single export. Defaults to 10s.
+	Timeout time.Duration `yaml:"timeout"`
+	// Compression is either none or gzip. Defaults to none.
+	Compression string `yaml:"compression"`
+	// Headers are added to every request. For grpc, they are sent as request metadata.
+	// Their values are secrets, as they usually carry credentials.
+	Headers map[string]config.Secret `yaml:"headers"`
+	// ResourceAttributes are added to the resource, overriding the attributes derived from the collectors.
+	ResourceAttributes map[string]string `yaml:"resource_attributes"`
+	// HTTPClientConfig configures authentication and TLS. grpc supports the TLS settings only.
+	HTTPClientConfig config.HTTPClientConfig `yaml:",inline"`
+}
+
+// UnmarshalYAML applies the defaults and validates the configuration.
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	type plain Config
+
+	*c = Config{
+		Protocol:         ProtocolHTTPProtobuf,
+		Compression:      CompressionNone,
+		HTTPClientConfig: config.DefaultHTTPClientConfig,
+	}
+
+	if err := value.Decode((*plain)(c)); err != nil {
+		return err
+	}
+
+	u, err := url.ParseRequestURI(c.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid otlp endpoint %q: %w", c.Endpoint, err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid otlp endpoint %q: scheme must be http or https", c.Endpoint)
+	}
+
+	switch c.Protocol {
+	case ProtocolHTTPProtobuf:
+	case ProtocolGRPC:
+		if c.HTTPClientConfig.BasicAuth != nil || c.HTTPClientConfig.Authorization != nil || c.HTTPClientConfig.OAuth2 != nil {
+			return errors.New("otlp protocol grpc doesn't support basic_auth, authorization and oauth2, use headers instead")
+		}
+	default:
+		return fmt.Errorf("unknown otlp protocol %q, must be %s or %s", c.Protocol, ProtocolGRPC, ProtocolHTTPProtobuf)
+	}
+
+	if c.Compression != CompressionNone && c.Compression != CompressionGzip {
+		return fmt.Errorf("unknown otlp compression %q, must be %s or %s", c.Compression, CompressionNone, CompressionGzip)
+	}
+
+	return c.HTTPClientConfig.Validate()
+}
+
+// Enabled returns true, if an endpoint is configured.
+func (c Config) Enabled() bool {
+	return c.Endpoint != ""
+}
+
+// Interface guard.
+var _ prometheus.Collector = (*Exporter)(nil)
+
+// Exporter periodically gathers metrics and exports them to the collector.
+type Exporter struct {
+	logger *slog.Logger
+	config Config
+	client client
+	// start is the start time of the cumulative data points.
+	start time.Time
+
+	stats    *sink.Stats
+	rejected atomic.Uint64
+
+	rejectedDesc *prometheus.Desc
+}
+
+// New returns an Exporter for the configuration. The Exporter has to be started by Run.
+func New(logger *slog.Logger, cfg Config) (*Exporter, error) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	var (
+		c   client
+		err error
+	)
+
+	if cfg.Protocol == ProtocolGRPC {
+		c, err = newGRPCClient(cfg)
+	} else {
+		c, err = newHTTPClient(cfg)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Exporter{
+		logger: logger,
+		config: cfg,
+		client: c,
+		start:  time.Now(),
+		stats:  sink.NewStats("otlp", "requests", "the OpenTelemetry collector", nil),
+		rejectedDesc: prometheus.NewDesc(
+			"windows_exporter_otlp_rejected_data_points_total",
+			"windows_exporter: Number of data points rejected by the collector.",
+			nil,
+			nil,
+		),
+	}, nil
+}
+
+// Run exports the metrics of gatherer on every interval until ctx is done. The connection to the collector
+// is closed afterward.
+func (e *Exporter) Run(ctx context.Context, gatherer prometheus.Gatherer) {
+	defer func() {
+		if err := e.client.Close(); err != nil {
+			e.logger.Debug("failed to close OTLP client",
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	sink.Run(ctx, e.config.Interval, func(ctx context.Context) {
+		e.Export(ctx, gatherer)
+	})
+}
+
+// Export gathers the metrics once and exports them to the collector.
+func (e *Exporter) Export(ctx context.Context, gatherer prometheus.Gatherer) {
+	families := sink.Gather(e.logger, gatherer)
+
+	resource := ResourceAttributes(families, serviceName, version.Version, e.config.ResourceAttributes)
+	request := EncodeExportRequest(families, resource, Scope{Name: scopeName, Version: version.Version}, e.start, time.Now())
+
+	ctx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	defer cancel()
+
+	err := e.client.Export(ctx, request)
+
+	var partialSuccessErr partialSuccessError
+
+	switch {
+	case err == nil:
+	case errors.As(err, &partialSuccessErr):
+		e.rejected.Add(uint64(max(partialSuccessErr.rejected, 0)))
+
+		e.logger.Warn("OTLP export partially rejected",
+			slog.Any("err", err),
+		)
+	default:
+		e.stats.Failure()
+
+		e.logger.Warn("OTLP export failed",
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	e.stats.Success()
+}
+
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	e.stats.Describe(ch)
+	ch <- e.rejectedDesc
+}
+
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.stats.Collect(ch)
+	ch <- prometheus.MustNewConstMetric(e.rejectedDesc, prometheus.CounterValue, float64(e.rejected.Load()))
+}
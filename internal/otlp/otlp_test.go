@@ -0,0 +1,214 @@
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+	"gopkg.in/yaml.v3"
+)
+
+func newTestExporter(t *testing.T, config string) *Exporter {
+	t.Helper()
+
+	var cfg Config
+
+	require.NoError(t, yaml.Unmarshal([]byte(config), &cfg))
+
+	exporter, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+	require.NoError(t, err)
+
+	return exporter
+}
+
+func newTestGatherer(value float64) prometheus.Gatherer {
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test", Help: "help"})
+	gauge.Set(value)
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(gauge)
+
+	return reg
+}
+
+// gaugeValue returns the value of the test gauge of an ExportMetricsServiceRequest.
+func gaugeValue(t *testing.T, request []byte) float64 {
+	t.Helper()
+
+	_, _, metrics := decodeMetrics(t, request)
+
+	return metrics["test"].message(t, 5).message(t, 1).double(4)
+}
+
+// marshalPartialSuccess encodes an ExportMetricsServiceResponse with a partial success.
+func marshalPartialSuccess(rejected int64, message string) []byte {
+	var partialSuccess []byte
+
+	partialSuccess = protowire.AppendTag(partialSuccess, 1, protowire.VarintType)
+	partialSuccess = protowire.AppendVarint(partialSuccess, uint64(rejected))
+	partialSuccess = protowire.AppendTag(partialSuccess, 2, protowire.BytesType)
+	partialSuccess = protowire.AppendString(partialSuccess, message)
+
+	var b []byte
+
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, partialSuccess)
+
+	return b
+}
+
+func TestExporterHTTP(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		received []float64
+		status   = http.StatusOK
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		require.Equal(t, "/v1/metrics", req.URL.Path)
+		require.Equal(t, "application/x-protobuf", req.Header.Get("Content-Type"))
+		require.Equal(t, "gzip", req.Header.Get("Content-Encoding"))
+		require.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+
+			return
+		}
+
+		gz, err := gzip.NewReader(req.Body)
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(gz)
+		require.NoError(t, err)
+
+		received = append(received, gaugeValue(t, body))
+
+		_, _ = w.Write(marshalPartialSuccess(2, "invalid data points"))
+	}))
+
+	t.Cleanup(server.Close)
+
+	exporter := newTestExporter(t, `
+endpoint: `+server.URL+`/v1/metrics
+compression: gzip
+authorization:
+  credentials: secret
+`)
+
+	exporter.Export(context.Background(), newTestGatherer(1))
+
+	mu.Lock()
+	status = http.StatusServiceUnavailable
+	mu.Unlock()
+
+	exporter.Export(context.Background(), newTestGatherer(2))
+
+	require.Equal(t, []float64{1}, received)
+	require.Equal(t, uint64(1), exporter.stats.Sent())
+	require.Equal(t, uint64(1), exporter.stats.Failed())
+	require.Equal(t, uint64(2), exporter.rejected.Load())
+}
+
+func TestExporterGRPC(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		received []float64
+		tenant   []string
+	)
+
+	server := grpc.NewServer(
+		grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+			method, _ := grpc.MethodFromServerStream(stream)
+			require.Equal(t, exportMethod, method)
+
+			var request []byte
+			if err := stream.RecvMsg(&request); err != nil {
+				return err
+			}
+
+			md, _ := metadata.FromIncomingContext(stream.Context())
+
+			mu.Lock()
+			received = append(received, gaugeValue(t, request))
+			tenant = md.Get("x-scope-orgid")
+			mu.Unlock()
+
+			response := []byte{}
+
+			return stream.SendMsg(&response)
+		}),
+	)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	t.Cleanup(server.Stop)
+
+	exporter := newTestExporter(t, `
+endpoint: http://`+listener.Addr().String()+`
+protocol: grpc
+headers:
+  X-Scope-OrgID: tenant-1
+`)
+
+	t.Cleanup(func() {
+		require.NoError(t, exporter.client.Close())
+	})
+
+	exporter.Export(context.Background(), newTestGatherer(3))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Equal(t, []float64{3}, received)
+	require.Equal(t, []string{"tenant-1"}, tenant)
+	require.Equal(t, uint64(1), exporter.stats.Sent())
+	require.Equal(t, uint64(0), exporter.stats.Failed())
+}
+
+func TestConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		config string
+	}{
+		{name: "missing endpoint", config: `protocol: grpc`},
+		{name: "unsupported scheme", config: `endpoint: ftp://localhost:4317`},
+		{name: "unknown protocol", config: "endpoint: http://localhost:4318\nprotocol: http/json"},
+		{name: "unknown compression", config: "endpoint: http://localhost:4318\ncompression: zstd"},
+		{name: "grpc with basic auth", config: "endpoint: http://localhost:4317\nprotocol: grpc\nbasic_auth:\n  username: user"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var cfg Config
+
+			require.Error(t, yaml.Unmarshal([]byte(tc.config), &cfg))
+		})
+	}
+}
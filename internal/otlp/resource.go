@@ -0,0 +1,87 @@
+package otlp
+
+import (
+	"runtime"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// resourceMetrics maps the labels of the info metrics of the os and cs collectors to the resource attributes of
+// the OpenTelemetry semantic conventions. Metrics listed first take precedence.
+//
+// https://opentelemetry.io/docs/specs/semconv/resource/
+var resourceMetrics = []struct {
+	metric     string
+	attributes map[string]string
+}{
+	{
+		metric: "windows_os_hostname",
+		attributes: map[string]string{
+			"hostname": "host.name",
+			"fqdn":     "service.instance.id",
+		},
+	},
+	{
+		metric: "windows_cs_hostname",
+		attributes: map[string]string{
+			"hostname": "host.name",
+			"fqdn":     "service.instance.id",
+		},
+	},
+	{
+		metric: "windows_os_info",
+		attributes: map[string]string{
+			"product":      "os.description",
+			"version":      "os.version",
+			"build_number": "os.build_id",
+		},
+	},
+}
+
+// ResourceAttributes returns the resource attributes for the metric families. The host and operating system attributes
+// are derived from the info metrics of the os and cs collectors, if enabled. extra overrides all other attributes.
+// The attributes are sorted by key.
+func ResourceAttributes(families []*dto.MetricFamily, serviceName, serviceVersion string, extra map[string]string) []Attribute {
+	values := map[string]string{
+		"os.type":         "windows",
+		"host.arch":       runtime.GOARCH,
+		"service.name":    serviceName,
+		"service.version": serviceVersion,
+	}
+
+	byName := make(map[string]*dto.MetricFamily, len(families))
+	for _, family := range families {
+		byName[family.GetName()] = family
+	}
+
+	for _, resourceMetric := range resourceMetrics {
+		family, ok := byName[resourceMetric.metric]
+		if !ok || len(family.GetMetric()) == 0 {
+			continue
+		}
+
+		for _, labelPair := range family.GetMetric()[0].GetLabel() {
+			attribute, ok := resourceMetric.attributes[labelPair.GetName()]
+			if !ok || labelPair.GetValue() == "" {
+				continue
+			}
+
+			if _, ok := values[attribute]; !ok {
+				values[attribute] = labelPair.GetValue()
+			}
+		}
+	}
+
+	for key, value := range extra {
+		values[key] = value
+	}
+
+	attributes := make([]Attribute, 0, len(values))
+	for key, value := range values {
+		attributes = append(attributes, Attribute{Key: key, Value: value})
+	}
+
+	sortAttributes(attributes)
+
+	return attributes
+}
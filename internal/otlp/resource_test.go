@@ -0,0 +1,65 @@
+package otlp
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/prometheus-community/windows_exporter/internal/testutils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceAttributes(t *testing.T) {
+	t.Parallel()
+
+	families := testutils.Gather(t,
+		prometheus.MustNewConstMetric(
+			prometheus.NewDesc("windows_os_hostname", "", []string{"hostname", "domain", "fqdn"}, nil),
+			prometheus.GaugeValue, 1, "web01", "example.com", "web01.example.com",
+		),
+		prometheus.MustNewConstMetric(
+			prometheus.NewDesc("windows_cs_hostname", "", []string{"hostname", "domain", "fqdn"}, nil),
+			prometheus.GaugeValue, 1, "other", "", "",
+		),
+		prometheus.MustNewConstMetric(
+			prometheus.NewDesc("windows_os_info", "", nil, prometheus.Labels{
+				"product":       "Microsoft Windows Server 2022 Standard",
+				"version":       "10.0.20348",
+				"major_version": "10",
+				"build_number":  "20348",
+			}),
+			prometheus.GaugeValue, 1,
+		),
+	)
+
+	attributes := ResourceAttributes(families, "windows_exporter", "1.0.0", map[string]string{
+		"deployment.environment": "prod",
+		"service.name":           "custom",
+	})
+
+	require.Equal(t, []Attribute{
+		{Key: "deployment.environment", Value: "prod"},
+		{Key: "host.arch", Value: runtime.GOARCH},
+		{Key: "host.name", Value: "web01"},
+		{Key: "os.build_id", Value: "20348"},
+		{Key: "os.description", Value: "Microsoft Windows Server 2022 Standard"},
+		{Key: "os.type", Value: "windows"},
+		{Key: "os.version", Value: "10.0.20348"},
+		{Key: "service.instance.id", Value: "web01.example.com"},
+		{Key: "service.name", Value: "custom"},
+		{Key: "service.version", Value: "1.0.0"},
+	}, attributes)
+}
+
+func TestResourceAttributesWithoutCollectors(t *testing.T) {
+	t.Parallel()
+
+	attributes := ResourceAttributes(nil, "windows_exporter", "1.0.0", nil)
+
+	require.Equal(t, []Attribute{
+		{Key: "host.arch", Value: runtime.GOARCH},
+		{Key: "os.type", Value: "windows"},
+		{Key: "service.name", Value: "windows_exporter"},
+		{Key: "service.version", Value: "1.0.0"},
+	}, attributes)
+}
@@ -1,6 +1,7 @@
 package perfdata
 
 import (
+	"context"
 	"errors"
 
 	"github.com/prometheus-community/windows_exporter/internal/perfdata/perftypes"
@@ -10,7 +11,8 @@ import (
 
 type Collector interface {
 	Describe() map[string]string
-	Collect() (map[string]map[string]perftypes.CounterValues, error)
+	// Collect queries the performance counters. If ctx is done, the collection is stopped and the error of ctx is returned.
+	Collect(ctx context.Context) (map[string]map[string]perftypes.CounterValues, error)
 	Close()
 }
 
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -27,7 +28,7 @@ func NewCollector(object string, _ []string, _ []string) (*Collector, error) {
 		query:  MapCounterToIndex(object),
 	}
 
-	if _, err := collector.Collect(); err != nil {
+	if _, err := collector.Collect(context.Background()); err != nil {
 		return nil, fmt.Errorf("failed to collect initial data: %w", err)
 	}
 
@@ -38,7 +39,11 @@ func (c *Collector) Describe() map[string]string {
 	return map[string]string{}
 }
 
-func (c *Collector) Collect() (map[string]map[string]perftypes.CounterValues, error) {
+func (c *Collector) Collect(ctx context.Context) (map[string]map[string]perftypes.CounterValues, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	perfObjects, err := QueryPerformanceData(c.query, c.object)
 	if err != nil {
 		return nil, fmt.Errorf("QueryPerformanceData: %w", err)
@@ -3,6 +3,7 @@
 package v2
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -101,7 +102,7 @@ func NewCollector(object string, instances []string, counters []string) (*Collec
 		return nil, errors.New("no counters configured")
 	}
 
-	if _, err := collector.Collect(); err != nil {
+	if _, err := collector.Collect(context.Background()); err != nil {
 		return nil, fmt.Errorf("failed to collect initial data: %w", err)
 	}
 
@@ -118,11 +119,15 @@ func (c *Collector) Describe() map[string]string {
 	return desc
 }
 
-func (c *Collector) Collect() (map[string]map[string]perftypes.CounterValues, error) {
+func (c *Collector) Collect(ctx context.Context) (map[string]map[string]perftypes.CounterValues, error) {
 	if len(c.counters) == 0 {
 		return map[string]map[string]perftypes.CounterValues{}, nil
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	if ret := PdhCollectQueryData(c.handle); ret != ErrorSuccess {
 		return nil, fmt.Errorf("failed to collect query data: %w", NewPdhError(ret))
 	}
@@ -130,6 +135,11 @@ func (c *Collector) Collect() (map[string]map[string]perftypes.CounterValues, er
 	var data map[string]map[string]perftypes.CounterValues
 
 	for _, counter := range c.counters {
+		// PDH calls can't be interrupted. Stop between the counters instead.
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		for _, instance := range counter.Instances {
 			// Get the info with the current buffer size
 			var itemCount uint32
@@ -1,6 +1,7 @@
 package v2_test
 
 import (
+	"context"
 	"testing"
 
 	v2 "github.com/prometheus-community/windows_exporter/internal/perfdata/v2"
@@ -42,7 +43,7 @@ func BenchmarkTestCollector(b *testing.B) {
 	require.NoError(b, err)
 
 	for i := 0; i < b.N; i++ {
-		_, _ = performanceData.Collect()
+		_, _ = performanceData.Collect(context.Background())
 	}
 
 	performanceData.Close()
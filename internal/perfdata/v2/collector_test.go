@@ -3,6 +3,7 @@
 package v2_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -66,7 +67,7 @@ func TestCollector(t *testing.T) {
 
 			time.Sleep(100 * time.Millisecond)
 
-			data, err := performanceData.Collect()
+			data, err := performanceData.Collect(context.Background())
 			require.NoError(t, err)
 			require.NotEmpty(t, data)
 
@@ -0,0 +1,252 @@
+// Package pushgateway pushes metrics to a Prometheus Pushgateway. It is intended for short-living hosts,
+// which disappear before they can be scraped.
+package pushgateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/sink"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/config"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	MethodPut  = "put"
+	MethodPost = "post"
+)
+
+const (
+	defaultJob      = "windows_exporter"
+	defaultInterval = time.Minute
+	defaultTimeout  = 10 * time.Second
+
+	operationPush   = "push"
+	operationDelete = "delete"
+)
+
+// Config is the configuration of the Pushgateway push mode.
+type Config struct {
+	// URL of the Pushgateway, e.g. http://pushgateway:9091.
+	URL string `yaml:"url"`
+	// Job is the job label of the pushed metrics. Defaults to windows_exporter.
+	Job string `yaml:"job"`
+	// Grouping are the labels of the grouping key in addition to the job. Defaults to the instance label
+	// with the hostname.
+	Grouping map[string]string `yaml:"grouping"`
+	// Method is either put or post. put replaces all metrics of the group, post replaces only the metrics
+	// with the same name. Defaults to put.
+	Method string `yaml:"method"`
+	// Interval between two pushes. Defaults to 1m.
+	Interval time.Duration `yaml:"interval"`
+	// Timeout of a single request. Defaults to 10s.
+	Timeout time.Duration `yaml:"timeout"`
+	// DeleteOnShutdown deletes the group on shutdown after the last push. Defaults to true.
+	DeleteOnShutdown bool `yaml:"delete_on_shutdown"`
+	// HTTPClientConfig configures authentication and TLS.
+	HTTPClientConfig config.HTTPClientConfig `yaml:",inline"`
+}
+
+// UnmarshalYAML applies the defaults and validates the configuration.
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	type plain Config
+
+	*c = Config{
+		Job:              defaultJob,
+		Method:           MethodPut,
+		DeleteOnShutdown: true,
+		HTTPClientConfig: config.DefaultHTTPClientConfig,
+	}
+
+	if err := value.Decode((*plain)(c)); err != nil {
+		return err
+	}
+
+	if _, err := url.ParseRequestURI(c.URL); err != nil {
+		return fmt.Errorf("invalid pushgateway url %q: %w", c.URL, err)
+	}
+
+	if c.Job == "" {
+		return errors.New("pushgateway job must not be empty")
+	}
+
+	if c.Method != MethodPut && c.Method != MethodPost {
+		return fmt.Errorf("unknown pushgateway method %q, must be %s or %s", c.Method, MethodPut, MethodPost)
+	}
+
+	return c.HTTPClientConfig.Validate()
+}
+
+// Enabled returns true, if a Pushgateway is configured.
+func (c Config) Enabled() bool {
+	return c.URL != ""
+}
+
+// Interface guard.
+var _ prometheus.Collector = (*Pusher)(nil)
+
+// Pusher periodically gathers metrics and pushes them to the Pushgateway.
+type Pusher struct {
+	logger *slog.Logger
+	config Config
+	client *http.Client
+	// newPush returns a push.Pusher for the job and the grouping key.
+	newPush func() *push.Pusher
+
+	// stats holds the Stats per operation.
+	stats map[string]*sink.Stats
+}
+
+// New returns a Pusher for the configuration. The Pusher has to be started by Run.
+func New(logger *slog.Logger, cfg Config) (*Pusher, error) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	grouping := cfg.Grouping
+	if len(grouping) == 0 {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname for the pushgateway grouping key: %w", err)
+		}
+
+		grouping = map[string]string{"instance": hostname}
+	}
+
+	client, err := config.NewClientFromConfig(cfg.HTTPClientConfig, "pushgateway")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	client.Timeout = cfg.Timeout
+
+	p := &Pusher{
+		logger: logger,
+		config: cfg,
+		client: client,
+		newPush: func() *push.Pusher {
+			pusher := push.New(cfg.URL, cfg.Job).Client(client)
+			for name, value := range grouping {
+				pusher = pusher.Grouping(name, value)
+			}
+
+			return pusher
+		},
+		stats: map[string]*sink.Stats{
+			operationPush:   sink.NewStats("pushgateway", "requests", "the Pushgateway", prometheus.Labels{"operation": operationPush}),
+			operationDelete: sink.NewStats("pushgateway", "requests", "the Pushgateway", prometheus.Labels{"operation": operationDelete}),
+		},
+	}
+
+	return p, nil
+}
+
+// Run pushes the metrics of gatherer on every interval until ctx is done. Afterward, the metrics are pushed
+// a last time and the group is deleted, if DeleteOnShutdown is set.
+func (p *Pusher) Run(ctx context.Context, gatherer prometheus.Gatherer) {
+	sink.Run(ctx, p.config.Interval, func(ctx context.Context) {
+		p.Push(ctx, gatherer)
+	})
+
+	p.shutdown(gatherer)
+}
+
+func (p *Pusher) shutdown(gatherer prometheus.Gatherer) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+	defer cancel()
+
+	p.Push(ctx, gatherer)
+
+	if !p.config.DeleteOnShutdown {
+		return
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), p.config.Timeout)
+	defer cancel()
+
+	if err := p.Delete(ctx); err == nil {
+		p.logger.Info("deleted pushgateway group")
+	}
+}
+
+// Push gathers the metrics once and pushes them to the Pushgateway.
+func (p *Pusher) Push(ctx context.Context, gatherer prometheus.Gatherer) {
+	var err error
+
+	families := sink.Gather(p.logger, gatherer)
+
+	pusher := p.newPush().Gatherer(prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return families, nil
+	}))
+
+	if p.config.Method == MethodPost {
+		err = pusher.AddContext(ctx)
+	} else {
+		err = pusher.PushContext(ctx)
+	}
+
+	if err != nil {
+		p.stats[operationPush].Failure()
+
+		p.logger.Warn("push to pushgateway failed",
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	p.stats[operationPush].Success()
+}
+
+// Delete deletes the group of the Pusher from the Pushgateway.
+func (p *Pusher) Delete(ctx context.Context) error {
+	// push.Pusher.Delete doesn't accept a context, so the context is attached to the request by the client.
+	if err := p.newPush().Client(contextDoer{ctx: ctx, client: p.client}).Delete(); err != nil {
+		p.stats[operationDelete].Failure()
+
+		p.logger.Warn("failed to delete pushgateway group",
+			slog.Any("err", err),
+		)
+
+		return err
+	}
+
+	p.stats[operationDelete].Success()
+
+	return nil
+}
+
+// contextDoer sends the requests with ctx.
+type contextDoer struct {
+	ctx    context.Context //nolint:containedctx
+	client push.HTTPDoer
+}
+
+func (d contextDoer) Do(req *http.Request) (*http.Response, error) {
+	return d.client.Do(req.WithContext(d.ctx))
+}
+
+func (p *Pusher) Describe(ch chan<- *prometheus.Desc) {
+	for _, stats := range p.stats {
+		stats.Describe(ch)
+	}
+}
+
+func (p *Pusher) Collect(ch chan<- prometheus.Metric) {
+	for _, stats := range p.stats {
+		stats.Collect(ch)
+	}
+}
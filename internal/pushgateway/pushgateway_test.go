@@ -0,0 +1,200 @@
+package pushgateway
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// gateway records the requests received by a Pushgateway.
+type gateway struct {
+	mu       sync.Mutex
+	requests []string
+	status   int
+}
+
+func (g *gateway) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	_, _ = io.Copy(io.Discard, req.Body)
+
+	g.requests = append(g.requests, req.Method+" "+req.URL.Path)
+
+	if g.status != 0 {
+		w.WriteHeader(g.status)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (g *gateway) received() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return append([]string(nil), g.requests...)
+}
+
+func newTestPusher(t *testing.T, config string) (*Pusher, *gateway) {
+	t.Helper()
+
+	gw := &gateway{}
+	server := httptest.NewServer(gw)
+
+	t.Cleanup(server.Close)
+
+	var cfg Config
+
+	require.NoError(t, yaml.Unmarshal([]byte("url: "+server.URL+"\n"+config), &cfg))
+
+	pusher, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+	require.NoError(t, err)
+
+	return pusher, gw
+}
+
+func newTestGatherer() prometheus.Gatherer {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: "test", Help: "help"}))
+
+	return reg
+}
+
+// runUntilFirstPush runs the pusher and stops it after the first request arrived at the gateway.
+func runUntilFirstPush(t *testing.T, pusher *Pusher, gw *gateway) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		pusher.Run(ctx, newTestGatherer())
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(gw.received()) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestPushDefaultGrouping(t *testing.T) {
+	t.Parallel()
+
+	pusher, gw := newTestPusher(t, "")
+
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	pusher.Push(context.Background(), newTestGatherer())
+
+	require.Equal(t, []string{"PUT /metrics/job/windows_exporter/instance/" + hostname}, gw.received())
+	require.Equal(t, uint64(1), pusher.stats[operationPush].Sent())
+}
+
+func TestRunPushesOnShutdown(t *testing.T) {
+	t.Parallel()
+
+	pusher, gw := newTestPusher(t, `
+job: build
+method: post
+interval: 1h
+grouping:
+  agent: agent-1
+`)
+
+	runUntilFirstPush(t, pusher, gw)
+
+	// By default, the group is deleted after the last push.
+	require.Equal(t, []string{
+		"POST /metrics/job/build/agent/agent-1",
+		"POST /metrics/job/build/agent/agent-1",
+		"DELETE /metrics/job/build/agent/agent-1",
+	}, gw.received())
+	require.Equal(t, uint64(1), pusher.stats[operationDelete].Sent())
+}
+
+func TestRunKeepsGroupOnShutdown(t *testing.T) {
+	t.Parallel()
+
+	pusher, gw := newTestPusher(t, `
+interval: 1h
+delete_on_shutdown: false
+grouping:
+  instance: agent-1
+`)
+
+	runUntilFirstPush(t, pusher, gw)
+
+	require.Equal(t, []string{
+		"PUT /metrics/job/windows_exporter/instance/agent-1",
+		"PUT /metrics/job/windows_exporter/instance/agent-1",
+	}, gw.received())
+	require.Equal(t, uint64(0), pusher.stats[operationDelete].Sent())
+}
+
+func TestDeleteContext(t *testing.T) {
+	t.Parallel()
+
+	pusher, gw := newTestPusher(t, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.ErrorIs(t, pusher.Delete(ctx), context.Canceled)
+	require.Empty(t, gw.received())
+	require.Equal(t, uint64(1), pusher.stats[operationDelete].Failed())
+}
+
+func TestPushErrors(t *testing.T) {
+	t.Parallel()
+
+	pusher, gw := newTestPusher(t, "")
+
+	gw.mu.Lock()
+	gw.status = http.StatusInternalServerError
+	gw.mu.Unlock()
+
+	pusher.Push(context.Background(), newTestGatherer())
+	require.Error(t, pusher.Delete(context.Background()))
+
+	// Metrics must not contain the labels of the grouping key.
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test", Help: "help"}, []string{"instance"}))
+	reg.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_instance", Help: "help", ConstLabels: prometheus.Labels{"instance": "x"}}))
+
+	pusher.Push(context.Background(), reg)
+
+	require.Len(t, gw.received(), 2)
+	require.Equal(t, uint64(2), pusher.stats[operationPush].Failed())
+	require.Equal(t, uint64(1), pusher.stats[operationDelete].Failed())
+	require.Equal(t, uint64(0), pusher.stats[operationPush].Sent())
+}
+
+func TestConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []string{
+		`job: test`,
+		"url: http://localhost:9091\nmethod: patch",
+		"url: http://localhost:9091\njob: \"\"",
+	} {
+		var cfg Config
+
+		require.Error(t, yaml.Unmarshal([]byte(config), &cfg), config)
+	}
+}
@@ -0,0 +1,248 @@
+// Package relabel implements relabeling rules for metrics, modeled after the
+// metric_relabel_configs of Prometheus.
+package relabel
+
+import (
+	"crypto/md5" //nolint:gosec // md5 is used for sharding, not for security.
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// MetricNameLabel is the label name, which holds the name of the metric.
+const MetricNameLabel = "__name__"
+
+type Action string
+
+const (
+	// Replace sets target_label to replacement, if regex matches the concatenated source_labels.
+	Replace Action = "replace"
+	// Keep drops metrics, for which regex does not match the concatenated source_labels.
+	Keep Action = "keep"
+	// Drop drops metrics, for which regex matches the concatenated source_labels.
+	Drop Action = "drop"
+	// LabelDrop removes all labels, whose names match regex.
+	LabelDrop Action = "labeldrop"
+	// HashMod sets target_label to the modulus of a hash of the concatenated source_labels.
+	HashMod Action = "hashmod"
+)
+
+var (
+	labelNameRegExp  = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+	metricNameRegExp = regexp.MustCompile("^[a-zA-Z_:][a-zA-Z0-9_:]*$")
+)
+
+// Config is a single relabeling rule.
+type Config struct {
+	// SourceLabels are the labels, whose values are concatenated and matched against Regex.
+	SourceLabels []string `yaml:"source_labels"`
+	// Separator is placed between the concatenated source label values. Defaults to ";".
+	Separator *string `yaml:"separator"`
+	// Regex is matched against the concatenated source label values. The regex is anchored on both ends.
+	// Defaults to "(.*)".
+	Regex *string `yaml:"regex"`
+	// Modulus is the modulus of the hashmod action.
+	Modulus uint64 `yaml:"modulus"`
+	// TargetLabel is the label, which is written by the replace and hashmod actions.
+	TargetLabel string `yaml:"target_label"`
+	// Replacement is the value written to TargetLabel by the replace action. Defaults to "$1".
+	Replacement *string `yaml:"replacement"`
+	// Action to perform. Defaults to replace.
+	Action Action `yaml:"action"`
+}
+
+type rule struct {
+	sourceLabels []string
+	separator    string
+	regex        *regexp.Regexp
+	modulus      uint64
+	targetLabel  string
+	replacement  string
+	action       Action
+
+	dropped atomic.Uint64
+}
+
+// Rules is a list of relabeling rules, which are applied in order.
+type Rules struct {
+	rules []*rule
+}
+
+// New validates the configs and returns the corresponding Rules.
+func New(configs []Config) (*Rules, error) {
+	rules := make([]*rule, 0, len(configs))
+	errs := make([]error, 0)
+
+	for i, config := range configs {
+		r, err := newRule(config)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid relabel config %d: %w", i, err))
+
+			continue
+		}
+
+		rules = append(rules, r)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return &Rules{rules: rules}, nil
+}
+
+func newRule(config Config) (*rule, error) {
+	r := &rule{
+		sourceLabels: config.SourceLabels,
+		separator:    ";",
+		modulus:      config.Modulus,
+		targetLabel:  config.TargetLabel,
+		replacement:  "$1",
+		action:       config.Action,
+	}
+
+	regex := "(.*)"
+
+	if config.Separator != nil {
+		r.separator = *config.Separator
+	}
+
+	if config.Regex != nil {
+		regex = *config.Regex
+	}
+
+	if config.Replacement != nil {
+		r.replacement = *config.Replacement
+	}
+
+	if r.action == "" {
+		r.action = Replace
+	}
+
+	var err error
+
+	r.regex, err = regexp.Compile("^(?:" + regex + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", regex, err)
+	}
+
+	switch r.action {
+	case Replace:
+		if r.targetLabel == "" {
+			return nil, fmt.Errorf("target_label is required for action %s", r.action)
+		}
+	case HashMod:
+		if r.targetLabel == "" {
+			return nil, fmt.Errorf("target_label is required for action %s", r.action)
+		}
+
+		if r.modulus == 0 {
+			return nil, fmt.Errorf("modulus is required for action %s", r.action)
+		}
+	case Keep, Drop:
+		if len(r.sourceLabels) == 0 {
+			return nil, fmt.Errorf("source_labels are required for action %s", r.action)
+		}
+	case LabelDrop:
+	default:
+		return nil, fmt.Errorf("unknown action %q", r.action)
+	}
+
+	return r, nil
+}
+
+// Len returns the number of rules.
+func (r *Rules) Len() int {
+	return len(r.rules)
+}
+
+// Action returns the action of the i-th rule.
+func (r *Rules) Action(i int) Action {
+	return r.rules[i].action
+}
+
+// Dropped returns the number of series dropped by the i-th rule.
+func (r *Rules) Dropped(i int) uint64 {
+	return r.rules[i].dropped.Load()
+}
+
+// Process applies the rules to labels, which includes the metric name as MetricNameLabel.
+// labels is modified in place. Process returns false, if the series is dropped.
+func (r *Rules) Process(labels map[string]string) bool {
+	for _, rule := range r.rules {
+		if !rule.process(labels) {
+			rule.dropped.Add(1)
+
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *rule) process(labels map[string]string) bool {
+	values := make([]string, 0, len(r.sourceLabels))
+	for _, name := range r.sourceLabels {
+		values = append(values, labels[name])
+	}
+
+	value := strings.Join(values, r.separator)
+
+	switch r.action {
+	case Keep:
+		return r.regex.MatchString(value)
+	case Drop:
+		return !r.regex.MatchString(value)
+	case LabelDrop:
+		for name := range labels {
+			if name != MetricNameLabel && r.regex.MatchString(name) {
+				delete(labels, name)
+			}
+		}
+	case HashMod:
+		hash := md5.Sum([]byte(value)) //nolint:gosec // md5 is used for sharding, not for security.
+
+		// Use only the last 8 bytes of the hash, to give the same result as Prometheus.
+		labels[r.targetLabel] = strconv.FormatUint(binary.BigEndian.Uint64(hash[8:])%r.modulus, 10)
+	case Replace:
+		indexes := r.regex.FindStringSubmatchIndex(value)
+		if indexes == nil {
+			break
+		}
+
+		target := string(r.regex.ExpandString([]byte{}, r.targetLabel, value, indexes))
+		if !isValidTarget(target) {
+			break
+		}
+
+		replacement := string(r.regex.ExpandString([]byte{}, r.replacement, value, indexes))
+		if replacement == "" {
+			if target != MetricNameLabel {
+				delete(labels, target)
+			}
+
+			break
+		}
+
+		labels[target] = replacement
+	}
+
+	return true
+}
+
+func isValidTarget(target string) bool {
+	if target == MetricNameLabel {
+		return true
+	}
+
+	return labelNameRegExp.MatchString(target)
+}
+
+// IsValidMetricName returns true, if name is a valid metric name.
+func IsValidMetricName(name string) bool {
+	return metricNameRegExp.MatchString(name)
+}
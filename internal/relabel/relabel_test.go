@@ -0,0 +1,122 @@
+package relabel_test
+
+import (
+	"testing"
+
+	"github.com/prometheus-community/windows_exporter/internal/relabel"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestRules(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		configs  []relabel.Config
+		labels   map[string]string
+		expected map[string]string
+	}{
+		{
+			name: "drop",
+			configs: []relabel.Config{
+				{SourceLabels: []string{"__name__"}, Regex: ptr("windows_service_info"), Action: relabel.Drop},
+			},
+			labels:   map[string]string{"__name__": "windows_service_info", "name": "wuauserv"},
+			expected: nil,
+		},
+		{
+			name: "drop not matching",
+			configs: []relabel.Config{
+				{SourceLabels: []string{"__name__"}, Regex: ptr("windows_service_info"), Action: relabel.Drop},
+			},
+			labels:   map[string]string{"__name__": "windows_service_state", "name": "wuauserv"},
+			expected: map[string]string{"__name__": "windows_service_state", "name": "wuauserv"},
+		},
+		{
+			name: "keep",
+			configs: []relabel.Config{
+				{SourceLabels: []string{"__name__", "name"}, Regex: ptr("windows_service_.+;wuauserv"), Action: relabel.Keep},
+			},
+			labels:   map[string]string{"__name__": "windows_service_state", "name": "spooler"},
+			expected: nil,
+		},
+		{
+			name: "replace",
+			configs: []relabel.Config{
+				{SourceLabels: []string{"volume"}, Regex: ptr("([A-Z]):"), TargetLabel: "drive", Replacement: ptr("${1}")},
+			},
+			labels:   map[string]string{"__name__": "windows_logical_disk_free_bytes", "volume": "C:"},
+			expected: map[string]string{"__name__": "windows_logical_disk_free_bytes", "volume": "C:", "drive": "C"},
+		},
+		{
+			name: "replace metric name",
+			configs: []relabel.Config{
+				{SourceLabels: []string{"__name__"}, Regex: ptr("windows_(.+)"), TargetLabel: "__name__", Replacement: ptr("win_$1")},
+			},
+			labels:   map[string]string{"__name__": "windows_cpu_time_total"},
+			expected: map[string]string{"__name__": "win_cpu_time_total"},
+		},
+		{
+			name: "replace with empty value removes label",
+			configs: []relabel.Config{
+				{SourceLabels: []string{"path_name"}, Regex: ptr(".*"), TargetLabel: "path_name", Replacement: ptr("")},
+			},
+			labels:   map[string]string{"__name__": "windows_service_info", "path_name": `C:\Windows\system32\svchost.exe`},
+			expected: map[string]string{"__name__": "windows_service_info"},
+		},
+		{
+			name: "labeldrop",
+			configs: []relabel.Config{
+				{Regex: ptr("path_name|run_as"), Action: relabel.LabelDrop},
+			},
+			labels:   map[string]string{"__name__": "windows_service_info", "name": "wuauserv", "path_name": "svchost.exe", "run_as": "LocalSystem"},
+			expected: map[string]string{"__name__": "windows_service_info", "name": "wuauserv"},
+		},
+		{
+			name: "hashmod",
+			configs: []relabel.Config{
+				{SourceLabels: []string{"name"}, Modulus: 8, TargetLabel: "shard", Action: relabel.HashMod},
+			},
+			labels:   map[string]string{"__name__": "windows_service_info", "name": "wuauserv"},
+			expected: map[string]string{"__name__": "windows_service_info", "name": "wuauserv", "shard": "3"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rules, err := relabel.New(tc.configs)
+			require.NoError(t, err)
+
+			keep := rules.Process(tc.labels)
+			if tc.expected == nil {
+				require.False(t, keep)
+				require.Equal(t, uint64(1), rules.Dropped(0))
+
+				return
+			}
+
+			require.True(t, keep)
+			require.Equal(t, tc.expected, tc.labels)
+			require.Equal(t, uint64(0), rules.Dropped(0))
+		})
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	for _, config := range []relabel.Config{
+		{Action: "unknown"},
+		{Regex: ptr("("), Action: relabel.LabelDrop},
+		{SourceLabels: []string{"name"}},
+		{SourceLabels: []string{"name"}, TargetLabel: "shard", Action: relabel.HashMod},
+		{Action: relabel.Drop},
+	} {
+		_, err := relabel.New([]relabel.Config{config})
+		require.Error(t, err, "config: %+v", config)
+	}
+}
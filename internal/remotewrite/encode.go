@@ -0,0 +1,235 @@
+package remotewrite
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// metricType is the MetricType enum of the remote write protocol.
+type metricType uint64
+
+const (
+	metricTypeUnknown   metricType = 0
+	metricTypeCounter   metricType = 1
+	metricTypeGauge     metricType = 2
+	metricTypeHistogram metricType = 3
+	metricTypeSummary   metricType = 5
+)
+
+type label struct {
+	name  string
+	value string
+}
+
+type sample struct {
+	value     float64
+	timestamp int64
+}
+
+type timeSeries struct {
+	labels  []label
+	samples []sample
+}
+
+type metricMetadata struct {
+	metricType metricType
+	name       string
+	help       string
+}
+
+// EncodeWriteRequest converts the metric families into a remote write request and returns the protobuf encoding
+// of the request. Metrics without a timestamp get the timestamp now. Summaries and histograms are split into
+// the series of the classic Prometheus representation.
+func EncodeWriteRequest(families []*dto.MetricFamily, now time.Time) []byte {
+	series := make([]timeSeries, 0, len(families))
+	metadata := make([]metricMetadata, 0, len(families))
+
+	for _, family := range families {
+		series = appendTimeSeries(series, family, now.UnixMilli())
+		metadata = append(metadata, metricMetadata{
+			metricType: toMetricType(family.GetType()),
+			name:       family.GetName(),
+			help:       family.GetHelp(),
+		})
+	}
+
+	return marshalWriteRequest(series, metadata)
+}
+
+func toMetricType(t dto.MetricType) metricType {
+	switch t {
+	case dto.MetricType_COUNTER:
+		return metricTypeCounter
+	case dto.MetricType_GAUGE:
+		return metricTypeGauge
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		return metricTypeHistogram
+	case dto.MetricType_SUMMARY:
+		return metricTypeSummary
+	case dto.MetricType_UNTYPED:
+		return metricTypeUnknown
+	default:
+		return metricTypeUnknown
+	}
+}
+
+func appendTimeSeries(series []timeSeries, family *dto.MetricFamily, nowMs int64) []timeSeries {
+	name := family.GetName()
+
+	for _, metric := range family.GetMetric() {
+		timestamp := nowMs
+		if metric.TimestampMs != nil {
+			timestamp = metric.GetTimestampMs()
+		}
+
+		add := func(name string, value float64, extraLabels ...label) {
+			series = append(series, newTimeSeries(name, metric.GetLabel(), extraLabels, value, timestamp))
+		}
+
+		switch family.GetType() {
+		case dto.MetricType_COUNTER:
+			add(name, metric.GetCounter().GetValue())
+		case dto.MetricType_GAUGE:
+			add(name, metric.GetGauge().GetValue())
+		case dto.MetricType_UNTYPED:
+			add(name, metric.GetUntyped().GetValue())
+		case dto.MetricType_SUMMARY:
+			summary := metric.GetSummary()
+
+			for _, quantile := range summary.GetQuantile() {
+				add(name, quantile.GetValue(), label{name: "quantile", value: formatFloat(quantile.GetQuantile())})
+			}
+
+			add(name+"_sum", summary.GetSampleSum())
+			add(name+"_count", float64(summary.GetSampleCount()))
+		case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+			histogram := metric.GetHistogram()
+			hasInf := false
+
+			for _, bucket := range histogram.GetBucket() {
+				if math.IsInf(bucket.GetUpperBound(), +1) {
+					hasInf = true
+				}
+
+				add(name+"_bucket", float64(bucket.GetCumulativeCount()), label{name: "le", value: formatFloat(bucket.GetUpperBound())})
+			}
+
+			if !hasInf {
+				add(name+"_bucket", float64(histogram.GetSampleCount()), label{name: "le", value: "+Inf"})
+			}
+
+			add(name+"_sum", histogram.GetSampleSum())
+			add(name+"_count", float64(histogram.GetSampleCount()))
+		}
+	}
+
+	return series
+}
+
+// newTimeSeries returns a series with a single sample. The labels are sorted by name, as required by the protocol.
+func newTimeSeries(name string, labelPairs []*dto.LabelPair, extraLabels []label, value float64, timestamp int64) timeSeries {
+	labels := make([]label, 0, len(labelPairs)+len(extraLabels)+1)
+	labels = append(labels, label{name: "__name__", value: name})
+
+	for _, labelPair := range labelPairs {
+		labels = append(labels, label{name: labelPair.GetName(), value: labelPair.GetValue()})
+	}
+
+	labels = append(labels, extraLabels...)
+
+	slices.SortFunc(labels, func(a, b label) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return timeSeries{
+		labels:  labels,
+		samples: []sample{{value: value, timestamp: timestamp}},
+	}
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, +1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+// marshalWriteRequest encodes a WriteRequest message.
+//
+// https://github.com/prometheus/prometheus/blob/main/prompb/remote.proto
+func marshalWriteRequest(series []timeSeries, metadata []metricMetadata) []byte {
+	var b []byte
+
+	for _, ts := range series {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, ts.marshal())
+	}
+
+	for _, md := range metadata {
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendBytes(b, md.marshal())
+	}
+
+	return b
+}
+
+// marshal encodes a TimeSeries message.
+//
+// https://github.com/prometheus/prometheus/blob/main/prompb/types.proto
+func (ts timeSeries) marshal() []byte {
+	var b []byte
+
+	for _, l := range ts.labels {
+		var lb []byte
+
+		lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.name)
+		lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.value)
+
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, lb)
+	}
+
+	for _, s := range ts.samples {
+		var sb []byte
+
+		sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+		sb = protowire.AppendFixed64(sb, math.Float64bits(s.value))
+		sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+		sb = protowire.AppendVarint(sb, uint64(s.timestamp))
+
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, sb)
+	}
+
+	return b
+}
+
+// marshal encodes a MetricMetadata message.
+//
+// https://github.com/prometheus/prometheus/blob/main/prompb/types.proto
+func (md metricMetadata) marshal() []byte {
+	var b []byte
+
+	b = protowire.AppendTag(b, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(md.metricType))
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendString(b, md.name)
+	b = protowire.AppendTag(b, 4, protowire.BytesType)
+	b = protowire.AppendString(b, md.help)
+
+	return b
+}
@@ -0,0 +1,153 @@
+package remotewrite
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodeWriteRequest decodes the time series of a WriteRequest into a map of the series, formatted as
+// name{label="value",...}, to its sample.
+func decodeWriteRequest(t *testing.T, b []byte) map[string]sample {
+	t.Helper()
+
+	series := make(map[string]sample)
+
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+
+		if num != 1 {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			require.GreaterOrEqual(t, n, 0)
+			b = b[n:]
+
+			continue
+		}
+
+		ts, n := protowire.ConsumeBytes(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+
+		var (
+			name, labels string
+			s            sample
+		)
+
+		for len(ts) > 0 {
+			num, _, n := protowire.ConsumeTag(ts)
+			ts = ts[n:]
+
+			field, n := protowire.ConsumeBytes(ts)
+			ts = ts[n:]
+
+			values := map[protowire.Number][]byte{}
+
+			for len(field) > 0 {
+				fieldNum, fieldType, n := protowire.ConsumeTag(field)
+				field = field[n:]
+
+				switch fieldType {
+				case protowire.BytesType:
+					v, n := protowire.ConsumeBytes(field)
+					values[fieldNum] = v
+					field = field[n:]
+				case protowire.Fixed64Type:
+					v, n := protowire.ConsumeFixed64(field)
+					s.value = math.Float64frombits(v)
+					field = field[n:]
+				case protowire.VarintType:
+					v, n := protowire.ConsumeVarint(field)
+					s.timestamp = int64(v)
+					field = field[n:]
+				default:
+					t.Fatalf("unexpected wire type %d", fieldType)
+				}
+			}
+
+			if num == 1 {
+				labelName, labelValue := string(values[1]), string(values[2])
+				if labelName == "__name__" {
+					name = labelValue
+
+					continue
+				}
+
+				if labels != "" {
+					labels += ","
+				}
+
+				labels += labelName + `="` + labelValue + `"`
+			}
+		}
+
+		series[name+"{"+labels+"}"] = s
+	}
+
+	return series
+}
+
+func TestEncodeWriteRequest(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_total", Help: "help"}, []string{"b", "a"})
+	counter.WithLabelValues("2", "1").Add(3)
+
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_seconds", Help: "help", Buckets: []float64{0.5, 1}})
+	histogram.Observe(0.7)
+	histogram.Observe(2)
+
+	summary := prometheus.NewSummary(prometheus.SummaryOpts{Name: "test_summary", Help: "help", Objectives: map[float64]float64{0.5: 0.01}})
+	summary.Observe(4)
+
+	reg.MustRegister(counter, histogram, summary)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	now := time.UnixMilli(1700000000000)
+
+	series := decodeWriteRequest(t, EncodeWriteRequest(families, now))
+
+	require.Equal(t, map[string]sample{
+		`test_total{a="1",b="2"}`:        {value: 3, timestamp: now.UnixMilli()},
+		`test_seconds_bucket{le="0.5"}`:  {value: 0, timestamp: now.UnixMilli()},
+		`test_seconds_bucket{le="1"}`:    {value: 1, timestamp: now.UnixMilli()},
+		`test_seconds_bucket{le="+Inf"}`: {value: 2, timestamp: now.UnixMilli()},
+		`test_seconds_sum{}`:             {value: 2.7, timestamp: now.UnixMilli()},
+		`test_seconds_count{}`:           {value: 2, timestamp: now.UnixMilli()},
+		`test_summary{quantile="0.5"}`:   {value: 4, timestamp: now.UnixMilli()},
+		`test_summary_sum{}`:             {value: 4, timestamp: now.UnixMilli()},
+		`test_summary_count{}`:           {value: 1, timestamp: now.UnixMilli()},
+	}, series)
+}
+
+func TestEncodeWriteRequestTimestamp(t *testing.T) {
+	t.Parallel()
+
+	families := []*dto.MetricFamily{
+		{
+			Name: ptr("test"),
+			Type: dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{
+				{Gauge: &dto.Gauge{Value: ptr(1.0)}, TimestampMs: ptr(int64(42))},
+			},
+		},
+	}
+
+	series := decodeWriteRequest(t, EncodeWriteRequest(families, time.Now()))
+
+	require.Equal(t, map[string]sample{`test{}`: {value: 1, timestamp: 42}}, series)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,385 @@
+// Package remotewrite pushes metrics to endpoints implementing the Prometheus remote write protocol.
+// Requests, which can't be delivered, are buffered in a bounded on-disk spool and replayed later.
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus-community/windows_exporter/internal/sink"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/config"
+	"github.com/prometheus/common/version"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultInterval     = time.Minute
+	defaultTimeout      = 30 * time.Second
+	defaultSpoolMaxSize = 100 * 1024 * 1024
+)
+
+// Config is the configuration of the remote write push mode.
+type Config struct {
+	// Interval between two pushes. Defaults to 1m.
+	Interval time.Duration `yaml:"interval"`
+	// Timeout of a single request. Defaults to 30s.
+	Timeout time.Duration `yaml:"timeout"`
+	// Spool configures the on-disk buffer for requests, which can't be delivered.
+	Spool SpoolConfig `yaml:"spool"`
+	// Endpoints are the remote write endpoints. Each endpoint receives all samples.
+	Endpoints []EndpointConfig `yaml:"endpoints"`
+}
+
+// SpoolConfig configures the on-disk buffer.
+type SpoolConfig struct {
+	// Directory of the spool. Each endpoint gets its own subdirectory. Empty disables the spool.
+	Directory string `yaml:"directory"`
+	// MaxSizeBytes is the maximum size of the spool per endpoint. If exceeded, the oldest requests are dropped.
+	// Defaults to 100 MiB.
+	MaxSizeBytes int64 `yaml:"max_size_bytes"`
+}
+
+// EndpointConfig configures a single remote write endpoint.
+type EndpointConfig struct {
+	URL string `yaml:"url"`
+	// Headers are added to every request. Their values are secrets, as they usually carry credentials.
+	Headers map[string]config.Secret `yaml:"headers"`
+	// HTTPClientConfig configures authentication and TLS.
+	HTTPClientConfig config.HTTPClientConfig `yaml:",inline"`
+}
+
+// UnmarshalYAML applies the defaults of the HTTP client configuration and validates the endpoint.
+func (c *EndpointConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain EndpointConfig
+
+	*c = EndpointConfig{HTTPClientConfig: config.DefaultHTTPClientConfig}
+
+	if err := value.Decode((*plain)(c)); err != nil {
+		return err
+	}
+
+	if _, err := url.ParseRequestURI(c.URL); err != nil {
+		return fmt.Errorf("invalid remote write url %q: %w", c.URL, err)
+	}
+
+	return c.HTTPClientConfig.Validate()
+}
+
+// Enabled returns true, if at least one endpoint is configured.
+func (c Config) Enabled() bool {
+	return len(c.Endpoints) > 0
+}
+
+// Interface guard.
+var _ prometheus.Collector = (*Pusher)(nil)
+
+// Pusher periodically gathers metrics and pushes them to the configured endpoints.
+type Pusher struct {
+	logger    *slog.Logger
+	config    Config
+	endpoints []*endpoint
+
+	droppedDesc       *prometheus.Desc
+	spoolRequestsDesc *prometheus.Desc
+	spoolBytesDesc    *prometheus.Desc
+}
+
+type endpoint struct {
+	url     string
+	headers map[string]config.Secret
+	client  *http.Client
+	spool   *spool
+
+	stats   *sink.Stats
+	dropped atomic.Uint64
+}
+
+// New returns a Pusher for the configuration. The Pusher has to be started by Run.
+func New(logger *slog.Logger, cfg Config) (*Pusher, error) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	if cfg.Spool.MaxSizeBytes <= 0 {
+		cfg.Spool.MaxSizeBytes = defaultSpoolMaxSize
+	}
+
+	p := &Pusher{
+		logger: logger,
+		config: cfg,
+		droppedDesc: prometheus.NewDesc(
+			"windows_exporter_remote_write_dropped_requests_total",
+			"windows_exporter: Number of remote write requests dropped, because they were rejected by the endpoint or didn't fit into the spool.",
+			[]string{"url"},
+			nil,
+		),
+		spoolRequestsDesc: prometheus.NewDesc(
+			"windows_exporter_remote_write_spool_requests",
+			"windows_exporter: Number of remote write requests waiting in the spool.",
+			[]string{"url"},
+			nil,
+		),
+		spoolBytesDesc: prometheus.NewDesc(
+			"windows_exporter_remote_write_spool_bytes",
+			"windows_exporter: Size of the remote write requests waiting in the spool.",
+			[]string{"url"},
+			nil,
+		),
+	}
+
+	for _, endpointConfig := range cfg.Endpoints {
+		client, err := config.NewClientFromConfig(endpointConfig.HTTPClientConfig, "remote_write")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client for %s: %w", endpointConfig.URL, err)
+		}
+
+		client.Timeout = cfg.Timeout
+
+		e := &endpoint{
+			url:     endpointConfig.URL,
+			headers: endpointConfig.Headers,
+			client:  client,
+			stats:   sink.NewStats("remote_write", "requests", "the remote write endpoint", prometheus.Labels{"url": endpointConfig.URL}),
+		}
+
+		if cfg.Spool.Directory != "" {
+			hash := sha256.Sum256([]byte(endpointConfig.URL))
+
+			e.spool, err = newSpool(filepath.Join(cfg.Spool.Directory, hex.EncodeToString(hash[:8])), cfg.Spool.MaxSizeBytes)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		p.endpoints = append(p.endpoints, e)
+	}
+
+	return p, nil
+}
+
+// Run pushes the metrics of gatherer on every interval until ctx is done.
+func (p *Pusher) Run(ctx context.Context, gatherer prometheus.Gatherer) {
+	sink.Run(ctx, p.config.Interval, func(ctx context.Context) {
+		p.Push(ctx, gatherer)
+	})
+}
+
+// Push gathers the metrics once and pushes them to all endpoints.
+func (p *Pusher) Push(ctx context.Context, gatherer prometheus.Gatherer) {
+	families := sink.Gather(p.logger, gatherer)
+
+	data := snappy.Encode(nil, EncodeWriteRequest(families, time.Now()))
+
+	for _, e := range p.endpoints {
+		p.pushEndpoint(ctx, e, data)
+	}
+}
+
+// pushEndpoint replays the spool of the endpoint and sends data afterward, to keep the order of the samples.
+// If the endpoint is unreachable, data is spooled.
+func (p *Pusher) pushEndpoint(ctx context.Context, e *endpoint, data []byte) {
+	logger := p.logger.With(slog.String("url", e.url))
+
+	if e.spool != nil && !p.replaySpool(ctx, logger, e) {
+		p.spool(logger, e, data)
+
+		return
+	}
+
+	err := e.send(ctx, data)
+
+	switch {
+	case err == nil:
+		return
+	case isRetryable(err):
+		logger.Warn("remote write failed",
+			slog.Any("err", err),
+		)
+
+		p.spool(logger, e, data)
+	default:
+		e.dropped.Add(1)
+
+		logger.Error("remote write request rejected, dropping samples",
+			slog.Any("err", err),
+		)
+	}
+}
+
+// replaySpool sends the spooled requests of the endpoint. It returns true, if the spool is empty afterward.
+func (p *Pusher) replaySpool(ctx context.Context, logger *slog.Logger, e *endpoint) bool {
+	for {
+		name, data, ok, err := e.spool.Oldest()
+		if !ok {
+			return true
+		}
+
+		if err == nil {
+			// The spooled requests are snappy encoded, a request, which can't be decoded, is corrupt.
+			if _, err = snappy.Decode(nil, data); err != nil {
+				err = fmt.Errorf("failed to decode spool file %s: %w", name, err)
+			}
+		}
+
+		if err != nil {
+			// An unreadable or corrupt spool file won't become readable by retrying it.
+			e.dropped.Add(1)
+
+			logger.Error("failed to read spooled remote write request, dropping samples",
+				slog.Any("err", err),
+			)
+		} else if err = e.send(ctx, data); err != nil {
+			if isRetryable(err) {
+				logger.Debug("replay of spooled remote write request failed",
+					slog.Any("err", err),
+				)
+
+				return false
+			}
+
+			e.dropped.Add(1)
+
+			logger.Error("spooled remote write request rejected, dropping samples",
+				slog.Any("err", err),
+			)
+		}
+
+		if err = e.spool.Remove(name); err != nil {
+			logger.Error("failed to remove spooled remote write request",
+				slog.Any("err", err),
+			)
+
+			return false
+		}
+	}
+}
+
+func (p *Pusher) spool(logger *slog.Logger, e *endpoint, data []byte) {
+	if e.spool == nil {
+		e.dropped.Add(1)
+
+		return
+	}
+
+	dropped, err := e.spool.Add(data)
+	e.dropped.Add(uint64(dropped))
+
+	if err != nil {
+		logger.Error("failed to spool remote write request",
+			slog.Any("err", err),
+		)
+	}
+
+	if dropped > 0 {
+		logger.Warn(fmt.Sprintf("remote write spool is full, dropped %d requests", dropped))
+	}
+}
+
+// statusError is returned for a non-2xx response of the endpoint.
+type statusError struct {
+	statusCode int
+	body       string
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("server returned HTTP status %d: %s", e.statusCode, e.body)
+}
+
+// isRetryable returns true, if a request may succeed on a later attempt. Requests rejected with a 4xx status,
+// except 429, will never succeed.
+func isRetryable(err error) bool {
+	var statusErr statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode >= http.StatusInternalServerError || statusErr.statusCode == http.StatusTooManyRequests
+	}
+
+	return true
+}
+
+func (e *endpoint) send(ctx context.Context, data []byte) error {
+	err := e.doSend(ctx, data)
+	if err != nil {
+		e.stats.Failure()
+
+		return err
+	}
+
+	e.stats.Success()
+
+	return nil
+}
+
+func (e *endpoint) doSend(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	for name, value := range e.headers {
+		req.Header.Set(name, string(value))
+	}
+
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "windows_exporter/"+version.Version)
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+
+	return statusError{statusCode: resp.StatusCode, body: string(bytes.TrimSpace(body))}
+}
+
+func (p *Pusher) Describe(ch chan<- *prometheus.Desc) {
+	for _, e := range p.endpoints {
+		e.stats.Describe(ch)
+	}
+
+	ch <- p.droppedDesc
+	ch <- p.spoolRequestsDesc
+	ch <- p.spoolBytesDesc
+}
+
+func (p *Pusher) Collect(ch chan<- prometheus.Metric) {
+	for _, e := range p.endpoints {
+		e.stats.Collect(ch)
+
+		ch <- prometheus.MustNewConstMetric(p.droppedDesc, prometheus.CounterValue, float64(e.dropped.Load()), e.url)
+
+		if e.spool != nil {
+			requests, size := e.spool.Stats()
+
+			ch <- prometheus.MustNewConstMetric(p.spoolRequestsDesc, prometheus.GaugeValue, float64(requests), e.url)
+			ch <- prometheus.MustNewConstMetric(p.spoolBytesDesc, prometheus.GaugeValue, float64(size), e.url)
+		}
+	}
+}
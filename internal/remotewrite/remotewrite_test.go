@@ -0,0 +1,184 @@
+package remotewrite
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// receiver is a remote write endpoint, which fails while unavailable is set.
+type receiver struct {
+	t *testing.T
+
+	mu          sync.Mutex
+	unavailable bool
+	statusCode  int
+	received    []float64
+	auth        string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.auth = req.Header.Get("Authorization")
+
+	if r.unavailable {
+		w.WriteHeader(r.statusCode)
+
+		return
+	}
+
+	require.Equal(r.t, "snappy", req.Header.Get("Content-Encoding"))
+	require.Equal(r.t, "application/x-protobuf", req.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(r.t, err)
+
+	data, err := snappy.Decode(nil, body)
+	require.NoError(r.t, err)
+
+	series := decodeWriteRequest(r.t, data)
+	r.received = append(r.received, series[`test{}`].value)
+}
+
+func TestPusher(t *testing.T) {
+	t.Parallel()
+
+	recv := &receiver{t: t}
+	server := httptest.NewServer(recv)
+
+	t.Cleanup(server.Close)
+
+	var cfg Config
+
+	require.NoError(t, yaml.Unmarshal([]byte(`
+spool:
+  directory: `+t.TempDir()+`
+endpoints:
+  - url: `+server.URL+`
+    authorization:
+      credentials: secret
+`), &cfg))
+
+	pusher, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+	require.NoError(t, err)
+
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test", Help: "help"})
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(gauge)
+
+	push := func(value float64) {
+		gauge.Set(value)
+		pusher.Push(context.Background(), reg)
+	}
+
+	push(1)
+
+	// The endpoint is unreachable, the requests are spooled.
+	recv.mu.Lock()
+	recv.unavailable = true
+	recv.statusCode = http.StatusServiceUnavailable
+	recv.mu.Unlock()
+
+	push(2)
+	push(3)
+
+	requests, _ := pusher.endpoints[0].spool.Stats()
+	require.Equal(t, 2, requests)
+
+	// The spool is replayed in order before the new request.
+	recv.mu.Lock()
+	recv.unavailable = false
+	recv.mu.Unlock()
+
+	push(4)
+
+	require.Equal(t, []float64{1, 2, 3, 4}, recv.received)
+	require.Equal(t, "Bearer secret", recv.auth)
+
+	requests, _ = pusher.endpoints[0].spool.Stats()
+	require.Zero(t, requests)
+
+	// Rejected requests are dropped instead of spooled.
+	recv.mu.Lock()
+	recv.unavailable = true
+	recv.statusCode = http.StatusBadRequest
+	recv.mu.Unlock()
+
+	push(5)
+
+	requests, _ = pusher.endpoints[0].spool.Stats()
+	require.Zero(t, requests)
+	require.Equal(t, uint64(1), pusher.endpoints[0].dropped.Load())
+}
+
+func TestPusherCorruptSpoolFile(t *testing.T) {
+	t.Parallel()
+
+	recv := &receiver{t: t}
+	server := httptest.NewServer(recv)
+
+	t.Cleanup(server.Close)
+
+	var cfg Config
+
+	require.NoError(t, yaml.Unmarshal([]byte(`
+spool:
+  directory: `+t.TempDir()+`
+endpoints:
+  - url: `+server.URL+`
+`), &cfg))
+
+	pusher, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+	require.NoError(t, err)
+
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test", Help: "help"})
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(gauge)
+
+	// The oldest spooled request is corrupt, the next one is valid.
+	e := pusher.endpoints[0]
+
+	_, err = e.spool.Add([]byte("\xff\xff\xff\xff not snappy"))
+	require.NoError(t, err)
+
+	gauge.Set(1)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	_, err = e.spool.Add(snappy.Encode(nil, EncodeWriteRequest(families, time.Now())))
+	require.NoError(t, err)
+
+	gauge.Set(2)
+	pusher.Push(context.Background(), reg)
+
+	// The corrupt request is dropped and the replay continues with the next request.
+	require.Equal(t, []float64{1, 2}, recv.received)
+	require.Equal(t, uint64(1), e.dropped.Load())
+
+	requests, _ := e.spool.Stats()
+	require.Zero(t, requests)
+}
+
+func TestEndpointConfigInvalidURL(t *testing.T) {
+	t.Parallel()
+
+	var cfg Config
+
+	require.Error(t, yaml.Unmarshal([]byte(`
+endpoints:
+  - url: not a url
+`), &cfg))
+}
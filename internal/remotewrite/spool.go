@@ -0,0 +1,151 @@
+package remotewrite
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const spoolFileExt = ".snappy"
+
+// spool is a bounded on-disk queue of compressed write requests. Each request is stored in its own file.
+// If the spool is full, the oldest requests are dropped.
+type spool struct {
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	files []spoolFile
+	size  int64
+	seq   uint64
+}
+
+type spoolFile struct {
+	name string
+	size int64
+}
+
+// newSpool opens the spool in dir. Requests spooled by a previous run are kept.
+func newSpool(dir string, maxBytes int64) (*spool, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool directory: %w", err)
+	}
+
+	s := &spool{
+		dir:      dir,
+		maxBytes: maxBytes,
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), spoolFileExt) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat spool file: %w", err)
+		}
+
+		s.files = append(s.files, spoolFile{name: entry.Name(), size: info.Size()})
+		s.size += info.Size()
+	}
+
+	// os.ReadDir returns the entries sorted by name, which is the order the requests were spooled.
+	return s, nil
+}
+
+// Add appends a request to the spool. It returns the number of requests dropped to make room for the request.
+// A request larger than the spool is dropped itself.
+func (s *spool) Add(data []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	size := int64(len(data))
+	if size > s.maxBytes {
+		return 1, nil
+	}
+
+	dropped := 0
+
+	for len(s.files) > 0 && s.size+size > s.maxBytes {
+		if err := s.removeLocked(s.files[0].name); err != nil {
+			return dropped, err
+		}
+
+		dropped++
+	}
+
+	s.seq++
+	name := fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), s.seq%1000000, spoolFileExt)
+
+	if err := os.WriteFile(filepath.Join(s.dir, name), data, 0o600); err != nil {
+		return dropped, fmt.Errorf("failed to write spool file: %w", err)
+	}
+
+	s.files = append(s.files, spoolFile{name: name, size: size})
+	s.size += size
+
+	return dropped, nil
+}
+
+// Oldest returns the oldest request of the spool. ok is false, if the spool is empty.
+func (s *spool) Oldest() (string, []byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.files) == 0 {
+		return "", nil, false, nil
+	}
+
+	name := s.files[0].name
+
+	data, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		return name, nil, true, fmt.Errorf("failed to read spool file: %w", err)
+	}
+
+	return name, data, true, nil
+}
+
+// Remove removes a request from the spool.
+func (s *spool) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.removeLocked(name)
+}
+
+func (s *spool) removeLocked(name string) error {
+	i := slices.IndexFunc(s.files, func(f spoolFile) bool { return f.name == name })
+	if i < 0 {
+		return nil
+	}
+
+	if err := os.Remove(filepath.Join(s.dir, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove spool file: %w", err)
+	}
+
+	s.size -= s.files[i].size
+	s.files = slices.Delete(s.files, i, i+1)
+
+	return nil
+}
+
+// Stats returns the number of spooled requests and their size in bytes.
+func (s *spool) Stats() (int, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.files), s.size
+}
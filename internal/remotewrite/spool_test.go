@@ -0,0 +1,52 @@
+package remotewrite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpool(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	s, err := newSpool(dir, 10)
+	require.NoError(t, err)
+
+	for _, data := range []string{"aaaa", "bbbb"} {
+		dropped, err := s.Add([]byte(data))
+		require.NoError(t, err)
+		require.Zero(t, dropped)
+	}
+
+	// The spool is full, the oldest request is dropped.
+	dropped, err := s.Add([]byte("cccc"))
+	require.NoError(t, err)
+	require.Equal(t, 1, dropped)
+
+	// A request larger than the spool is dropped itself.
+	dropped, err = s.Add([]byte("too large for the spool"))
+	require.NoError(t, err)
+	require.Equal(t, 1, dropped)
+
+	requests, size := s.Stats()
+	require.Equal(t, 2, requests)
+	require.Equal(t, int64(8), size)
+
+	// Spooled requests survive a restart.
+	s, err = newSpool(dir, 10)
+	require.NoError(t, err)
+
+	for _, expected := range []string{"bbbb", "cccc"} {
+		name, data, ok, err := s.Oldest()
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, expected, string(data))
+		require.NoError(t, s.Remove(name))
+	}
+
+	_, _, ok, err := s.Oldest()
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,119 @@
+// Package sink holds the parts shared by the push modes, which periodically gather the metrics and send them
+// to another system, like the Prometheus remote write endpoints, the Pushgateway or InfluxDB.
+package sink
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Gather gathers the metrics of gatherer. If some collectors fail, a prometheus.Gatherer returns the metrics of
+// the successful collectors alongside the error. The error is logged and the metrics are returned anyway.
+func Gather(logger *slog.Logger, gatherer prometheus.Gatherer) []*dto.MetricFamily {
+	families, err := gatherer.Gather()
+	if err != nil {
+		logger.Warn("error gathering metrics",
+			slog.Any("err", err),
+		)
+	}
+
+	return families
+}
+
+// Run calls send immediately and afterward on every interval until ctx is done.
+func Run(ctx context.Context, interval time.Duration, send func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		send(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Interface guard.
+var _ prometheus.Collector = (*Stats)(nil)
+
+// Stats counts the sent and failed requests of a push mode. The counts are exposed as
+// windows_exporter_<subsystem>_sent_<unit>_total and windows_exporter_<subsystem>_failed_<unit>_total,
+// the time of the last sent request as windows_exporter_<subsystem>_last_success_timestamp_seconds.
+type Stats struct {
+	sent        atomic.Uint64
+	failed      atomic.Uint64
+	lastSuccess atomic.Int64
+
+	sentDesc        *prometheus.Desc
+	failedDesc      *prometheus.Desc
+	lastSuccessDesc *prometheus.Desc
+}
+
+// NewStats returns Stats for the requests of the unit, like requests or batches, sent to target.
+// constLabels distinguish multiple Stats of the same subsystem, like the endpoints of a push mode.
+func NewStats(subsystem, unit, target string, constLabels prometheus.Labels) *Stats {
+	return &Stats{
+		sentDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("windows_exporter", subsystem, "sent_"+unit+"_total"),
+			"windows_exporter: Number of "+unit+" delivered to "+target+".",
+			nil,
+			constLabels,
+		),
+		failedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("windows_exporter", subsystem, "failed_"+unit+"_total"),
+			"windows_exporter: Number of "+unit+", which couldn't be delivered to "+target+".",
+			nil,
+			constLabels,
+		),
+		lastSuccessDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("windows_exporter", subsystem, "last_success_timestamp_seconds"),
+			"windows_exporter: Timestamp of the last delivery to "+target+".",
+			nil,
+			constLabels,
+		),
+	}
+}
+
+// Success counts a sent request.
+func (s *Stats) Success() {
+	s.sent.Add(1)
+	s.lastSuccess.Store(time.Now().UnixNano())
+}
+
+// Failure counts a failed request.
+func (s *Stats) Failure() {
+	s.failed.Add(1)
+}
+
+// Sent returns the number of sent requests.
+func (s *Stats) Sent() uint64 {
+	return s.sent.Load()
+}
+
+// Failed returns the number of failed requests.
+func (s *Stats) Failed() uint64 {
+	return s.failed.Load()
+}
+
+func (s *Stats) Describe(ch chan<- *prometheus.Desc) {
+	ch <- s.sentDesc
+	ch <- s.failedDesc
+	ch <- s.lastSuccessDesc
+}
+
+func (s *Stats) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(s.sentDesc, prometheus.CounterValue, float64(s.sent.Load()))
+	ch <- prometheus.MustNewConstMetric(s.failedDesc, prometheus.CounterValue, float64(s.failed.Load()))
+
+	if lastSuccess := s.lastSuccess.Load(); lastSuccess > 0 {
+		ch <- prometheus.MustNewConstMetric(s.lastSuccessDesc, prometheus.GaugeValue, float64(lastSuccess)/1e9)
+	}
+}
@@ -0,0 +1,65 @@
+package sink
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls atomic.Int32
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		Run(ctx, time.Millisecond, func(context.Context) {
+			if calls.Add(1) == 3 {
+				cancel()
+			}
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run didn't return after ctx was done")
+	}
+
+	require.Equal(t, int32(3), calls.Load())
+}
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	stats := NewStats("test", "requests", "the test endpoint", prometheus.Labels{"url": "http://localhost"})
+
+	// The timestamp of the last success is exposed after the first success only.
+	require.NoError(t, testutil.CollectAndCompare(stats, strings.NewReader(`
+# HELP windows_exporter_test_failed_requests_total windows_exporter: Number of requests, which couldn't be delivered to the test endpoint.
+# TYPE windows_exporter_test_failed_requests_total counter
+windows_exporter_test_failed_requests_total{url="http://localhost"} 0
+# HELP windows_exporter_test_sent_requests_total windows_exporter: Number of requests delivered to the test endpoint.
+# TYPE windows_exporter_test_sent_requests_total counter
+windows_exporter_test_sent_requests_total{url="http://localhost"} 0
+`)))
+
+	stats.Success()
+	stats.Failure()
+	stats.Failure()
+
+	require.Equal(t, uint64(1), stats.Sent())
+	require.Equal(t, uint64(2), stats.Failed())
+	require.Equal(t, 3, testutil.CollectAndCount(stats))
+}
@@ -0,0 +1,37 @@
+package testutils
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+// constCollector exposes a fixed set of metrics.
+type constCollector []prometheus.Metric
+
+func (c constCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, metric := range c {
+		ch <- metric.Desc()
+	}
+}
+
+func (c constCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, metric := range c {
+		ch <- metric
+	}
+}
+
+// Gather returns the metric families of metrics, as returned by a registry, which exposes the metrics.
+func Gather(tb testing.TB, metrics ...prometheus.Metric) []*dto.MetricFamily {
+	tb.Helper()
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(constCollector(metrics))
+
+	families, err := reg.Gather()
+	require.NoError(tb, err)
+
+	return families
+}
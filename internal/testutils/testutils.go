@@ -3,6 +3,7 @@
 package testutils
 
 import (
+	"context"
 	"io"
 	"log/slog"
 	"sync"
@@ -40,10 +41,19 @@ func FuncBenchmarkCollector[C collector.Collector](b *testing.B, name string, co
 	}()
 
 	for i := 0; i < b.N; i++ {
-		require.NoError(b, c.Collect(scrapeContext, logger, metrics))
+		require.NoError(b, c.Collect(context.Background(), scrapeContext, logger, metrics))
 	}
 }
 
+// FuncBenchmarkCollectorWithConfig is FuncBenchmarkCollector for collectors, which start from a configuration.
+func FuncBenchmarkCollectorWithConfig[C collector.Collector, V interface{}](b *testing.B, name string, fn func(*kingpin.Application, *V) C, conf *V) {
+	b.Helper()
+
+	FuncBenchmarkCollector(b, name, func(app *kingpin.Application) C {
+		return fn(app, conf)
+	})
+}
+
 func TestCollector[C collector.Collector, V interface{}](t *testing.T, fn func(*V) C, conf *V) {
 	t.Helper()
 	t.Setenv("WINDOWS_EXPORTER_PERF_COUNTERS_ENGINE", "pdh")
@@ -84,7 +94,7 @@ func TestCollector[C collector.Collector, V interface{}](t *testing.T, fn func(*
 
 	time.Sleep(1 * time.Second)
 
-	require.NoError(t, c.Collect(nil, logger, ch))
+	require.NoError(t, c.Collect(context.Background(), nil, logger, ch))
 
 	close(ch)
 
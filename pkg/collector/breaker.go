@@ -0,0 +1,45 @@
+//go:build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/internal/breaker"
+)
+
+// breakerFor returns the circuit breaker of a collector. nil is returned, if the circuit breaker is disabled.
+func (c *MetricCollectors) breakerFor(name string) *breaker.Breaker {
+	c.breakersMu.Lock()
+	defer c.breakersMu.Unlock()
+
+	if !c.CircuitBreaker.Enabled() {
+		return nil
+	}
+
+	if c.breakers == nil {
+		c.breakers = make(map[string]*breaker.Breaker)
+	}
+
+	b, ok := c.breakers[name]
+	if !ok {
+		b = breaker.New(c.CircuitBreaker)
+		c.breakers[name] = b
+	}
+
+	return b
+}
+
+// resetBreakers applies the options of the circuit breaker and removes the circuit breakers of the collectors
+// for which filter returns true. If the options changed, all circuit breakers are removed.
+func (c *MetricCollectors) resetBreakers(options breaker.Options, filter func(name string) bool) {
+	c.breakersMu.Lock()
+	defer c.breakersMu.Unlock()
+
+	optionsChanged := c.CircuitBreaker != options
+	c.CircuitBreaker = options
+
+	for name := range c.breakers {
+		if optionsChanged || filter(name) {
+			delete(c.breakers, name)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+//go:build windows
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	v1 "github.com/prometheus-community/windows_exporter/internal/perfdata/v1"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrNotCollectedYet is returned by the Collect of a collector with a background collection interval,
+// until its first background collection finished. The collector is reported as waiting, not as failed.
+var ErrNotCollectedYet = errors.New("background collection has not finished yet")
+
+// Interface guard.
+var _ Collector = (*cachedCollector)(nil)
+
+// cachedCollector runs the wrapped collector in the background at a fixed interval.
+// Scrapes are served from the result of the last successful background collection.
+type cachedCollector struct {
+	Collector
+
+	interval         time.Duration
+	perfCounterQuery string
+
+	mu          sync.RWMutex
+	metricsBuf  []prometheus.Metric
+	lastErr     error
+	lastRefresh time.Time
+
+	// ctx is cancelled on Close to stop a running background collection.
+	ctx    context.Context //nolint:containedctx
+	cancel context.CancelFunc
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+
+	// closeOnce makes Close idempotent, since a collector may be closed by a Reload and on shutdown.
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func newCachedCollector(collector Collector, interval time.Duration) *cachedCollector {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &cachedCollector{
+		Collector: collector,
+		interval:  interval,
+		ctx:       ctx,
+		cancel:    cancel,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+func (c *cachedCollector) Build(logger *slog.Logger, miSession *mi.Session) error {
+	if err := c.Collector.Build(logger, miSession); err != nil {
+		return err
+	}
+
+	// The background collection takes its own perflib snapshot,
+	// since the snapshot of a scrape may be not available at the time of the background collection.
+	perfCounterQuery, err := buildPerfCounterQuery(logger, Map{c.GetName(): c.Collector})
+	if err != nil {
+		return err
+	}
+
+	c.perfCounterQuery = perfCounterQuery
+
+	c.wg.Add(1)
+
+	go c.run(logger.With(slog.String("collector", c.GetName())))
+
+	return nil
+}
+
+// Close stops the background collection and closes the wrapped collector. Further calls return the result of the first call.
+func (c *cachedCollector) Close(logger *slog.Logger) error {
+	c.closeOnce.Do(func() {
+		c.cancel()
+		close(c.stopCh)
+		c.wg.Wait()
+
+		c.closeErr = c.Collector.Close(logger)
+	})
+
+	return c.closeErr
+}
+
+// GetPerfCounter returns no perf counters, since the perf counters of the wrapped collector
+// are queried by the background collection.
+func (c *cachedCollector) GetPerfCounter(_ *slog.Logger) ([]string, error) {
+	return []string{}, nil
+}
+
+// Collect sends the metrics of the last successful background collection. Their age is exposed by the
+// Prometheus collector, so an error of a later background collection is not returned. The error is returned
+// only, if no background collection has succeeded yet.
+func (c *cachedCollector) Collect(_ context.Context, _ *types.ScrapeContext, _ *slog.Logger, ch chan<- prometheus.Metric) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.lastRefresh.IsZero() {
+		if c.lastErr != nil {
+			return c.lastErr
+		}
+
+		return ErrNotCollectedYet
+	}
+
+	for _, m := range c.metricsBuf {
+		ch <- m
+	}
+
+	return nil
+}
+
+// LastRefresh returns the time of the last successful background collection.
+func (c *cachedCollector) LastRefresh() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.lastRefresh
+}
+
+func (c *cachedCollector) run(logger *slog.Logger) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.refresh(logger)
+
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *cachedCollector) refresh(logger *slog.Logger) {
+	t := time.Now()
+
+	metricsBuf, err := c.collect(logger)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastErr = err
+
+	if err != nil {
+		logger.Error(fmt.Sprintf("background collection failed after %s, serving metrics from %s", time.Since(t), c.lastRefresh),
+			slog.Any("err", err),
+		)
+
+		return
+	}
+
+	c.metricsBuf = metricsBuf
+	c.lastRefresh = time.Now()
+
+	logger.Debug(fmt.Sprintf("background collection succeeded after %s, resulting in %d metrics", time.Since(t), len(metricsBuf)))
+}
+
+func (c *cachedCollector) collect(logger *slog.Logger) (metricsBuf []prometheus.Metric, err error) {
+	scrapeContext := &types.ScrapeContext{}
+
+	if c.perfCounterQuery != "" {
+		scrapeContext.PerfObjects, err = v1.GetPerflibSnapshot(c.perfCounterQuery)
+		if err != nil {
+			return nil, fmt.Errorf("failed to capture perflib snapshot: %w", err)
+		}
+	}
+
+	// A background collection must not take longer than its interval.
+	ctx, cancel := context.WithTimeout(c.ctx, c.interval)
+	defer cancel()
+
+	metricsBuf = make([]prometheus.Metric, 0, len(c.metricsBuf))
+	bufCh := make(chan prometheus.Metric, 1000)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errCh <- fmt.Errorf("panic in collector %s: %v. stack: %s", c.GetName(), r,
+					string(debug.Stack()),
+				)
+			}
+
+			close(bufCh)
+		}()
+
+		errCh <- c.Collector.Collect(ctx, scrapeContext, logger, bufCh)
+	}()
+
+	for m := range bufCh {
+		metricsBuf = append(metricsBuf, m)
+	}
+
+	if err = <-errCh; err != nil {
+		return nil, err
+	}
+
+	return metricsBuf, nil
+}
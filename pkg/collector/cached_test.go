@@ -0,0 +1,169 @@
+//go:build windows
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/breaker"
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+var testDesc = prometheus.NewDesc("windows_test_value", "Test value.", nil, nil)
+
+// testCollector sends a single metric on every successful collection. Each collection returns the next error of results.
+type testCollector struct {
+	results chan error
+	closed  atomic.Int32
+}
+
+func newTestCollector() *testCollector {
+	return &testCollector{results: make(chan error, 1)}
+}
+
+func (c *testCollector) Build(*slog.Logger, *mi.Session) error { return nil }
+
+func (c *testCollector) Close(*slog.Logger) error {
+	c.closed.Add(1)
+
+	return nil
+}
+
+func (c *testCollector) GetName() string { return "test" }
+
+func (c *testCollector) GetPerfCounter(*slog.Logger) ([]string, error) { return nil, nil }
+
+func (c *testCollector) Collect(ctx context.Context, _ *types.ScrapeContext, _ *slog.Logger, ch chan<- prometheus.Metric) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-c.results:
+		if err == nil {
+			ch <- prometheus.MustNewConstMetric(testDesc, prometheus.GaugeValue, 1)
+		}
+
+		return err
+	}
+}
+
+func collectAll(t *testing.T, c Collector) ([]prometheus.Metric, error) {
+	t.Helper()
+
+	ch := make(chan prometheus.Metric, 10)
+	err := c.Collect(context.Background(), &types.ScrapeContext{}, slog.New(slog.NewTextHandler(io.Discard, nil)), ch)
+
+	close(ch)
+
+	metrics := make([]prometheus.Metric, 0, len(ch))
+	for m := range ch {
+		metrics = append(metrics, m)
+	}
+
+	return metrics, err
+}
+
+func TestCachedCollectorRefresh(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	wrapped := newTestCollector()
+
+	// The interval is long enough, that only the first background collection runs on its own.
+	cached := newCachedCollector(wrapped, time.Hour)
+	require.NoError(t, cached.Build(logger, nil))
+
+	t.Cleanup(func() { _ = cached.Close(logger) })
+
+	// The first background collection is still waiting for its result.
+	_, err := collectAll(t, cached)
+	require.ErrorIs(t, err, ErrNotCollectedYet)
+
+	// Without a successful background collection, its error fails the collector.
+	wrapped.results <- errors.New("connection refused")
+
+	require.Eventually(t, func() bool {
+		_, err := collectAll(t, cached)
+
+		return err != nil && err.Error() == "connection refused"
+	}, 5*time.Second, time.Millisecond)
+
+	wrapped.results <- nil
+
+	cached.refresh(logger)
+
+	metrics, err := collectAll(t, cached)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+
+	lastRefresh := cached.LastRefresh()
+
+	// A failed background collection keeps serving the stale metrics without an error.
+	// Their staleness is exposed by the age of the cache.
+	wrapped.results <- errors.New("query failed")
+
+	cached.refresh(logger)
+
+	metrics, err = collectAll(t, cached)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, lastRefresh, cached.LastRefresh())
+
+	// The next successful background collection refreshes the cache.
+	wrapped.results <- nil
+
+	cached.refresh(logger)
+
+	metrics, err = collectAll(t, cached)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.False(t, cached.LastRefresh().Before(lastRefresh))
+}
+
+func TestCachedCollectorClose(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	wrapped := newTestCollector()
+
+	cached := newCachedCollector(wrapped, time.Hour)
+	require.NoError(t, cached.Build(logger, nil))
+
+	// Close stops the running background collection and may be called more than once.
+	require.NoError(t, cached.Close(logger))
+	require.NoError(t, cached.Close(logger))
+	require.Equal(t, int32(1), wrapped.closed.Load())
+}
+
+func TestPrometheusWaitingCollector(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	collectors := &MetricCollectors{
+		CircuitBreaker: breaker.Options{Threshold: 1, Backoff: time.Minute, MaxBackoff: time.Hour},
+	}
+
+	// The background collection of a collector, which is not built, never finishes.
+	cached := newCachedCollector(newTestCollector(), time.Hour)
+	p := collectors.NewPrometheusCollector(time.Second, logger)
+
+	s, err := p.startScrape()
+	require.NoError(t, err)
+
+	defer s.done()
+
+	ch := make(chan prometheus.Metric, 10)
+
+	// A collector waiting for its first background collection is neither a success nor a failure.
+	require.Equal(t, waiting, p.executeWithBreaker(s, "test", cached, &types.ScrapeContext{}, ch))
+	require.Equal(t, breaker.Closed, collectors.breakerFor("test").State())
+	require.Zero(t, collectors.statusFor("test").Status("test").Failures)
+}
@@ -63,14 +63,66 @@ import (
 )
 
 // NewWithFlags To be called by the exporter for collector initialization before running kingpin.Parse.
-func NewWithFlags(app *kingpin.Application) *MetricCollectors {
+// The collectors start from config, usually loaded from the configuration file, and flags set on the command line
+// override its values. A nil config uses ConfigDefaults.
+func NewWithFlags(app *kingpin.Application, config *Config) *MetricCollectors {
+	if config == nil {
+		defaults := ConfigDefaults
+		config = &defaults
+	}
+
 	collectors := map[string]Collector{}
+	collectorOptions := map[string]*CollectorOptions{}
+	collectorFlags := map[string][]*kingpin.FlagModel{}
 
 	for name, builder := range BuildersWithFlags {
-		collectors[name] = builder(app)
+		numFlags := len(app.Model().Flags)
+
+		collectors[name] = builder(app, config)
+		collectorOptions[name] = newCollectorOptionsWithFlags(app, name)
+
+		// Remember the flags of the collector to detect configuration changes on reload.
+		collectorFlags[name] = app.Model().Flags[numFlags:]
 	}
 
-	return New(collectors)
+	metricCollectors := New(collectors)
+	metricCollectors.CollectorOptions = collectorOptions
+	metricCollectors.collectorFlags = collectorFlags
+	metricCollectors.config = config
+
+	app.Flag(
+		"collectors.circuit-breaker.threshold",
+		"Number of consecutive failures or timeouts of a collector, after which the collector is skipped for a backoff period. 0 to disable.",
+	).Default("0").IntVar(&metricCollectors.CircuitBreaker.Threshold)
+
+	app.Flag(
+		"collectors.circuit-breaker.backoff",
+		"Duration a collector is skipped after reaching the failure threshold. The duration doubles every time a retry of the collector fails.",
+	).Default("30s").DurationVar(&metricCollectors.CircuitBreaker.Backoff)
+
+	app.Flag(
+		"collectors.circuit-breaker.max-backoff",
+		"Maximum duration a collector is skipped by the circuit breaker.",
+	).Default("10m").DurationVar(&metricCollectors.CircuitBreaker.MaxBackoff)
+
+	return metricCollectors
+}
+
+// newCollectorOptionsWithFlags registers the generic per collector flags.
+func newCollectorOptionsWithFlags(app *kingpin.Application, name string) *CollectorOptions {
+	options := &CollectorOptions{}
+
+	app.Flag(
+		"collector."+name+".interval",
+		"If greater than 0, the "+name+" collector runs in the background at this interval and scrapes are served from the last cached result.",
+	).Default("0s").DurationVar(&options.Interval)
+
+	app.Flag(
+		"collector."+name+".timeout",
+		"If greater than 0, limits the duration of a collection of the "+name+" collector. The timeout is capped by the timeout of the scrape request.",
+	).Default("0s").DurationVar(&options.Timeout)
+
+	return options
 }
 
 // NewWithConfig To be called by the external libraries for collector initialization without running [kingpin.Parse].
@@ -136,6 +188,83 @@ func New(collectors Map) *MetricCollectors {
 }
 
 func (c *MetricCollectors) SetPerfCounterQuery(logger *slog.Logger) error {
+	perfCounterQuery, err := buildPerfCounterQuery(logger, c.Collectors)
+	if err != nil {
+		return err
+	}
+
+	c.PerfCounterQuery = perfCounterQuery
+
+	c.resetPerfCounterQueryCache()
+
+	return nil
+}
+
+// perfCounterQueryFor returns the perflib query for the perf counters required by the given subset of collectors.
+// The query is cached per combination of collectors. The caller must hold the read lock of c.
+func (c *MetricCollectors) perfCounterQueryFor(logger *slog.Logger, names []string) (string, error) {
+	names = slices.Clone(names)
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	key := strings.Join(names, ",")
+
+	c.perfCounterQueryCacheMu.Lock()
+	defer c.perfCounterQueryCacheMu.Unlock()
+
+	if perfCounterQuery, ok := c.perfCounterQueryCache[key]; ok {
+		return perfCounterQuery, nil
+	}
+
+	collectors, err := c.selectCollectors(names)
+	if err != nil {
+		return "", err
+	}
+
+	perfCounterQuery, err := buildPerfCounterQuery(logger, collectors)
+	if err != nil {
+		return "", err
+	}
+
+	if c.perfCounterQueryCache == nil {
+		c.perfCounterQueryCache = make(map[string]string)
+	}
+
+	c.perfCounterQueryCache[key] = perfCounterQuery
+
+	return perfCounterQuery, nil
+}
+
+// resetPerfCounterQueryCache discards the cached perflib queries. It has to be called whenever the collectors change.
+func (c *MetricCollectors) resetPerfCounterQueryCache() {
+	c.perfCounterQueryCacheMu.Lock()
+	c.perfCounterQueryCache = nil
+	c.perfCounterQueryCacheMu.Unlock()
+}
+
+// selectCollectors returns the given subset of collectors. The caller must hold the read lock of c.
+func (c *MetricCollectors) selectCollectors(names []string) (Map, error) {
+	collectors := make(Map, len(names))
+
+	for _, name := range names {
+		// Unavailable collectors are skipped until their build succeeds.
+		if _, ok := c.unavailableCollectors[name]; ok {
+			continue
+		}
+
+		collector, ok := c.Collectors[name]
+		if !ok {
+			return nil, fmt.Errorf("couldn't find collector %s", name)
+		}
+
+		collectors[name] = collector
+	}
+
+	return collectors, nil
+}
+
+// buildPerfCounterQuery returns the perflib query for the perf counters required by the given collectors.
+func buildPerfCounterQuery(logger *slog.Logger, collectors Map) (string, error) {
 	var (
 		err error
 
@@ -143,12 +272,16 @@ func (c *MetricCollectors) SetPerfCounterQuery(logger *slog.Logger) error {
 		perfIndicies     []string
 	)
 
-	perfCounterDependencies := make([]string, 0, len(c.Collectors))
+	perfCounterDependencies := make([]string, 0, len(collectors))
 
-	for _, collector := range c.Collectors {
+	for _, collector := range collectors {
 		perfCounterNames, err = collector.GetPerfCounter(logger)
 		if err != nil {
-			return err
+			return "", err
+		}
+
+		if len(perfCounterNames) == 0 {
+			continue
 		}
 
 		perfIndicies = make([]string, 0, len(perfCounterNames))
@@ -159,9 +292,7 @@ func (c *MetricCollectors) SetPerfCounterQuery(logger *slog.Logger) error {
 		perfCounterDependencies = append(perfCounterDependencies, strings.Join(perfIndicies, " "))
 	}
 
-	c.PerfCounterQuery = strings.Join(perfCounterDependencies, " ")
-
-	return nil
+	return strings.Join(perfCounterDependencies, " "), nil
 }
 
 // Enable removes all collectors that not enabledCollectors.
@@ -188,18 +319,43 @@ func (c *MetricCollectors) Build(logger *slog.Logger) error {
 		return fmt.Errorf("error from initialize MI: %w", err)
 	}
 
+	buildErrs := c.build(logger)
+	errs := make([]error, 0, len(buildErrs))
+
+	for name, err := range buildErrs {
+		errs = append(errs, fmt.Errorf("error build collector %s: %w", name, err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// build builds all collectors concurrently and returns the errors per collector name.
+func (c *MetricCollectors) build(logger *slog.Logger) map[string]error {
+	for name, collector := range c.Collectors {
+		if options, ok := c.CollectorOptions[name]; ok && options.Interval > 0 {
+			if _, ok := collector.(*cachedCollector); !ok {
+				c.Collectors[name] = newCachedCollector(collector, options.Interval)
+			}
+		}
+	}
+
+	type buildResult struct {
+		name string
+		err  error
+	}
+
 	wg := sync.WaitGroup{}
 	wg.Add(len(c.Collectors))
 
-	errCh := make(chan error, len(c.Collectors))
-	errs := make([]error, 0, len(c.Collectors))
+	errCh := make(chan buildResult, len(c.Collectors))
+	errs := make(map[string]error, len(c.Collectors))
 
-	for _, collector := range c.Collectors {
+	for name, collector := range c.Collectors {
 		go func() {
 			defer wg.Done()
 
-			if err = collector.Build(logger, c.MISession); err != nil {
-				errCh <- fmt.Errorf("error build collector %s: %w", collector.GetName(), err)
+			if err := collector.Build(logger, c.MISession); err != nil {
+				errCh <- buildResult{name: name, err: err}
 			}
 		}()
 	}
@@ -208,21 +364,26 @@ func (c *MetricCollectors) Build(logger *slog.Logger) error {
 
 	close(errCh)
 
-	for err := range errCh {
-		errs = append(errs, err)
+	for result := range errCh {
+		errs[result.name] = result.err
 	}
 
-	return errors.Join(errs...)
+	return errs
 }
 
 // PrepareScrapeContext creates a ScrapeContext to be used during a single scrape.
 func (c *MetricCollectors) PrepareScrapeContext() (*types.ScrapeContext, error) {
+	return prepareScrapeContext(c.PerfCounterQuery)
+}
+
+// prepareScrapeContext creates a ScrapeContext with a perflib snapshot of the given perflib query.
+func prepareScrapeContext(perfCounterQuery string) (*types.ScrapeContext, error) {
 	// If no perf counters to query, return an empty context.
-	if c.PerfCounterQuery == "" {
+	if perfCounterQuery == "" {
 		return &types.ScrapeContext{}, nil
 	}
 
-	perfObjects, err := v1.GetPerflibSnapshot(c.PerfCounterQuery)
+	perfObjects, err := v1.GetPerflibSnapshot(perfCounterQuery)
 	if err != nil {
 		return nil, err
 	}
@@ -232,6 +393,8 @@ func (c *MetricCollectors) PrepareScrapeContext() (*types.ScrapeContext, error)
 
 // Close To be called by the exporter for collector cleanup.
 func (c *MetricCollectors) Close(logger *slog.Logger) error {
+	c.stopBuildRetries()
+
 	errs := make([]error, 0, len(c.Collectors))
 
 	for _, collector := range c.Collectors {
@@ -0,0 +1,69 @@
+//go:build windows
+
+package collector
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	v1 "github.com/prometheus-community/windows_exporter/internal/perfdata/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// perfCounterCollector requires the given perf counters and counts the calls of GetPerfCounter.
+type perfCounterCollector struct {
+	*testCollector
+
+	counters []string
+	calls    atomic.Int32
+}
+
+func newPerfCounterCollector(counters ...string) *perfCounterCollector {
+	return &perfCounterCollector{testCollector: newTestCollector(), counters: counters}
+}
+
+func (c *perfCounterCollector) GetPerfCounter(*slog.Logger) ([]string, error) {
+	c.calls.Add(1)
+
+	return c.counters, nil
+}
+
+func TestPerfCounterQueryFor(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	processor := newPerfCounterCollector("Processor")
+	memory := newPerfCounterCollector("Memory")
+
+	c := New(Map{"cpu": processor, "memory": memory})
+	require.NoError(t, c.SetPerfCounterQuery(logger))
+
+	query, err := c.perfCounterQueryFor(logger, []string{"cpu"})
+	require.NoError(t, err)
+	require.Equal(t, v1.MapCounterToIndex("Processor"), query)
+
+	query, err = c.perfCounterQueryFor(logger, []string{"memory", "cpu"})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{v1.MapCounterToIndex("Processor"), v1.MapCounterToIndex("Memory")}, strings.Fields(query))
+
+	// Each combination is built once, independent of the order of the collectors.
+	calls := processor.calls.Load()
+
+	query, err = c.perfCounterQueryFor(logger, []string{"cpu", "memory", "cpu"})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{v1.MapCounterToIndex("Processor"), v1.MapCounterToIndex("Memory")}, strings.Fields(query))
+	require.Equal(t, calls, processor.calls.Load())
+
+	// A reload, which changes the collectors, discards the cached queries.
+	require.NoError(t, c.Reload(logger, New(Map{"cpu": newPerfCounterCollector("Memory")})))
+
+	query, err = c.perfCounterQueryFor(logger, []string{"cpu"})
+	require.NoError(t, err)
+	require.Equal(t, v1.MapCounterToIndex("Memory"), query)
+
+	_, err = c.perfCounterQueryFor(logger, []string{"cpu", "memory"})
+	require.ErrorContains(t, err, "couldn't find collector memory")
+}
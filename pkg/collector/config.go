@@ -1,6 +1,9 @@
 package collector
 
 import (
+	"reflect"
+	"strings"
+
 	"github.com/prometheus-community/windows_exporter/internal/collector/ad"
 	"github.com/prometheus-community/windows_exporter/internal/collector/adcs"
 	"github.com/prometheus-community/windows_exporter/internal/collector/adfs"
@@ -49,6 +52,8 @@ import (
 	"github.com/prometheus-community/windows_exporter/internal/collector/vmware"
 )
 
+// Config holds the configuration of all collectors.
+// The flag tags hold the names of the collectors, which the configuration file uses in addition to the YAML keys.
 type Config struct {
 	AD               ad.Config                `yaml:"ad"`
 	ADCS             adcs.Config              `yaml:"adcs"`
@@ -60,25 +65,25 @@ type Config struct {
 	Cs               cs.Config                `yaml:"cs"`
 	DFSR             dfsr.Config              `yaml:"dfsr"`
 	Dhcp             dhcp.Config              `yaml:"dhcp"`
-	DiskDrive        diskdrive.Config         `yaml:"disk_drive"`
+	DiskDrive        diskdrive.Config         `yaml:"disk_drive" flag:"diskdrive"`
 	DNS              dns.Config               `yaml:"dns"`
 	Exchange         exchange.Config          `yaml:"exchange"`
 	Filetime         filetime.Config          `yaml:"filetime"`
 	Fsrmquota        fsrmquota.Config         `yaml:"fsrmquota"`
-	HyperV           hyperv.Config            `yaml:"hyper_v"`
+	HyperV           hyperv.Config            `yaml:"hyper_v" flag:"hyperv"`
 	IIS              iis.Config               `yaml:"iis"`
 	License          license.Config           `yaml:"license"`
 	LogicalDisk      logical_disk.Config      `yaml:"logical_disk"`
 	Logon            logon.Config             `yaml:"logon"`
 	Memory           memory.Config            `yaml:"memory"`
-	MSCluster        mscluster.Config         `yaml:"ms_cluster"`
+	MSCluster        mscluster.Config         `yaml:"ms_cluster" flag:"mscluster"`
 	Msmq             msmq.Config              `yaml:"msmq"`
 	Mssql            mssql.Config             `yaml:"mssql"`
 	Net              net.Config               `yaml:"net"`
-	NetFramework     netframework.Config      `yaml:"net_framework"`
+	NetFramework     netframework.Config      `yaml:"net_framework" flag:"netframework"`
 	Nps              nps.Config               `yaml:"nps"`
 	OS               os.Config                `yaml:"os"`
-	PerfData         perfdata.Config          `yaml:"perf_data"`
+	PerfData         perfdata.Config          `yaml:"perf_data" flag:"perfdata"`
 	PhysicalDisk     physical_disk.Config     `yaml:"physical_disk"`
 	Printer          printer.Config           `yaml:"printer"`
 	Process          process.Config           `yaml:"process"`
@@ -86,15 +91,15 @@ type Config struct {
 	ScheduledTask    scheduled_task.Config    `yaml:"scheduled_task"`
 	Service          service.Config           `yaml:"service"`
 	SMB              smb.Config               `yaml:"smb"`
-	SMBClient        smbclient.Config         `yaml:"smb_client"`
+	SMBClient        smbclient.Config         `yaml:"smb_client" flag:"smbclient"`
 	SMTP             smtp.Config              `yaml:"smtp"`
 	System           system.Config            `yaml:"system"`
 	TCP              tcp.Config               `yaml:"tcp"`
 	TerminalServices terminal_services.Config `yaml:"terminal_services"`
 	Textfile         textfile.Config          `yaml:"textfile"`
-	ThermalZone      thermalzone.Config       `yaml:"thermal_zone"`
+	ThermalZone      thermalzone.Config       `yaml:"thermal_zone" flag:"thermalzone"`
 	Time             time.Config              `yaml:"time"`
-	Update           update.Config            `yaml:"update"`
+	Update           update.Config            `yaml:"update" flag:"updates"`
 	Vmware           vmware.Config            `yaml:"vmware"`
 }
 
@@ -149,3 +154,35 @@ var ConfigDefaults = Config{
 	Update:           update.ConfigDefaults,
 	Vmware:           vmware.ConfigDefaults,
 }
+
+// section returns the configuration of the collector name.
+func (c *Config) section(name string) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	v := reflect.ValueOf(c).Elem()
+
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+
+		if key, _, _ := strings.Cut(field.Tag.Get("yaml"), ","); key == name || field.Tag.Get("flag") == name {
+			return v.Field(i).Interface(), true
+		}
+	}
+
+	return nil, false
+}
+
+// sectionOf returns the section of config with the type T.
+func sectionOf[T any](config *Config) *T {
+	v := reflect.ValueOf(config).Elem()
+
+	for i := range v.NumField() {
+		if section, ok := v.Field(i).Addr().Interface().(*T); ok {
+			return section
+		}
+	}
+
+	return nil
+}
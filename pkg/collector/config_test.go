@@ -0,0 +1,54 @@
+//go:build windows
+
+package collector
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/collector/update"
+	"github.com/prometheus-community/windows_exporter/internal/config"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfigFromFile(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+collector.updates.online: true
+collector.updates.scrape-interval: 1h
+collector:
+  service:
+    include: windows_exporter
+`), 0o600))
+
+	resolver, err := config.NewResolver(file, slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+	require.NoError(t, err)
+
+	decoded := ConfigDefaults
+	require.NoError(t, resolver.Unmarshal("collector", &decoded))
+
+	require.Equal(t, update.Config{Online: true, ScrapeInterval: time.Hour}, decoded.Update)
+	require.Equal(t, "^(?:windows_exporter)$", decoded.Service.ServiceInclude.String())
+}
+
+func TestConfigYAMLKeys(t *testing.T) {
+	t.Parallel()
+
+	// Library users decode the configuration with the YAML keys.
+	var decoded Config
+
+	require.NoError(t, yaml.Unmarshal([]byte(`
+update:
+  online: true
+  scrape_interval: 1h
+`), &decoded))
+
+	require.Equal(t, update.Config{Online: true, ScrapeInterval: time.Hour}, decoded.Update)
+}
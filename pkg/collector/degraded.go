@@ -0,0 +1,191 @@
+//go:build windows
+
+package collector
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// BuildDegraded builds the collectors like Build. Unlike Build, collectors which fail to build do not fail
+// the call. They are marked as unavailable and their build is retried in the background. The delay between
+// the retries starts at backoff and doubles after every failed retry, up to maxBackoff.
+// Once the build succeeds, the collector is added to the scraped collectors.
+// Collectors, which fail to build on a later Reload, are retried the same way.
+//
+// SetPerfCounterQuery has to be called afterward like with Build.
+func (c *MetricCollectors) BuildDegraded(logger *slog.Logger, backoff, maxBackoff time.Duration) error {
+	if err := c.initMI(); err != nil {
+		return fmt.Errorf("error from initialize MI: %w", err)
+	}
+
+	c.degraded = true
+	c.buildRetryBackoff = backoff
+	c.buildRetryMaxBackoff = maxBackoff
+
+	buildErrs := c.build(logger)
+	if len(buildErrs) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, err := range buildErrs {
+		collector := c.Collectors[name]
+		delete(c.Collectors, name)
+
+		c.markUnavailable(logger, name, collector, err)
+	}
+
+	return nil
+}
+
+// markUnavailable marks collector as unavailable, because its build failed with err, and retries its build in the background.
+// The caller must hold the lock of c.
+func (c *MetricCollectors) markUnavailable(logger *slog.Logger, name string, collector Collector, err error) {
+	if c.unavailableCollectors == nil {
+		c.unavailableCollectors = make(Map)
+	}
+
+	if c.buildRetryStopCh == nil {
+		c.buildRetryStopCh = make(chan struct{})
+	}
+
+	logger.Error(fmt.Sprintf("collector %s failed to build, retrying in %s", name, c.buildRetryBackoff),
+		slog.Any("err", err),
+	)
+
+	c.statusFor(name).SetBuildError(err)
+
+	c.unavailableCollectors[name] = collector
+
+	c.buildRetryWg.Add(1)
+
+	go c.retryBuild(logger.With(slog.String("collector", name)), name, collector, c.buildRetryBackoff, c.buildRetryMaxBackoff)
+}
+
+// retryBuild builds an unavailable collector until the build succeeds, the collector was replaced or disabled by a Reload
+// or the MetricCollectors are closed.
+func (c *MetricCollectors) retryBuild(logger *slog.Logger, name string, collector Collector, backoff, maxBackoff time.Duration) {
+	defer c.buildRetryWg.Done()
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-c.buildRetryStopCh:
+			return
+		case <-timer.C:
+		}
+
+		if !c.isUnavailable(name, collector) {
+			return
+		}
+
+		// The failed build may have left the collector partially built.
+		if err := collector.Close(logger); err != nil {
+			logger.Warn("failed to close collector "+name+" before retrying its build",
+				slog.Any("err", err),
+			)
+		}
+
+		err := collector.Build(logger, c.MISession)
+		if err == nil {
+			c.addBuiltCollector(logger, name, collector)
+
+			return
+		}
+
+		c.statusFor(name).SetBuildError(err)
+
+		backoff = min(backoff*2, maxBackoff)
+
+		logger.Error(fmt.Sprintf("collector %s failed to build, retrying in %s", name, backoff),
+			slog.Any("err", err),
+		)
+
+		timer.Reset(backoff)
+	}
+}
+
+// isUnavailable returns true, if collector is still the unavailable collector of the given name.
+func (c *MetricCollectors) isUnavailable(name string, collector Collector) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.unavailableCollectors[name] == collector
+}
+
+// addBuiltCollector adds a collector, whose build succeeded on retry, to the scraped collectors.
+// If the collector was replaced by a Reload in the meantime, the collector is closed instead.
+func (c *MetricCollectors) addBuiltCollector(logger *slog.Logger, name string, collector Collector) {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	if !c.isUnavailable(name, collector) {
+		if err := collector.Close(logger); err != nil {
+			logger.Warn("failed to close replaced collector "+name,
+				slog.Any("err", err),
+			)
+		}
+
+		return
+	}
+
+	c.mu.RLock()
+	collectors := make(Map, len(c.Collectors)+1)
+
+	for n, existing := range c.Collectors {
+		collectors[n] = existing
+	}
+
+	c.mu.RUnlock()
+
+	collectors[name] = collector
+
+	perfCounterQuery, err := buildPerfCounterQuery(logger, collectors)
+	if err != nil {
+		logger.Error("couldn't build performance counter query, collector "+name+" stays unavailable",
+			slog.Any("err", err),
+		)
+
+		if err = collector.Close(logger); err != nil {
+			logger.Warn("failed to close collector "+name,
+				slog.Any("err", err),
+			)
+		}
+
+		return
+	}
+
+	c.mu.Lock()
+	c.Collectors = collectors
+	c.PerfCounterQuery = perfCounterQuery
+	delete(c.unavailableCollectors, name)
+	c.statusFor(name).SetBuildError(nil)
+
+	c.resetPerfCounterQueryCache()
+	c.mu.Unlock()
+
+	logger.Info(fmt.Sprintf("collector %s built successfully on retry and is available now", name))
+}
+
+// stopBuildRetries stops the background builds of the unavailable collectors. It may be called multiple times.
+func (c *MetricCollectors) stopBuildRetries() {
+	c.buildRetryStopOnce.Do(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		// Builds, which fail later on, are not retried.
+		if c.buildRetryStopCh == nil {
+			c.buildRetryStopCh = make(chan struct{})
+		}
+
+		close(c.buildRetryStopCh)
+	})
+
+	c.buildRetryWg.Wait()
+}
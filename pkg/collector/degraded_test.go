@@ -0,0 +1,116 @@
+//go:build windows
+
+package collector
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyBuildCollector fails to build until failures builds failed. It records the calls of Build and Close.
+type flakyBuildCollector struct {
+	*testCollector
+
+	mu       sync.Mutex
+	failures int
+	calls    []string
+}
+
+func (c *flakyBuildCollector) Build(*slog.Logger, *mi.Session) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls = append(c.calls, "build")
+
+	if c.failures > 0 {
+		c.failures--
+
+		return errors.New("build failed")
+	}
+
+	return nil
+}
+
+func (c *flakyBuildCollector) Close(logger *slog.Logger) error {
+	c.mu.Lock()
+	c.calls = append(c.calls, "close")
+	c.mu.Unlock()
+
+	return c.testCollector.Close(logger)
+}
+
+func (c *flakyBuildCollector) recordedCalls() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.calls...)
+}
+
+func newDegradedCollectors() *MetricCollectors {
+	c := New(Map{})
+	c.degraded = true
+	c.buildRetryBackoff = time.Millisecond
+	c.buildRetryMaxBackoff = time.Millisecond
+
+	return c
+}
+
+func TestRetryBuildClosesBeforeRetry(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	c := newDegradedCollectors()
+	flaky := &flakyBuildCollector{testCollector: newTestCollector(), failures: 1}
+
+	c.mu.Lock()
+	c.markUnavailable(logger, "flaky", flaky, errors.New("build failed"))
+	c.mu.Unlock()
+
+	require.Eventually(t, func() bool {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+
+		return c.Collectors["flaky"] == flaky
+	}, 5*time.Second, time.Millisecond)
+
+	// Every retry closes the collector, which failed to build before.
+	require.Equal(t, []string{"close", "build", "close", "build"}, flaky.recordedCalls())
+	require.Empty(t, c.unavailableCollectors)
+
+	c.stopBuildRetries()
+}
+
+func TestStopBuildRetries(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	c := newDegradedCollectors()
+	c.buildRetryBackoff = time.Hour
+	c.buildRetryMaxBackoff = time.Hour
+
+	flaky := &flakyBuildCollector{testCollector: newTestCollector(), failures: 1}
+
+	c.mu.Lock()
+	c.markUnavailable(logger, "flaky", flaky, errors.New("build failed"))
+	c.mu.Unlock()
+
+	// A second stop must not panic.
+	c.stopBuildRetries()
+	c.stopBuildRetries()
+
+	require.Empty(t, flaky.recordedCalls())
+
+	// Builds, which fail after the stop, are not retried.
+	c.mu.Lock()
+	c.markUnavailable(logger, "other", &flakyBuildCollector{testCollector: newTestCollector()}, errors.New("build failed"))
+	c.mu.Unlock()
+
+	c.stopBuildRetries()
+}
@@ -0,0 +1,42 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LegacyCollector is the Collector interface without support for cancellation.
+// Implementations can be used as Collector by wrapping them with NewLegacyCollectorAdapter.
+type LegacyCollector interface {
+	Build(logger *slog.Logger, miSession *mi.Session) error
+	// Close closes the collector
+	Close(logger *slog.Logger) error
+	// GetName get the name of the collector
+	GetName() string
+	// GetPerfCounter returns the perf counter required by the collector
+	GetPerfCounter(logger *slog.Logger) ([]string, error)
+	// Collect Get new metrics and expose them via prometheus registry.
+	Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) (err error)
+}
+
+// Interface guard.
+var _ Collector = (*legacyCollectorAdapter)(nil)
+
+// legacyCollectorAdapter implements Collector for a LegacyCollector.
+type legacyCollectorAdapter struct {
+	LegacyCollector
+}
+
+// NewLegacyCollectorAdapter returns a Collector for a collector implementing the previous Collector interface.
+// The context of a scrape is not passed to the wrapped collector. A timed-out collection is abandoned, but keeps running.
+func NewLegacyCollectorAdapter(collector LegacyCollector) Collector { //nolint:ireturn
+	return &legacyCollectorAdapter{LegacyCollector: collector}
+}
+
+func (a *legacyCollectorAdapter) Collect(_ context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) error {
+	return a.LegacyCollector.Collect(scrapeCtx, logger, ch)
+}
@@ -53,13 +53,21 @@ import (
 	"github.com/prometheus-community/windows_exporter/internal/collector/vmware"
 )
 
-func NewBuilderWithFlags[C Collector](fn BuilderWithFlags[C]) BuilderWithFlags[Collector] {
-	return func(app *kingpin.Application) Collector {
+// NewBuilderWithFlags returns a builder for a collector without configuration.
+func NewBuilderWithFlags[C Collector](fn BuilderWithFlags[C]) BuilderWithConfigAndFlags {
+	return func(app *kingpin.Application, _ *Config) Collector {
 		return fn(app)
 	}
 }
 
-var BuildersWithFlags = map[string]BuilderWithFlags[Collector]{
+// NewBuilderWithConfigAndFlags returns a builder for a collector, which starts from its section of the configuration.
+func NewBuilderWithConfigAndFlags[C Collector, T any](fn func(*kingpin.Application, *T) C) BuilderWithConfigAndFlags {
+	return func(app *kingpin.Application, config *Config) Collector {
+		return fn(app, sectionOf[T](config))
+	}
+}
+
+var BuildersWithFlags = map[string]BuilderWithConfigAndFlags{
 	ad.Name:                NewBuilderWithFlags(ad.NewWithFlags),
 	adcs.Name:              NewBuilderWithFlags(adcs.NewWithFlags),
 	adfs.Name:              NewBuilderWithFlags(adfs.NewWithFlags),
@@ -68,43 +76,43 @@ var BuildersWithFlags = map[string]BuilderWithFlags[Collector]{
 	cpu.Name:               NewBuilderWithFlags(cpu.NewWithFlags),
 	cpu_info.Name:          NewBuilderWithFlags(cpu_info.NewWithFlags),
 	cs.Name:                NewBuilderWithFlags(cs.NewWithFlags),
-	dfsr.Name:              NewBuilderWithFlags(dfsr.NewWithFlags),
+	dfsr.Name:              NewBuilderWithConfigAndFlags(dfsr.NewWithFlags),
 	dhcp.Name:              NewBuilderWithFlags(dhcp.NewWithFlags),
 	diskdrive.Name:         NewBuilderWithFlags(diskdrive.NewWithFlags),
 	dns.Name:               NewBuilderWithFlags(dns.NewWithFlags),
-	exchange.Name:          NewBuilderWithFlags(exchange.NewWithFlags),
-	filetime.Name:          NewBuilderWithFlags(filetime.NewWithFlags),
+	exchange.Name:          NewBuilderWithConfigAndFlags(exchange.NewWithFlags),
+	filetime.Name:          NewBuilderWithConfigAndFlags(filetime.NewWithFlags),
 	fsrmquota.Name:         NewBuilderWithFlags(fsrmquota.NewWithFlags),
 	hyperv.Name:            NewBuilderWithFlags(hyperv.NewWithFlags),
-	iis.Name:               NewBuilderWithFlags(iis.NewWithFlags),
+	iis.Name:               NewBuilderWithConfigAndFlags(iis.NewWithFlags),
 	license.Name:           NewBuilderWithFlags(license.NewWithFlags),
-	logical_disk.Name:      NewBuilderWithFlags(logical_disk.NewWithFlags),
+	logical_disk.Name:      NewBuilderWithConfigAndFlags(logical_disk.NewWithFlags),
 	logon.Name:             NewBuilderWithFlags(logon.NewWithFlags),
 	memory.Name:            NewBuilderWithFlags(memory.NewWithFlags),
-	mscluster.Name:         NewBuilderWithFlags(mscluster.NewWithFlags),
-	msmq.Name:              NewBuilderWithFlags(msmq.NewWithFlags),
-	mssql.Name:             NewBuilderWithFlags(mssql.NewWithFlags),
-	net.Name:               NewBuilderWithFlags(net.NewWithFlags),
-	netframework.Name:      NewBuilderWithFlags(netframework.NewWithFlags),
+	mscluster.Name:         NewBuilderWithConfigAndFlags(mscluster.NewWithFlags),
+	msmq.Name:              NewBuilderWithConfigAndFlags(msmq.NewWithFlags),
+	mssql.Name:             NewBuilderWithConfigAndFlags(mssql.NewWithFlags),
+	net.Name:               NewBuilderWithConfigAndFlags(net.NewWithFlags),
+	netframework.Name:      NewBuilderWithConfigAndFlags(netframework.NewWithFlags),
 	nps.Name:               NewBuilderWithFlags(nps.NewWithFlags),
 	os.Name:                NewBuilderWithFlags(os.NewWithFlags),
-	perfdata.Name:          NewBuilderWithFlags(perfdata.NewWithFlags),
-	physical_disk.Name:     NewBuilderWithFlags(physical_disk.NewWithFlags),
-	printer.Name:           NewBuilderWithFlags(printer.NewWithFlags),
-	process.Name:           NewBuilderWithFlags(process.NewWithFlags),
+	perfdata.Name:          NewBuilderWithConfigAndFlags(perfdata.NewWithFlags),
+	physical_disk.Name:     NewBuilderWithConfigAndFlags(physical_disk.NewWithFlags),
+	printer.Name:           NewBuilderWithConfigAndFlags(printer.NewWithFlags),
+	process.Name:           NewBuilderWithConfigAndFlags(process.NewWithFlags),
 	remote_fx.Name:         NewBuilderWithFlags(remote_fx.NewWithFlags),
-	scheduled_task.Name:    NewBuilderWithFlags(scheduled_task.NewWithFlags),
-	service.Name:           NewBuilderWithFlags(service.NewWithFlags),
+	scheduled_task.Name:    NewBuilderWithConfigAndFlags(scheduled_task.NewWithFlags),
+	service.Name:           NewBuilderWithConfigAndFlags(service.NewWithFlags),
 	smb.Name:               NewBuilderWithFlags(smb.NewWithFlags),
 	smbclient.Name:         NewBuilderWithFlags(smbclient.NewWithFlags),
-	smtp.Name:              NewBuilderWithFlags(smtp.NewWithFlags),
+	smtp.Name:              NewBuilderWithConfigAndFlags(smtp.NewWithFlags),
 	system.Name:            NewBuilderWithFlags(system.NewWithFlags),
-	tcp.Name:               NewBuilderWithFlags(tcp.NewWithFlags),
+	tcp.Name:               NewBuilderWithConfigAndFlags(tcp.NewWithFlags),
 	terminal_services.Name: NewBuilderWithFlags(terminal_services.NewWithFlags),
-	textfile.Name:          NewBuilderWithFlags(textfile.NewWithFlags),
+	textfile.Name:          NewBuilderWithConfigAndFlags(textfile.NewWithFlags),
 	thermalzone.Name:       NewBuilderWithFlags(thermalzone.NewWithFlags),
 	time.Name:              NewBuilderWithFlags(time.NewWithFlags),
-	update.Name:            NewBuilderWithFlags(update.NewWithFlags),
+	update.Name:            NewBuilderWithConfigAndFlags(update.NewWithFlags),
 	vmware.Name:            NewBuilderWithFlags(vmware.NewWithFlags),
 }
 
@@ -4,13 +4,19 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"runtime/debug"
+	"slices"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/prometheus-community/windows_exporter/internal/breaker"
+	"github.com/prometheus-community/windows_exporter/internal/collectorstatus"
+	"github.com/prometheus-community/windows_exporter/internal/relabel"
 	"github.com/prometheus-community/windows_exporter/internal/types"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -23,12 +29,20 @@ type Prometheus struct {
 	maxScrapeDuration time.Duration
 	logger            *slog.Logger
 	metricCollectors  *MetricCollectors
+	// collectorNames holds the subset of collectors to collect. nil collects all collectors.
+	collectorNames []string
 
 	// Base metrics returned by Prometheus
 	scrapeDurationDesc          *prometheus.Desc
 	collectorScrapeDurationDesc *prometheus.Desc
 	collectorScrapeSuccessDesc  *prometheus.Desc
 	collectorScrapeTimeoutDesc  *prometheus.Desc
+	collectorCacheAgeDesc       *prometheus.Desc
+	collectorCacheRefreshDesc   *prometheus.Desc
+	relabelDroppedDesc          *prometheus.Desc
+	circuitBreakerStateDesc     *prometheus.Desc
+	collectorBuildSuccessDesc   *prometheus.Desc
+	circuitBreakerRetryDesc     *prometheus.Desc
 	snapshotDuration            *prometheus.Desc
 }
 
@@ -43,6 +57,11 @@ const (
 	pending collectorStatusCode = iota
 	success
 	failed
+	// skipped is the status of a collector, which is skipped by its open circuit breaker.
+	skipped
+	// waiting is the status of a collector with a background collection interval, whose first background collection
+	// has not finished yet. It is neither reported as success nor as failure.
+	waiting
 )
 
 // NewPrometheusCollector returns a new Prometheus where the set of MetricCollectors must
@@ -76,6 +95,42 @@ func (c *MetricCollectors) NewPrometheusCollector(timeout time.Duration, logger
 			[]string{"collector"},
 			nil,
 		),
+		collectorCacheAgeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, "exporter", "collector_cache_age_seconds"),
+			"windows_exporter: Age of the cached metrics of a collector running in the background.",
+			[]string{"collector"},
+			nil,
+		),
+		collectorCacheRefreshDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, "exporter", "collector_cache_last_refresh_timestamp_seconds"),
+			"windows_exporter: Timestamp of the last successful background collection of a collector.",
+			[]string{"collector"},
+			nil,
+		),
+		relabelDroppedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, "exporter", "relabel_dropped_series_total"),
+			"windows_exporter: Number of series dropped by a metric relabel rule.",
+			[]string{"rule", "action"},
+			nil,
+		),
+		circuitBreakerStateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, "exporter", "collector_circuit_breaker_state"),
+			"windows_exporter: State of the circuit breaker of a collector. 0 is closed, 1 is open and 2 is half-open.",
+			[]string{"collector"},
+			nil,
+		),
+		circuitBreakerRetryDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, "exporter", "collector_circuit_breaker_next_retry_timestamp_seconds"),
+			"windows_exporter: Timestamp of the next retry of a collector, which is skipped by its circuit breaker.",
+			[]string{"collector"},
+			nil,
+		),
+		collectorBuildSuccessDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(types.Namespace, "exporter", "collector_build_success"),
+			"windows_exporter: Whether the collector was built successfully. Collectors which failed to build are retried in the background.",
+			[]string{"collector"},
+			nil,
+		),
 		snapshotDuration: prometheus.NewDesc(
 			prometheus.BuildFQName(types.Namespace, "exporter", "perflib_snapshot_duration_seconds"),
 			"Duration of perflib snapshot capture",
@@ -85,6 +140,23 @@ func (c *MetricCollectors) NewPrometheusCollector(timeout time.Duration, logger
 	}
 }
 
+// NewPrometheusCollectorFor returns a new Prometheus like NewPrometheusCollector,
+// which collects only the given subset of the MetricCollectors.
+func (c *MetricCollectors) NewPrometheusCollectorFor(collectorNames []string, timeout time.Duration, logger *slog.Logger) (*Prometheus, error) {
+	c.mu.RLock()
+	_, err := c.selectCollectors(collectorNames)
+	c.mu.RUnlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := c.NewPrometheusCollector(timeout, logger)
+	p.collectorNames = collectorNames
+
+	return p, nil
+}
+
 func (p *Prometheus) Describe(_ chan<- *prometheus.Desc) {}
 
 // Collect sends the collected metrics from each of the MetricCollectors to
@@ -92,8 +164,21 @@ func (p *Prometheus) Describe(_ chan<- *prometheus.Desc) {}
 func (p *Prometheus) Collect(ch chan<- prometheus.Metric) {
 	t := time.Now()
 
+	// The scrape uses the collectors taken at its start. A pending reload doesn't block the scrape. The reload closes
+	// the replaced collectors, after the scrapes using them have finished.
+	s, err := p.startScrape()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(p.collectorScrapeSuccessDesc, fmt.Errorf("failed to prepare scrape: %w", err))
+
+		return
+	}
+
+	defer s.done()
+
+	collectors, perfCounterQuery := s.collectors, s.perfCounterQuery
+
 	// Scrape Performance Counters for all collectors
-	scrapeContext, err := p.metricCollectors.PrepareScrapeContext()
+	scrapeContext, err := prepareScrapeContext(perfCounterQuery)
 
 	ch <- prometheus.MustNewConstMetric(
 		p.snapshotDuration,
@@ -109,21 +194,21 @@ func (p *Prometheus) Collect(ch chan<- prometheus.Metric) {
 
 	// WaitGroup to wait for all collectors to finish
 	wg := sync.WaitGroup{}
-	wg.Add(len(p.metricCollectors.Collectors))
+	wg.Add(len(collectors))
 
 	// Using a channel to collect the status of each collector
 	// A channel is safe to use concurrently while a map is not
-	collectorStatusCh := make(chan collectorStatus, len(p.metricCollectors.Collectors))
+	collectorStatusCh := make(chan collectorStatus, len(collectors))
 
 	// Execute all collectors concurrently
 	// timeout handling is done in the execute function
-	for name, metricsCollector := range p.metricCollectors.Collectors {
+	for name, metricsCollector := range collectors {
 		go func(name string, metricsCollector Collector) {
 			defer wg.Done()
 
 			collectorStatusCh <- collectorStatus{
 				name:       name,
-				statusCode: p.execute(name, metricsCollector, scrapeContext, ch),
+				statusCode: p.executeWithBreaker(s, name, metricsCollector, scrapeContext, ch),
 			}
 		}(name, metricsCollector)
 	}
@@ -135,6 +220,10 @@ func (p *Prometheus) Collect(ch chan<- prometheus.Metric) {
 	close(collectorStatusCh)
 
 	for status := range collectorStatusCh {
+		if status.statusCode == waiting {
+			continue
+		}
+
 		var successValue, timeoutValue float64
 		if status.statusCode == pending {
 			timeoutValue = 1.0
@@ -159,6 +248,85 @@ func (p *Prometheus) Collect(ch chan<- prometheus.Metric) {
 		)
 	}
 
+	for name, metricsCollector := range collectors {
+		cached, ok := metricsCollector.(*cachedCollector)
+		if !ok {
+			continue
+		}
+
+		lastRefresh := cached.LastRefresh()
+		if lastRefresh.IsZero() {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			p.collectorCacheAgeDesc,
+			prometheus.GaugeValue,
+			time.Since(lastRefresh).Seconds(),
+			name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			p.collectorCacheRefreshDesc,
+			prometheus.GaugeValue,
+			float64(lastRefresh.UnixNano())/1e9,
+			name,
+		)
+	}
+
+	for name := range collectors {
+		ch <- prometheus.MustNewConstMetric(
+			p.collectorBuildSuccessDesc,
+			prometheus.GaugeValue,
+			1,
+			name,
+		)
+	}
+
+	for _, name := range s.unavailable {
+		ch <- prometheus.MustNewConstMetric(
+			p.collectorBuildSuccessDesc,
+			prometheus.GaugeValue,
+			0,
+			name,
+		)
+	}
+
+	for name := range collectors {
+		b := p.metricCollectors.breakerFor(name)
+		if b == nil {
+			break
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			p.circuitBreakerStateDesc,
+			prometheus.GaugeValue,
+			float64(b.State()),
+			name,
+		)
+
+		if nextRetry := b.NextRetry(); !nextRetry.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				p.circuitBreakerRetryDesc,
+				prometheus.GaugeValue,
+				float64(nextRetry.UnixNano())/1e9,
+				name,
+			)
+		}
+	}
+
+	if rules := s.relabelRules; rules != nil {
+		for i := range rules.Len() {
+			ch <- prometheus.MustNewConstMetric(
+				p.relabelDroppedDesc,
+				prometheus.CounterValue,
+				float64(rules.Dropped(i)),
+				strconv.Itoa(i),
+				string(rules.Action(i)),
+			)
+		}
+	}
+
 	ch <- prometheus.MustNewConstMetric(
 		p.scrapeDurationDesc,
 		prometheus.GaugeValue,
@@ -166,7 +334,103 @@ func (p *Prometheus) Collect(ch chan<- prometheus.Metric) {
 	)
 }
 
-func (p *Prometheus) execute(name string, c Collector, scrapeCtx *types.ScrapeContext, ch chan<- prometheus.Metric) collectorStatusCode {
+// scrape holds the state of the MetricCollectors, which a single scrape uses. A Reload doesn't change it.
+type scrape struct {
+	collectors       Map
+	perfCounterQuery string
+	collectorOptions map[string]*CollectorOptions
+	relabelRules     *relabel.Rules
+	// unavailable holds the names of the requested collectors, which are unavailable.
+	unavailable []string
+	// done has to be called, once the scrape has finished. Afterward, a Reload may close the collectors.
+	done func()
+}
+
+// startScrape returns the state of the MetricCollectors for a scrape.
+func (p *Prometheus) startScrape() (*scrape, error) {
+	p.metricCollectors.mu.RLock()
+	defer p.metricCollectors.mu.RUnlock()
+
+	collectors, perfCounterQuery, err := p.selectCollectors()
+	if err != nil {
+		return nil, err
+	}
+
+	unavailable := make([]string, 0, len(p.metricCollectors.unavailableCollectors))
+
+	for name := range p.metricCollectors.unavailableCollectors {
+		if p.collectorNames == nil || slices.Contains(p.collectorNames, name) {
+			unavailable = append(unavailable, name)
+		}
+	}
+
+	return &scrape{
+		collectors:       collectors,
+		perfCounterQuery: perfCounterQuery,
+		collectorOptions: p.metricCollectors.CollectorOptions,
+		relabelRules:     p.metricCollectors.MetricRelabelRules,
+		unavailable:      unavailable,
+		done:             p.metricCollectors.trackScrape(),
+	}, nil
+}
+
+// selectCollectors returns the collectors of the scrape and their perflib query.
+// The caller must hold the read lock of the MetricCollectors.
+func (p *Prometheus) selectCollectors() (Map, string, error) {
+	if p.collectorNames == nil {
+		return p.metricCollectors.Collectors, p.metricCollectors.PerfCounterQuery, nil
+	}
+
+	collectors, err := p.metricCollectors.selectCollectors(p.collectorNames)
+	if err != nil {
+		return nil, "", err
+	}
+
+	perfCounterQuery, err := p.metricCollectors.perfCounterQueryFor(p.logger, p.collectorNames)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return collectors, perfCounterQuery, nil
+}
+
+// executeWithBreaker executes a collector, if its circuit breaker allows it.
+// Failures and timeouts are reported to the circuit breaker.
+func (p *Prometheus) executeWithBreaker(s *scrape, name string, c Collector, scrapeCtx *types.ScrapeContext, ch chan<- prometheus.Metric) collectorStatusCode {
+	b := p.metricCollectors.breakerFor(name)
+	if b == nil {
+		return p.execute(s, name, c, scrapeCtx, ch)
+	}
+
+	if !b.Allow() {
+		p.logger.Debug(fmt.Sprintf("collector %s skipped by circuit breaker until %s", name, b.NextRetry().Format(time.RFC3339)))
+
+		return skipped
+	}
+
+	statusCode := p.execute(s, name, c, scrapeCtx, ch)
+	if statusCode == waiting {
+		return statusCode
+	}
+
+	if statusCode == success {
+		if b.State() == breaker.HalfOpen {
+			p.logger.Info(fmt.Sprintf("collector %s recovered, circuit breaker closed", name))
+		}
+
+		b.Success()
+
+		return statusCode
+	}
+
+	if b.Failure() {
+		p.logger.Warn(fmt.Sprintf("collector %s failed repeatedly, circuit breaker opened until %s", name, b.NextRetry().Format(time.RFC3339)))
+	}
+
+	return statusCode
+}
+
+func (p *Prometheus) execute(s *scrape, name string, c Collector, scrapeCtx *types.ScrapeContext, ch chan<- prometheus.Metric) collectorStatusCode {
 	var (
 		err        error
 		numMetrics int
@@ -179,7 +443,10 @@ func (p *Prometheus) execute(name string, c Collector, scrapeCtx *types.ScrapeCo
 	bufCh := make(chan prometheus.Metric, 1000)
 	errCh := make(chan error, 1)
 
-	ctx, cancel := context.WithTimeout(context.Background(), p.maxScrapeDuration)
+	maxScrapeDuration := p.collectorTimeout(s, name)
+	relabelRules := s.relabelRules
+
+	ctx, cancel := context.WithTimeout(context.Background(), maxScrapeDuration)
 	defer cancel()
 
 	// Execute the collector
@@ -194,7 +461,7 @@ func (p *Prometheus) execute(name string, c Collector, scrapeCtx *types.ScrapeCo
 			close(bufCh)
 		}()
 
-		errCh <- c.Collect(scrapeCtx, p.logger, bufCh)
+		errCh <- c.Collect(ctx, scrapeCtx, p.logger, bufCh)
 	}()
 
 	wg := sync.WaitGroup{}
@@ -220,11 +487,31 @@ func (p *Prometheus) execute(name string, c Collector, scrapeCtx *types.ScrapeCo
 					return
 				}
 
-				if !timeout.Load() {
-					ch <- m
+				if timeout.Load() {
+					continue
+				}
 
-					numMetrics++
+				if relabelRules != nil {
+					var (
+						keep       bool
+						relabelErr error
+					)
+
+					m, keep, relabelErr = p.metricCollectors.relabelCache.relabelMetric(relabelRules, m)
+					if relabelErr != nil {
+						p.logger.Debug("failed to relabel metric of collector "+name,
+							slog.Any("err", relabelErr),
+						)
+					}
+
+					if !keep {
+						continue
+					}
 				}
+
+				ch <- m
+
+				numMetrics++
 			}
 		}
 	}()
@@ -254,7 +541,15 @@ func (p *Prometheus) execute(name string, c Collector, scrapeCtx *types.ScrapeCo
 			name,
 		)
 
-		p.logger.Warn(fmt.Sprintf("collector %s timeouted after %s, resulting in %d metrics", name, p.maxScrapeDuration, numMetrics))
+		p.logger.Warn(fmt.Sprintf("collector %s timeouted after %s, resulting in %d metrics", name, maxScrapeDuration, numMetrics))
+
+		p.metricCollectors.statusFor(name).Record(collectorstatus.Run{
+			Time:            t,
+			DurationSeconds: duration.Seconds(),
+			Metrics:         numMetrics,
+			Outcome:         collectorstatus.Timeout,
+			Error:           fmt.Sprintf("timeout after %s", maxScrapeDuration),
+		})
 
 		go func() {
 			// Drain channel in case of premature return to not leak a goroutine.
@@ -266,15 +561,45 @@ func (p *Prometheus) execute(name string, c Collector, scrapeCtx *types.ScrapeCo
 		return pending
 	}
 
+	if errors.Is(err, ErrNotCollectedYet) {
+		p.logger.Debug(fmt.Sprintf("collector %s is waiting for its first background collection", name))
+
+		return waiting
+	}
+
+	run := collectorstatus.Run{
+		Time:            t,
+		DurationSeconds: duration.Seconds(),
+		Metrics:         numMetrics,
+		Outcome:         collectorstatus.Success,
+	}
+
 	if err != nil {
 		p.logger.Error(fmt.Sprintf("collector %s failed after %s, resulting in %d metrics", name, duration, numMetrics),
 			slog.Any("err", err),
 		)
 
+		run.Outcome = collectorstatus.Failure
+		run.Error = err.Error()
+		p.metricCollectors.statusFor(name).Record(run)
+
 		return failed
 	}
 
+	p.metricCollectors.statusFor(name).Record(run)
+
 	p.logger.Debug(fmt.Sprintf("collector %s succeeded after %s, resulting in %d metrics", name, duration, numMetrics))
 
 	return success
 }
+
+// collectorTimeout returns the timeout of a collector. A timeout configured for
+// the collector is capped by the timeout of the scrape.
+func (p *Prometheus) collectorTimeout(s *scrape, name string) time.Duration {
+	options, ok := s.collectorOptions[name]
+	if !ok || options.Timeout <= 0 {
+		return p.maxScrapeDuration
+	}
+
+	return min(options.Timeout, p.maxScrapeDuration)
+}
@@ -0,0 +1,69 @@
+//go:build windows
+
+package collector
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectorTimeout(t *testing.T) {
+	t.Parallel()
+
+	collectors := &MetricCollectors{
+		CollectorOptions: map[string]*CollectorOptions{
+			"short": {Timeout: time.Second},
+			"long":  {Timeout: time.Hour},
+			"zero":  {Timeout: 0},
+		},
+	}
+
+	p := collectors.NewPrometheusCollector(time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	s, err := p.startScrape()
+	require.NoError(t, err)
+
+	defer s.done()
+
+	for name, expected := range map[string]time.Duration{
+		// The timeout of the collector overrides the timeout of the scrape.
+		"short": time.Second,
+		// The timeout of the collector is capped by the timeout of the scrape.
+		"long": time.Minute,
+		// Without a timeout of the collector, the timeout of the scrape applies.
+		"zero":    time.Minute,
+		"missing": time.Minute,
+	} {
+		require.Equal(t, expected, p.collectorTimeout(s, name), name)
+	}
+}
+
+func TestExecuteCollectorTimeout(t *testing.T) {
+	t.Parallel()
+
+	collectors := &MetricCollectors{
+		CollectorOptions: map[string]*CollectorOptions{
+			"test": {Timeout: 10 * time.Millisecond},
+		},
+	}
+
+	p := collectors.NewPrometheusCollector(time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	s, err := p.startScrape()
+	require.NoError(t, err)
+
+	defer s.done()
+
+	// The collection never finishes, because the test collector has no result.
+	ch := make(chan prometheus.Metric, 10)
+	start := time.Now()
+
+	require.Equal(t, pending, p.execute(s, "test", newTestCollector(), &types.ScrapeContext{}, ch))
+	require.Less(t, time.Since(start), time.Minute)
+}
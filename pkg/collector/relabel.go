@@ -0,0 +1,183 @@
+package collector
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/prometheus-community/windows_exporter/internal/relabel"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// maxRelabelDescs bounds the descriptors of relabelCache. Some collectors, like textfile, create new descriptors on every scrape.
+const maxRelabelDescs = 10000
+
+var errInvalidDesc = errors.New("unable to describe metric")
+
+// Interface guard.
+var _ prometheus.Metric = (*relabeledMetric)(nil)
+
+// relabeledMetric is a metric with a changed name or changed labels.
+type relabeledMetric struct {
+	desc   *prometheus.Desc
+	metric *dto.Metric
+}
+
+func (m *relabeledMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
+
+func (m *relabeledMetric) Write(out *dto.Metric) error {
+	out.Label = m.metric.GetLabel()
+	out.Gauge = m.metric.GetGauge()
+	out.Counter = m.metric.GetCounter()
+	out.Summary = m.metric.GetSummary()
+	out.Untyped = m.metric.GetUntyped()
+	out.Histogram = m.metric.GetHistogram()
+	out.TimestampMs = m.metric.TimestampMs
+
+	return nil
+}
+
+// relabelCache caches the name and the help text per metric descriptor and the descriptors of the relabeled metrics,
+// so the metrics of a scrape are described once.
+type relabelCache struct {
+	mu    sync.Mutex
+	descs map[*prometheus.Desc]*relabelDesc
+}
+
+type relabelDesc struct {
+	name string
+	help string
+	// relabeled holds the descriptors of the relabeled metrics by their metric name.
+	relabeled map[string]*prometheus.Desc
+}
+
+// relabelMetric applies the relabel rules to a metric. It returns false, if the metric is dropped.
+func (c *relabelCache) relabelMetric(rules *relabel.Rules, m prometheus.Metric) (prometheus.Metric, bool, error) {
+	desc, err := c.describe(m)
+	if err != nil {
+		return m, true, err
+	}
+
+	metric := &dto.Metric{}
+	if err = m.Write(metric); err != nil {
+		return m, true, err
+	}
+
+	labels := make(map[string]string, len(metric.GetLabel())+1)
+	for _, label := range metric.GetLabel() {
+		labels[label.GetName()] = label.GetValue()
+	}
+
+	labels[relabel.MetricNameLabel] = desc.name
+
+	if !rules.Process(labels) {
+		return nil, false, nil
+	}
+
+	name := desc.name
+	if newName := labels[relabel.MetricNameLabel]; relabel.IsValidMetricName(newName) {
+		name = newName
+	}
+
+	delete(labels, relabel.MetricNameLabel)
+
+	metric.Label = make([]*dto.LabelPair, 0, len(labels))
+	for labelName, labelValue := range labels {
+		metric.Label = append(metric.Label, &dto.LabelPair{
+			Name:  &labelName,
+			Value: &labelValue,
+		})
+	}
+
+	slices.SortFunc(metric.Label, func(a, b *dto.LabelPair) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+
+	return &relabeledMetric{
+		desc:   c.relabeledDesc(desc, name),
+		metric: metric,
+	}, true, nil
+}
+
+// describe returns the name and the help text of the descriptor of m.
+func (c *relabelCache) describe(m prometheus.Metric) (*relabelDesc, error) {
+	c.mu.Lock()
+	desc, ok := c.descs[m.Desc()]
+	c.mu.Unlock()
+
+	if ok {
+		return desc, nil
+	}
+
+	family, err := gatherMetric(m)
+	if err != nil {
+		return nil, err
+	}
+
+	desc = &relabelDesc{
+		name:      family.GetName(),
+		help:      family.GetHelp(),
+		relabeled: make(map[string]*prometheus.Desc),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.descs == nil || len(c.descs) >= maxRelabelDescs {
+		c.descs = make(map[*prometheus.Desc]*relabelDesc)
+	}
+
+	c.descs[m.Desc()] = desc
+
+	return desc, nil
+}
+
+// relabeledDesc returns the descriptor of a metric of desc, which is renamed to name by the relabel rules.
+// The labels are part of the relabeled metric, so the descriptor has no variable labels.
+func (c *relabelCache) relabeledDesc(desc *relabelDesc, name string) *prometheus.Desc {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	relabeled, ok := desc.relabeled[name]
+	if !ok {
+		relabeled = prometheus.NewDesc(name, desc.help, nil, nil)
+		desc.relabeled[name] = relabeled
+	}
+
+	return relabeled
+}
+
+// gatherMetric returns the metric family of m. prometheus.Desc doesn't expose the name and the help text,
+// so they are taken from the metric family gathered by a registry.
+func gatherMetric(m prometheus.Metric) (*dto.MetricFamily, error) {
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(metricCollector{metric: m}); err != nil {
+		return nil, errors.Join(errInvalidDesc, err)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		return nil, errors.Join(errInvalidDesc, err)
+	}
+
+	if len(families) != 1 {
+		return nil, errInvalidDesc
+	}
+
+	return families[0], nil
+}
+
+// metricCollector is an unchecked collector of a single metric.
+type metricCollector struct {
+	metric prometheus.Metric
+}
+
+func (c metricCollector) Describe(chan<- *prometheus.Desc) {}
+
+func (c metricCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- c.metric
+}
@@ -0,0 +1,65 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/prometheus-community/windows_exporter/internal/relabel"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestRelabelMetric(t *testing.T) {
+	t.Parallel()
+
+	rules, err := relabel.New([]relabel.Config{
+		{SourceLabels: []string{"__name__"}, Regex: ptr("windows_service_info"), Action: relabel.Drop},
+		{SourceLabels: []string{"__name__"}, Regex: ptr("windows_(.+)"), TargetLabel: "__name__", Replacement: ptr("win_$1")},
+		{SourceLabels: []string{"name"}, Regex: ptr("(.+)"), TargetLabel: "service", Replacement: ptr("$1")},
+	})
+	require.NoError(t, err)
+
+	// Quotes and braces in the help text and the label values are kept.
+	desc := prometheus.NewDesc("windows_service_state", `The state of the service ("{state}").`, []string{"name"}, nil)
+
+	var cache relabelCache
+
+	relabeled := make([]prometheus.Metric, 0, 2)
+
+	for _, name := range []string{`svc"}{`, "wuauserv"} {
+		m, keep, err := cache.relabelMetric(rules, prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, name))
+		require.NoError(t, err)
+		require.True(t, keep)
+
+		relabeled = append(relabeled, m)
+	}
+
+	// The relabeled descriptor is created once per descriptor and metric name.
+	require.Same(t, relabeled[0].Desc(), relabeled[1].Desc())
+
+	family, err := gatherMetric(relabeled[0])
+	require.NoError(t, err)
+	require.Equal(t, "win_service_state", family.GetName())
+	require.Equal(t, `The state of the service ("{state}").`, family.GetHelp())
+	require.Equal(t, dto.MetricType_GAUGE, family.GetType())
+	require.Equal(t, map[string]string{"name": `svc"}{`, "service": `svc"}{`}, labelMap(family.GetMetric()[0]))
+
+	_, keep, err := cache.relabelMetric(rules, prometheus.MustNewConstMetric(
+		prometheus.NewDesc("windows_service_info", "help", nil, nil), prometheus.GaugeValue, 1,
+	))
+	require.NoError(t, err)
+	require.False(t, keep)
+}
+
+func labelMap(metric *dto.Metric) map[string]string {
+	labels := make(map[string]string, len(metric.GetLabel()))
+	for _, label := range metric.GetLabel() {
+		labels[label.GetName()] = label.GetValue()
+	}
+
+	return labels
+}
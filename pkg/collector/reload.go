@@ -0,0 +1,231 @@
+//go:build windows
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Reload applies the collectors of newCollectors. Collectors with an unchanged configuration are kept running.
+// Changed and newly enabled collectors are built and swapped in atomically.
+// Afterward, the replaced and disabled collectors are closed.
+//
+// newCollectors has to be created with NewWithFlags or NewWithConfig and must not be built.
+// If a collector fails to build, the reload is aborted and the current collectors are kept.
+// If the collectors were built by BuildDegraded, the reload is applied instead and the collectors,
+// which fail to build, are marked as unavailable and retried in the background.
+func (c *MetricCollectors) Reload(logger *slog.Logger, newCollectors *MetricCollectors) error {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	c.mu.RLock()
+	current := c.Collectors
+
+	changed := make(Map, len(newCollectors.Collectors))
+
+	for name, collector := range newCollectors.Collectors {
+		if _, ok := current[name]; ok {
+			fingerprint := c.configFingerprint(name)
+			if fingerprint != "" && fingerprint == newCollectors.configFingerprint(name) {
+				continue
+			}
+		}
+
+		changed[name] = collector
+	}
+
+	c.mu.RUnlock()
+
+	built := &MetricCollectors{
+		Collectors:       changed,
+		MISession:        c.MISession,
+		CollectorOptions: newCollectors.CollectorOptions,
+	}
+
+	buildErrs := built.build(logger)
+	if len(buildErrs) > 0 && !c.degraded {
+		errs := make([]error, 0, len(buildErrs))
+
+		for name, err := range buildErrs {
+			errs = append(errs, fmt.Errorf("error build collector %s: %w", name, err))
+		}
+
+		built.closeCollectors(logger, func(name string) bool {
+			_, failed := buildErrs[name]
+
+			return !failed
+		})
+
+		return errors.Join(errs...)
+	}
+
+	unavailable := make(Map, len(buildErrs))
+
+	for name := range buildErrs {
+		unavailable[name] = built.Collectors[name]
+		delete(built.Collectors, name)
+	}
+
+	collectors := make(Map, len(newCollectors.Collectors))
+
+	for name := range newCollectors.Collectors {
+		if collector, ok := built.Collectors[name]; ok {
+			collectors[name] = collector
+		} else if _, ok := unavailable[name]; !ok {
+			collectors[name] = current[name]
+		}
+	}
+
+	perfCounterQuery, err := buildPerfCounterQuery(logger, collectors)
+	if err != nil {
+		built.closeCollectors(logger, func(string) bool { return true })
+
+		return fmt.Errorf("couldn't build performance counter query: %w", err)
+	}
+
+	c.mu.Lock()
+	c.Collectors = collectors
+	c.PerfCounterQuery = perfCounterQuery
+	c.CollectorOptions = newCollectors.CollectorOptions
+	c.MetricRelabelRules = newCollectors.MetricRelabelRules
+	c.collectorFlags = newCollectors.collectorFlags
+	c.config = newCollectors.config
+	// The previously unavailable collectors, which were rebuilt or disabled, are no longer unavailable.
+	// Their background builds notice the replacement and stop.
+	for name := range c.unavailableCollectors {
+		_, rebuilt := built.Collectors[name]
+		_, enabled := collectors[name]
+
+		if rebuilt || !enabled {
+			delete(c.unavailableCollectors, name)
+		}
+	}
+
+	// Rebuilt and disabled collectors start with a closed circuit breaker.
+	// Changed options of the circuit breaker apply to all collectors.
+	c.resetBreakers(newCollectors.CircuitBreaker, func(name string) bool {
+		_, rebuilt := built.Collectors[name]
+		_, enabled := collectors[name]
+
+		return rebuilt || !enabled
+	})
+
+	// The recorded runs of rebuilt and disabled collectors are discarded.
+	c.resetStatus(func(name string) bool {
+		_, rebuilt := built.Collectors[name]
+		_, enabled := collectors[name]
+
+		return rebuilt || !enabled
+	})
+
+	for name, collector := range unavailable {
+		c.markUnavailable(logger, name, collector, buildErrs[name])
+	}
+
+	c.resetPerfCounterQueryCache()
+
+	scrapes := c.replaceScrapes()
+	c.mu.Unlock()
+
+	// New scrapes use the new collectors already. The replaced collectors are closed after the scrapes using them have finished.
+	scrapes.Wait()
+
+	old := &MetricCollectors{Collectors: current}
+
+	errs := old.closeCollectors(logger, func(name string) bool {
+		_, rebuilt := built.Collectors[name]
+		_, enabled := collectors[name]
+
+		return rebuilt || !enabled
+	})
+
+	logger.Info(fmt.Sprintf("reloaded collectors, %d of %d collectors rebuilt, %d collectors unavailable",
+		len(built.Collectors), len(collectors)+len(unavailable), len(unavailable)))
+
+	return errors.Join(errs...)
+}
+
+// trackScrape tracks a scrape of the current collectors. The returned function has to be called, once the scrape has finished.
+// The caller must hold the read lock of c.
+func (c *MetricCollectors) trackScrape() func() {
+	c.scrapesMu.Lock()
+	defer c.scrapesMu.Unlock()
+
+	if c.scrapes == nil {
+		c.scrapes = &sync.WaitGroup{}
+	}
+
+	scrapes := c.scrapes
+	scrapes.Add(1)
+
+	return scrapes.Done
+}
+
+// replaceScrapes returns the tracked scrapes of the current collectors. Later scrapes are tracked separately.
+// The caller must hold the lock of c.
+func (c *MetricCollectors) replaceScrapes() *sync.WaitGroup {
+	c.scrapesMu.Lock()
+	defer c.scrapesMu.Unlock()
+
+	scrapes := c.scrapes
+	if scrapes == nil {
+		scrapes = &sync.WaitGroup{}
+	}
+
+	c.scrapes = &sync.WaitGroup{}
+
+	return scrapes
+}
+
+// closeCollectors closes the collectors for which filter returns true.
+func (c *MetricCollectors) closeCollectors(logger *slog.Logger, filter func(name string) bool) []error {
+	errs := make([]error, 0)
+
+	for name, collector := range c.Collectors {
+		if !filter(name) {
+			continue
+		}
+
+		if err := collector.Close(logger); err != nil {
+			errs = append(errs, fmt.Errorf("error close collector %s: %w", name, err))
+		}
+	}
+
+	return errs
+}
+
+// configFingerprint returns a representation of the configuration of a collector,
+// derived from the values of its flags and its configuration. An empty string is returned, if the collector was
+// not created by NewWithFlags.
+func (c *MetricCollectors) configFingerprint(name string) string {
+	flags, ok := c.collectorFlags[name]
+	if !ok {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	for _, flag := range flags {
+		sb.WriteString(flag.Name)
+		sb.WriteString("=")
+		sb.WriteString(flag.Value.String())
+		sb.WriteString("\n")
+	}
+
+	if section, ok := c.config.section(name); ok {
+		out, err := yaml.Marshal(section)
+		if err != nil {
+			return ""
+		}
+
+		sb.Write(out)
+	}
+
+	return sb.String()
+}
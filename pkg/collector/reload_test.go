@@ -0,0 +1,102 @@
+//go:build windows
+
+package collector
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cpu, memory := newTestCollector(), newTestCollector()
+
+	c := New(Map{"cpu": cpu, "memory": memory})
+	require.NoError(t, c.SetPerfCounterQuery(logger))
+
+	// Collectors created without flags have no configuration fingerprint, so they are rebuilt on every reload.
+	newCPU, osCollector := newTestCollector(), newTestCollector()
+
+	require.NoError(t, c.Reload(logger, New(Map{"cpu": newCPU, "os": osCollector})))
+
+	require.Equal(t, Map{"cpu": newCPU, "os": osCollector}, c.Collectors)
+	require.Equal(t, int32(1), cpu.closed.Load())
+	require.Equal(t, int32(1), memory.closed.Load())
+	require.Zero(t, newCPU.closed.Load())
+	require.Zero(t, osCollector.closed.Load())
+}
+
+func TestReloadBuildError(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cpu := newTestCollector()
+
+	c := New(Map{"cpu": cpu})
+	require.NoError(t, c.SetPerfCounterQuery(logger))
+
+	newCPU := newTestCollector()
+	failing := &flakyBuildCollector{testCollector: newTestCollector(), failures: 1}
+
+	require.ErrorContains(t, c.Reload(logger, New(Map{"cpu": newCPU, "os": failing})), "error build collector os")
+
+	// The current collectors are kept and the built collectors are closed.
+	require.Equal(t, Map{"cpu": cpu}, c.Collectors)
+	require.Zero(t, cpu.closed.Load())
+	require.Equal(t, int32(1), newCPU.closed.Load())
+}
+
+func TestReloadDuringScrape(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cpu := newTestCollector()
+
+	c := New(Map{"cpu": cpu})
+	require.NoError(t, c.SetPerfCounterQuery(logger))
+
+	p := c.NewPrometheusCollector(time.Minute, logger)
+
+	inFlight, err := p.startScrape()
+	require.NoError(t, err)
+
+	newCPU := newTestCollector()
+	reloaded := make(chan error)
+
+	go func() {
+		reloaded <- c.Reload(logger, New(Map{"cpu": newCPU}))
+	}()
+
+	// A pending reload doesn't block new scrapes. They use the new collectors.
+	require.Eventually(t, func() bool {
+		s, err := p.startScrape()
+		if err != nil {
+			return false
+		}
+
+		defer s.done()
+
+		return s.collectors["cpu"] == newCPU
+	}, 5*time.Second, time.Millisecond)
+
+	// The replaced collector is closed after the in-flight scrape has finished.
+	require.Equal(t, cpu, inFlight.collectors["cpu"])
+	require.Zero(t, cpu.closed.Load())
+
+	select {
+	case <-reloaded:
+		t.Fatal("reload finished before the in-flight scrape")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	inFlight.done()
+
+	require.NoError(t, <-reloaded)
+	require.Equal(t, int32(1), cpu.closed.Load())
+}
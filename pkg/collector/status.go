@@ -0,0 +1,74 @@
+//go:build windows
+
+package collector
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/collectorstatus"
+)
+
+// Status returns the status of the enabled collectors, including the collectors which failed to build and are
+// retried in the background, sorted by name.
+func (c *MetricCollectors) Status() []collectorstatus.Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	statuses := make([]collectorstatus.Status, 0, len(c.Collectors)+len(c.unavailableCollectors))
+
+	add := func(name string, available bool) {
+		status := c.statusFor(name).Status(name)
+		status.Available = available
+
+		if b := c.breakerFor(name); b != nil {
+			status.CircuitBreaker = b.State().String()
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for name := range c.Collectors {
+		add(name, true)
+	}
+
+	for name := range c.unavailableCollectors {
+		add(name, false)
+	}
+
+	slices.SortFunc(statuses, func(a, b collectorstatus.Status) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return statuses
+}
+
+// statusFor returns the status tracker of a collector.
+func (c *MetricCollectors) statusFor(name string) *collectorstatus.Tracker {
+	c.statusMu.Lock()
+	defer c.statusMu.Unlock()
+
+	if c.status == nil {
+		c.status = make(map[string]*collectorstatus.Tracker)
+	}
+
+	t, ok := c.status[name]
+	if !ok {
+		t = collectorstatus.NewTracker()
+		c.status[name] = t
+	}
+
+	return t
+}
+
+// resetStatus removes the status trackers of the collectors for which filter returns true.
+func (c *MetricCollectors) resetStatus(filter func(name string) bool) {
+	c.statusMu.Lock()
+	defer c.statusMu.Unlock()
+
+	for name := range c.status {
+		if filter(name) {
+			delete(c.status, name)
+		}
+	}
+}
@@ -1,23 +1,94 @@
 package collector
 
 import (
+	"context"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus-community/windows_exporter/internal/breaker"
+	"github.com/prometheus-community/windows_exporter/internal/collectorstatus"
 	"github.com/prometheus-community/windows_exporter/internal/mi"
+	"github.com/prometheus-community/windows_exporter/internal/relabel"
 	"github.com/prometheus-community/windows_exporter/internal/types"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 type MetricCollectors struct {
+	// mu guards Collectors, PerfCounterQuery, CollectorOptions, MetricRelabelRules and
+	// unavailableCollectors against a concurrent Reload. A scrape holds it only while it takes the collectors.
+	mu sync.RWMutex
+	// reloadMu serializes calls of Reload.
+	reloadMu sync.Mutex
+
 	Collectors       Map
 	MISession        *mi.Session
 	PerfCounterQuery string
+
+	// CollectorOptions holds the generic options per collector name.
+	// Collectors without an entry are using the default options.
+	CollectorOptions map[string]*CollectorOptions
+
+	// MetricRelabelRules are applied to every metric sent by a collector. nil disables the relabeling.
+	MetricRelabelRules *relabel.Rules
+	// relabelCache holds the descriptors of the relabeled metrics.
+	relabelCache relabelCache
+
+	// CircuitBreaker configures the circuit breaker, which skips repeatedly failing or timing-out collectors.
+	CircuitBreaker breaker.Options
+
+	scrapesMu sync.Mutex
+	// scrapes tracks the in-flight scrapes of the current collectors. A Reload waits for them before it closes
+	// the replaced collectors.
+	scrapes *sync.WaitGroup
+
+	// breakersMu guards CircuitBreaker and breakers.
+	breakersMu sync.Mutex
+	// breakers holds the circuit breaker per collector name. Breakers are created on the first scrape of a collector.
+	breakers map[string]*breaker.Breaker
+
+	statusMu sync.Mutex
+	// status holds the status tracker per collector name. Trackers are created on the first run or build of a collector.
+	status map[string]*collectorstatus.Tracker
+
+	// unavailableCollectors holds the collectors, which failed to build by BuildDegraded or Reload and are retried in the background.
+	unavailableCollectors Map
+	buildRetryStopCh      chan struct{}
+	buildRetryStopOnce    sync.Once
+	buildRetryWg          sync.WaitGroup
+	// degraded is true, if the collectors were built by BuildDegraded. Reload retries failing collectors then, too.
+	degraded             bool
+	buildRetryBackoff    time.Duration
+	buildRetryMaxBackoff time.Duration
+
+	perfCounterQueryCacheMu sync.Mutex
+	// perfCounterQueryCache holds the perflib queries per combination of collectors.
+	perfCounterQueryCache map[string]string
+
+	// collectorFlags holds the flags per collector name, if the collectors are created by NewWithFlags.
+	collectorFlags map[string][]*kingpin.FlagModel
+	// config holds the configuration, which the collectors created by NewWithFlags start from.
+	config *Config
+}
+
+// CollectorOptions holds settings which are applied by MetricCollectors to a single collector,
+// independent of the collector specific configuration.
+type CollectorOptions struct {
+	// Interval enables the background collection of the collector. The collector runs on its own
+	// interval and scrapes are served from the last cached result. 0 collects on every scrape.
+	Interval time.Duration `yaml:"interval"`
+	// Timeout limits the duration of a collection of the collector. The timeout is capped by the
+	// timeout of the scrape request. 0 uses the timeout of the scrape request.
+	Timeout time.Duration `yaml:"timeout"`
 }
 
 type (
 	BuilderWithFlags[C Collector] func(*kingpin.Application) C
-	Map                           map[string]Collector
+	// BuilderWithConfigAndFlags creates a collector, which starts from its configuration in config.
+	// Flags set on the command line override the configuration.
+	BuilderWithConfigAndFlags func(app *kingpin.Application, config *Config) Collector
+	Map                       map[string]Collector
 )
 
 // Collector interface that a collector has to implement.
@@ -30,5 +101,6 @@ type Collector interface {
 	// GetPerfCounter returns the perf counter required by the collector
 	GetPerfCounter(logger *slog.Logger) ([]string, error)
 	// Collect Get new metrics and expose them via prometheus registry.
-	Collect(ctx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) (err error)
+	// ctx is cancelled, once the scrape times out. Running MI queries and perfdata collections should be stopped then.
+	Collect(ctx context.Context, scrapeCtx *types.ScrapeContext, logger *slog.Logger, ch chan<- prometheus.Metric) (err error)
 }
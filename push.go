@@ -0,0 +1,178 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/prometheus-community/windows_exporter/internal/config"
+	"github.com/prometheus-community/windows_exporter/internal/graphite"
+	"github.com/prometheus-community/windows_exporter/internal/influx"
+	"github.com/prometheus-community/windows_exporter/internal/otlp"
+	"github.com/prometheus-community/windows_exporter/internal/pushgateway"
+	"github.com/prometheus-community/windows_exporter/internal/remotewrite"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// pushConfig holds the configuration of the push modes, which send the metrics to external systems.
+// The push modes are configured by the configuration file only.
+type pushConfig struct {
+	remoteWrite remotewrite.Config
+	otlp        otlp.Config
+	pushgateway pushgateway.Config
+	influx      influx.Config
+	graphite    graphite.Config
+}
+
+func (c *pushConfig) load(resolver *config.Resolver) error {
+	if err := resolver.Unmarshal("remote_write", &c.remoteWrite); err != nil {
+		return err
+	}
+
+	if err := resolver.Unmarshal("otlp", &c.otlp); err != nil {
+		return err
+	}
+
+	if err := resolver.Unmarshal("pushgateway", &c.pushgateway); err != nil {
+		return err
+	}
+
+	if err := resolver.Unmarshal("influxdb", &c.influx); err != nil {
+		return err
+	}
+
+	if err := resolver.Unmarshal("graphite", &c.graphite); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// pushers holds the enabled push modes.
+type pushers struct {
+	remoteWrite *remotewrite.Pusher
+	otlp        *otlp.Exporter
+	pushgateway *pushgateway.Pusher
+	influx      *influx.Pusher
+	graphite    *graphite.Sink
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newPushers(logger *slog.Logger, cfg pushConfig) (*pushers, error) {
+	p := &pushers{}
+
+	var err error
+
+	if cfg.remoteWrite.Enabled() {
+		p.remoteWrite, err = remotewrite.New(logger.With(slog.String("component", "remote_write")), cfg.remoteWrite)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create remote write pusher: %w", err)
+		}
+	}
+
+	if cfg.otlp.Enabled() {
+		p.otlp, err = otlp.New(logger.With(slog.String("component", "otlp")), cfg.otlp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+	}
+
+	if cfg.pushgateway.Enabled() {
+		p.pushgateway, err = pushgateway.New(logger.With(slog.String("component", "pushgateway")), cfg.pushgateway)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create pushgateway pusher: %w", err)
+		}
+	}
+
+	if cfg.influx.Enabled() {
+		p.influx, err = influx.New(logger.With(slog.String("component", "influxdb")), cfg.influx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create influxdb pusher: %w", err)
+		}
+	}
+
+	if cfg.graphite.Enabled() {
+		p.graphite, err = graphite.New(logger.With(slog.String("component", "graphite")), cfg.graphite)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create graphite sink: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+// collectors returns the collectors for the metrics about the push modes.
+func (p *pushers) collectors() []prometheus.Collector {
+	collectors := make([]prometheus.Collector, 0)
+
+	if p.remoteWrite != nil {
+		collectors = append(collectors, p.remoteWrite)
+	}
+
+	if p.otlp != nil {
+		collectors = append(collectors, p.otlp)
+	}
+
+	if p.pushgateway != nil {
+		collectors = append(collectors, p.pushgateway)
+	}
+
+	if p.influx != nil {
+		collectors = append(collectors, p.influx)
+	}
+
+	if p.graphite != nil {
+		collectors = append(collectors, p.graphite)
+	}
+
+	return collectors
+}
+
+// run starts the enabled push modes. They stop, once ctx is done or stop is called.
+func (p *pushers) run(ctx context.Context, gatherer prometheus.Gatherer) {
+	ctx, p.cancel = context.WithCancel(ctx)
+
+	if p.remoteWrite != nil {
+		p.start(func() { p.remoteWrite.Run(ctx, gatherer) })
+	}
+
+	if p.otlp != nil {
+		p.start(func() { p.otlp.Run(ctx, gatherer) })
+	}
+
+	if p.pushgateway != nil {
+		p.start(func() { p.pushgateway.Run(ctx, gatherer) })
+	}
+
+	if p.influx != nil {
+		p.start(func() { p.influx.Run(ctx, gatherer) })
+	}
+
+	if p.graphite != nil {
+		p.start(func() { p.graphite.Run(ctx, gatherer) })
+	}
+}
+
+func (p *pushers) start(run func()) {
+	p.wg.Add(1)
+
+	go func() {
+		defer p.wg.Done()
+
+		run()
+	}()
+}
+
+// stop stops the push modes and waits for their final pushes.
+func (p *pushers) stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+
+	p.wg.Wait()
+}
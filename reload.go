@@ -0,0 +1,79 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/internal/config"
+	"github.com/prometheus-community/windows_exporter/internal/utils"
+	"github.com/prometheus-community/windows_exporter/pkg/collector"
+)
+
+// reloadCollectors re-reads the configuration file and applies the changed collector configurations.
+// Changes of other settings, like the listen address, the const labels, the metrics endpoints or the push modes,
+// require a restart of windows_exporter.
+// endpointCollectors are the collectors of the metrics endpoints, which stay enabled. remote loads the configuration file from a URL.
+// The collectors of --collectors.enabled are returned.
+func reloadCollectors(logger *slog.Logger, configFile string, remote *config.Remote, collectors *collector.MetricCollectors, endpointCollectors []string) ([]string, error) {
+	app, flags, newCollectors := newApp(nil)
+
+	var resolver *config.Resolver
+
+	if configFile != "" {
+		var err error
+
+		resolver, err = config.NewResolver(configFile, logger, remote)
+		if err != nil {
+			return nil, fmt.Errorf("could not load config file: %w", err)
+		}
+
+		if err = resolver.Validate(app, configSchema()); err != nil {
+			return nil, fmt.Errorf("invalid configuration file: %w", err)
+		}
+
+		collectorConfig, err := loadCollectorConfig(resolver)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load collector configuration: %w", err)
+		}
+
+		app, flags, newCollectors = newApp(collectorConfig)
+
+		if err = resolver.Bind(app, os.Args[1:]); err != nil {
+			return nil, fmt.Errorf("failed to bind configuration: %w", err)
+		}
+
+		if newCollectors.MetricRelabelRules, err = loadMetricRelabelRules(resolver); err != nil {
+			return nil, fmt.Errorf("failed to load metric relabel configs: %w", err)
+		}
+	}
+
+	if _, err := app.Parse(os.Args[1:]); err != nil {
+		return nil, fmt.Errorf("failed to parse CLI args from YAML file: %w", err)
+	}
+
+	if err := newCollectors.Enable(utils.ExpandEnabledCollectors(*flags.enabledCollectors + "," + strings.Join(endpointCollectors, ","))); err != nil {
+		return nil, err
+	}
+
+	if err := newCollectors.CircuitBreaker.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := collectors.Reload(logger, newCollectors); err != nil {
+		return nil, err
+	}
+
+	if resolver != nil {
+		if err := resolver.SaveCache(); err != nil {
+			logger.Warn("Failed to cache config file",
+				slog.Any("err", err),
+			)
+		}
+	}
+
+	return utils.ExpandEnabledCollectors(*flags.enabledCollectors), nil
+}